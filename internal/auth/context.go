@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrUnauthenticated = errors.New("authentication required")
+
+// Identity 는 인증된 요청의 사용자다
+type Identity struct {
+	UserID string
+	Email  string
+	Name   string
+}
+
+type contextKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Middleware 는 Authorization: Bearer 토큰을 검증하고 사용자를 요청 컨텍스트에 넣는다
+func Middleware(issuer *Issuer) func(http.Handler) http.Handler {
+	return authenticate(issuer, bearer)
+}
+
+// WebSocketMiddleware 는 Middleware 와 같지만 ?access_token 도 받는다.
+// 브라우저 웹소켓은 헤더를 붙일 수 없어서 쓰며, URL 이 로그에 남으므로 웹소켓 경로에만 쓴다.
+func WebSocketMiddleware(issuer *Issuer) func(http.Handler) http.Handler {
+	return authenticate(issuer, func(r *http.Request) (string, bool) {
+		if token, ok := bearer(r); ok {
+			return token, true
+		}
+		token := r.URL.Query().Get("access_token")
+		return token, token != ""
+	})
+}
+
+func authenticate(issuer *Issuer, extract func(r *http.Request) (string, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := extract(r)
+			if !ok {
+				unauthorized(w, ErrUnauthenticated)
+				return
+			}
+
+			identity, err := issuer.Verify(token)
+			if err != nil {
+				unauthorized(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+func bearer(r *http.Request) (string, bool) {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		token = strings.TrimSpace(token)
+		return token, token != ""
+	}
+	return "", false
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="diagram-server"`)
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	issuer := NewIssuer([]byte("secret"), time.Hour)
+	token, _, _ := issuer.Issue(Identity{UserID: "u1"})
+
+	var seen Identity
+	record := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	})
+	rest := Middleware(issuer)(record)
+	websocket := WebSocketMiddleware(issuer)(record)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		header  string
+		query   string
+		want    int
+	}{
+		{name: "토큰이 없으면 401", handler: rest, want: http.StatusUnauthorized},
+		{name: "잘못된 토큰이면 401", handler: rest, header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "빈 Bearer 토큰이면 401", handler: rest, header: "Bearer  ", want: http.StatusUnauthorized},
+		{name: "Bearer 헤더", handler: rest, header: "Bearer " + token, want: http.StatusOK},
+		{name: "일반 API 는 쿼리 파라미터 토큰을 받지 않는다", handler: rest, query: "?access_token=" + token, want: http.StatusUnauthorized},
+		{name: "웹소켓용 쿼리 파라미터", handler: websocket, query: "?access_token=" + token, want: http.StatusOK},
+		{name: "웹소켓도 Bearer 헤더를 받는다", handler: websocket, header: "Bearer " + token, want: http.StatusOK},
+		{name: "웹소켓에 토큰이 없으면 401", handler: websocket, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = Identity{}
+			req := httptest.NewRequest(http.MethodGet, "/api/diagrams/d1"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && seen.UserID != "u1" {
+				t.Errorf("identity = %+v, want u1", seen)
+			}
+		})
+	}
+}
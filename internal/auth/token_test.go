@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIssuer(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	issuer := NewIssuer([]byte("secret"), time.Hour)
+	issuer.now = func() time.Time { return now }
+
+	alice := Identity{UserID: "u1", Email: "alice@example.com", Name: "Alice"}
+	token, expiresAt, err := issuer.Issue(alice)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if !expiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expiresAt = %v", expiresAt)
+	}
+
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + parts[1] + "." + NewIssuer([]byte("other"), time.Hour).sign(parts[0]+"."+parts[1])
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+		want  error
+	}{
+		{name: "발급한 토큰은 검증된다", token: token, at: now},
+		{name: "만료되면 실패한다", token: token, at: now.Add(time.Hour), want: ErrTokenExpired},
+		{name: "다른 키로 서명하면 실패한다", token: forged, at: now, want: ErrInvalidToken},
+		{name: "alg 가 다르면 실패한다", token: "eyJhbGciOiJub25lIn0." + parts[1] + ".", at: now, want: ErrInvalidToken},
+		{name: "형식이 틀리면 실패한다", token: "not-a-token", at: now, want: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer.now = func() time.Time { return tt.at }
+
+			got, err := issuer.Verify(tt.token)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("Verify() error = %v, want %v", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != alice {
+				t.Errorf("Verify() = %+v, want %+v", got, alice)
+			}
+		})
+	}
+}
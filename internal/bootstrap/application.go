@@ -2,8 +2,12 @@ package bootstrap
 
 import (
 	"context"
+	"crypto/rand"
+	"diagram-server/internal/auth"
 	"diagram-server/internal/database"
+	"diagram-server/internal/domain"
 	"diagram-server/internal/handler"
+	"diagram-server/internal/live"
 	"diagram-server/internal/persistance"
 	"diagram-server/internal/service"
 	"errors"
@@ -18,10 +22,14 @@ import (
 )
 
 type Application struct {
-	port           string
-	server         *http.Server
-	db             database.Connector
-	diagramHandler *handler.DiagramHandler
+	port             string
+	server           *http.Server
+	db               database.Connector
+	diagramHandler   *handler.DiagramHandler
+	workspaceHandler *handler.WorkspaceHandler
+	authHandler      *handler.AuthHandler
+	liveHub          *live.Hub
+	tokens           *auth.Issuer
 }
 
 func NewApplication() *Application {
@@ -40,7 +48,9 @@ func (app *Application) Run(ctx context.Context) error {
 	}
 	defer app.shutdown(ctx)
 
-	app.initDependencies()
+	if err := app.initDependencies(ctx); err != nil {
+		return fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
 	app.initWebServer()
 
 	// 배너 및 시스템 정보 출력
@@ -66,9 +76,15 @@ func (app *Application) Run(ctx context.Context) error {
 }
 
 func (app *Application) initDatabase(ctx context.Context) error {
+	dbType := database.ConnectType(getEnv("DATABASE_TYPE", string(database.MongoDB)))
+	defaultUri := "mongodb://localhost:27017"
+	if dbType == database.SQLite {
+		defaultUri = "diagram.db"
+	}
+
 	cfg := database.Config{
-		Type:     database.MongoDB,
-		Uri:      getEnv("DATABASE", "mongodb://localhost:27017"),
+		Type:     dbType,
+		Uri:      getEnv("DATABASE", defaultUri),
 		Database: getEnv("COLLECTION", "diagram"),
 		Pool: database.PoolConfig{
 			MinSize:     getEnvUint64("DB_POOL_MIN", 5),
@@ -103,10 +119,54 @@ func (app *Application) initWebServer() {
 		_, _ = w.Write([]byte("OK"))
 	})
 
-	mux.HandleFunc("POST /api/diagrams", app.diagramHandler.Create)
-	mux.HandleFunc("GET /api/diagrams/by-type/{type}", app.diagramHandler.GetAllByType)
-	mux.HandleFunc("GET /api/diagrams/{id}", app.diagramHandler.GetByID)
-	mux.HandleFunc("DELETE /api/diagrams/{id}", app.diagramHandler.Delete)
+	mux.HandleFunc("POST /api/auth/register", app.authHandler.Register)
+	mux.HandleFunc("POST /api/auth/login", app.authHandler.Login)
+
+	// 다이어그램과 워크스페이스 API 는 모두 로그인한 사용자만 쓸 수 있다
+	authenticate := auth.Middleware(app.tokens)
+	protected := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, authenticate(h))
+	}
+
+	protected("GET /api/diagrams", app.diagramHandler.List)
+	protected("POST /api/diagrams", app.diagramHandler.Create)
+	protected("GET /api/diagrams/search", app.diagramHandler.Search)
+	protected("POST /api/diagrams/parse", app.diagramHandler.ParseDDL)
+	protected("POST /api/diagrams/import", app.diagramHandler.Import)
+	// by-type/{type} 은 /api/diagrams/{id}/revisions 같은 하위 경로와 겹치므로 타입마다 따로 등록한다
+	for _, dtype := range domain.DiagramTypes {
+		dtype := string(dtype)
+		protected("GET /api/diagrams/by-type/"+dtype, func(w http.ResponseWriter, r *http.Request) {
+			r.SetPathValue("type", dtype)
+			app.diagramHandler.GetAllByType(w, r)
+		})
+	}
+	protected("GET /api/diagrams/{id}", app.diagramHandler.GetByID)
+	protected("PATCH /api/diagrams/{id}", app.diagramHandler.Update)
+	protected("PUT /api/diagrams/{id}", app.diagramHandler.Replace)
+	protected("DELETE /api/diagrams/{id}", app.diagramHandler.Delete)
+	protected("POST /api/diagrams/{id}/migrations", app.diagramHandler.ApplyMigration)
+	protected("PUT /api/diagrams/{id}/layout", app.diagramHandler.UpdateLayout)
+	protected("GET /api/diagrams/{id}/revisions", app.diagramHandler.GetRevisions)
+	protected("GET /api/diagrams/{id}/revisions/{rev}", app.diagramHandler.GetRevision)
+	protected("POST /api/diagrams/{id}/revisions/{rev}/restore", app.diagramHandler.RestoreRevision)
+	// 브라우저 웹소켓은 헤더를 붙일 수 없으므로 이 경로만 ?access_token 을 받는다
+	mux.Handle("GET /api/diagrams/{id}/live", auth.WebSocketMiddleware(app.tokens)(http.HandlerFunc(app.diagramHandler.Live)))
+	protected("GET /api/diagrams/{id}/acl", app.diagramHandler.GetACL)
+	protected("POST /api/diagrams/{id}/acl", app.diagramHandler.Grant)
+	protected("DELETE /api/diagrams/{id}/acl/{user}", app.diagramHandler.Revoke)
+	protected("GET /api/diagrams/{a}/diff/{b}", app.diagramHandler.Diff)
+	protected("GET /api/diagrams/{a}/diff/{b}/migration", app.diagramHandler.Migration)
+	protected("GET /api/diagrams/{id}/export/sql", app.diagramHandler.ExportSQL)
+	protected("GET /api/diagrams/{id}/export/mermaid", app.diagramHandler.ExportMermaid)
+	protected("GET /api/diagrams/{id}/export/dbml", app.diagramHandler.ExportDBML)
+
+	protected("POST /api/workspaces", app.workspaceHandler.Create)
+	protected("GET /api/workspaces", app.workspaceHandler.GetMine)
+	protected("GET /api/workspaces/{id}", app.workspaceHandler.GetByID)
+	protected("POST /api/workspaces/{id}/members", app.workspaceHandler.AddMember)
+	protected("DELETE /api/workspaces/{id}/members/{user}", app.workspaceHandler.RemoveMember)
+	protected("GET /api/workspaces/{id}/diagrams", app.workspaceHandler.GetDiagrams)
 
 	app.server = &http.Server{
 		Addr:    fmt.Sprintf(":%s", app.port),
@@ -114,18 +174,93 @@ func (app *Application) initWebServer() {
 	}
 }
 
-func (app *Application) initDependencies() {
-	mongoConn := app.db.(*database.MongoConnector)
-	db := mongoConn.DB()
+// repositories 는 연결한 DB 종류에 맞는 저장소 묶음이다
+type repositories struct {
+	diagrams   persistance.DiagramRepository
+	revisions  persistance.RevisionRepository
+	users      persistance.UserRepository
+	workspaces persistance.WorkspaceRepository
+}
+
+func (app *Application) initRepositories(ctx context.Context) (*repositories, error) {
+	switch conn := app.db.(type) {
+	case *database.MongoConnector:
+		db := conn.DB()
+		if err := persistance.EnsureIndexes(ctx, db); err != nil {
+			return nil, err
+		}
+		return &repositories{
+			diagrams:   persistance.NewDiagramRepository(db),
+			revisions:  persistance.NewRevisionRepository(db),
+			users:      persistance.NewUserRepository(db),
+			workspaces: persistance.NewWorkspaceRepository(db),
+		}, nil
+	case *database.SQLiteConnector:
+		db := conn.DB()
+		if err := persistance.MigrateSQLite(ctx, db); err != nil {
+			return nil, err
+		}
+		return &repositories{
+			diagrams:   persistance.NewSQLiteDiagramRepository(db),
+			revisions:  persistance.NewSQLiteRevisionRepository(db),
+			users:      persistance.NewSQLiteUserRepository(db),
+			workspaces: persistance.NewSQLiteWorkspaceRepository(db),
+		}, nil
+	case *database.MemoryConnector:
+		log.Println("[INFO] Using in-memory repositories; data will be lost on restart")
+		return &repositories{
+			diagrams:   persistance.NewMemoryDiagramRepository(),
+			revisions:  persistance.NewMemoryRevisionRepository(),
+			users:      persistance.NewMemoryUserRepository(),
+			workspaces: persistance.NewMemoryWorkspaceRepository(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("no repositories for connector %T", app.db)
+	}
+}
+
+func (app *Application) initDependencies(ctx context.Context) error {
+	repos, err := app.initRepositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	diagramSvc := service.NewDiagramService(repos.diagrams, repos.revisions, repos.users, repos.workspaces)
+	app.liveHub = live.NewHub(diagramSvc)
+	app.diagramHandler = handler.NewDiagramHandler(diagramSvc, app.liveHub)
+	app.workspaceHandler = handler.NewWorkspaceHandler(service.NewWorkspaceService(repos.workspaces, repos.diagrams, repos.users))
+
+	tokens, err := newTokenIssuer()
+	if err != nil {
+		return err
+	}
+	app.tokens = tokens
 
-	diagramRepo := persistance.NewDiagramRepository(db)
-	diagramSvc := service.NewDiagramService(diagramRepo)
-	app.diagramHandler = handler.NewDiagramHandler(diagramSvc)
+	app.authHandler = handler.NewAuthHandler(service.NewUserService(repos.users), app.tokens)
 
 	log.Println("[INFO] Dependencies initialized")
+	return nil
+}
+
+// JWT_SECRET 이 없으면 임의의 키를 만든다. 이 경우 재시작하면 발급한 토큰이 모두 무효가 된다
+func newTokenIssuer() (*auth.Issuer, error) {
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	if len(secret) == 0 {
+		log.Println("[INFO] JWT_SECRET is not set, using a random key; tokens will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return auth.NewIssuer(secret, getEnvDuration("JWT_TTL", 24*time.Hour)), nil
 }
 
 func (app *Application) shutdown(ctx context.Context) {
+	// 웹소켓은 Server.Shutdown 이 기다리지 않으므로 편집 중인 내용을 먼저 저장한다
+	if app.liveHub != nil {
+		app.liveHub.Close()
+	}
+
 	if app.db != nil {
 		if err := app.db.Disconnect(ctx); err != nil {
 			log.Fatalf("[Error] Database disconnect error: %v", err)
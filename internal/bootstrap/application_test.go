@@ -0,0 +1,45 @@
+package bootstrap
+
+import (
+	"diagram-server/internal/handler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 라우트 패턴이 서로 충돌하면 ServeMux 가 등록 시점에 panic 한다
+func TestInitWebServer(t *testing.T) {
+	app := &Application{
+		port:             "8080",
+		diagramHandler:   &handler.DiagramHandler{},
+		workspaceHandler: &handler.WorkspaceHandler{},
+		authHandler:      &handler.AuthHandler{},
+	}
+
+	app.initWebServer()
+
+	if app.server == nil || app.server.Handler == nil {
+		t.Fatal("server 가 초기화되지 않음")
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/diagrams/by-type/erdiagram", want: "GET /api/diagrams/by-type/erdiagram"},
+		{path: "/api/diagrams/d1/revisions", want: "GET /api/diagrams/{id}/revisions"},
+		{path: "/api/diagrams/d1/live", want: "GET /api/diagrams/{id}/live"},
+		{path: "/api/diagrams/d1/acl", want: "GET /api/diagrams/{id}/acl"},
+		{path: "/api/diagrams/d1/unknown", want: ""},
+	}
+
+	mux := app.server.Handler.(*http.ServeMux)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, pattern := mux.Handler(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if pattern != tt.want {
+				t.Errorf("pattern = %q, want %q", pattern, tt.want)
+			}
+		})
+	}
+}
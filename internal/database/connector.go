@@ -15,12 +15,20 @@ type ConnectType string
 
 const (
 	MongoDB ConnectType = "mongodb"
+	// Memory 는 DB 없이 메모리에 저장한다. 재시작하면 내용이 사라지므로 개발과 테스트에만 쓴다
+	Memory ConnectType = "memory"
+	// SQLite 는 파일 하나에 저장한다. MongoDB 를 띄우기 어려운 작은 설치에 쓴다
+	SQLite ConnectType = "sqlite"
 )
 
 func NewConnector(cfg Config) (Connector, error) {
 	switch cfg.Type {
 	case MongoDB:
 		return NewMongoConnector(cfg), nil
+	case Memory:
+		return NewMemoryConnector(), nil
+	case SQLite:
+		return NewSQLiteConnector(cfg), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
 	}
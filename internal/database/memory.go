@@ -0,0 +1,15 @@
+package database
+
+import "context"
+
+// MemoryConnector 는 연결할 DB 가 없다. 저장소는 프로세스 메모리에 만들어진다
+type MemoryConnector struct{}
+
+func NewMemoryConnector() *MemoryConnector {
+	return &MemoryConnector{}
+}
+
+func (mc *MemoryConnector) Connect(ctx context.Context) error    { return nil }
+func (mc *MemoryConnector) Disconnect(ctx context.Context) error { return nil }
+func (mc *MemoryConnector) Ping(ctx context.Context) error       { return nil }
+func (mc *MemoryConnector) Client() any                          { return nil }
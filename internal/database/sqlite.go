@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqlitePragmas 는 모든 연결에 적용한다.
+// WAL 은 읽기와 쓰기를 동시에 허용하고, _txlock=immediate 는 트랜잭션 시작 시 쓰기 잠금을 잡아
+// 읽은 뒤 쓰려다 SQLITE_BUSY 로 실패하는 일을 막는다.
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+// SQLiteConnector 의 Uri 는 데이터베이스 파일 경로다. :memory: 는 연결마다 다른 DB 가 되므로 쓰지 않는다
+type SQLiteConnector struct {
+	cfg Config
+	db  *sql.DB
+}
+
+func NewSQLiteConnector(cfg Config) *SQLiteConnector {
+	return &SQLiteConnector{cfg: cfg}
+}
+
+func (sc *SQLiteConnector) Connect(ctx context.Context) error {
+	dsn := sc.cfg.Uri
+	if strings.Contains(dsn, "?") {
+		dsn += "&" + sqlitePragmas
+	} else {
+		dsn += "?" + sqlitePragmas
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return err
+	}
+	if sc.cfg.Pool.MaxSize > 0 {
+		db.SetMaxOpenConns(int(sc.cfg.Pool.MaxSize))
+	}
+	db.SetConnMaxIdleTime(sc.cfg.Pool.MaxIdleTime)
+	db.SetConnMaxLifetime(sc.cfg.Pool.MaxLifetime)
+
+	sc.db = db
+	return nil
+}
+
+func (sc *SQLiteConnector) Disconnect(ctx context.Context) error {
+	if sc.db != nil {
+		return sc.db.Close()
+	}
+	return nil
+}
+
+func (sc *SQLiteConnector) Ping(ctx context.Context) error {
+	return sc.db.PingContext(ctx)
+}
+
+func (sc *SQLiteConnector) Client() any {
+	return sc.db
+}
+
+func (sc *SQLiteConnector) DB() *sql.DB {
+	return sc.db
+}
@@ -0,0 +1,316 @@
+package diff
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	TableAdded   ChangeKind = "table_added"
+	TableRemoved ChangeKind = "table_removed"
+	TableRenamed ChangeKind = "table_renamed"
+
+	ColumnAdded           ChangeKind = "column_added"
+	ColumnRemoved         ChangeKind = "column_removed"
+	ColumnTypeChanged     ChangeKind = "column_type_changed"
+	ColumnNullableChanged ChangeKind = "column_nullable_changed"
+	ColumnPKChanged       ChangeKind = "column_pk_changed"
+
+	RelationAdded       ChangeKind = "relation_added"
+	RelationRemoved     ChangeKind = "relation_removed"
+	RelationTypeChanged ChangeKind = "relation_type_changed"
+)
+
+// renameThreshold 는 삭제된 테이블과 추가된 테이블을 이름 변경으로 볼 최소 컬럼 유사도다
+const renameThreshold = 0.75
+
+// Change 는 변경 하나다. Kind 에 따라 채워지는 필드가 다르다.
+//   - Table*: Table 은 변경 후 이름(삭제면 변경 전 이름), OldTable/NewTable 은 테이블 전체
+//   - Column*: Table 은 변경 후 테이블 이름, OldColumn/NewColumn 은 컬럼 전체
+//   - Relation*: Table 은 관계를 가진 테이블, OldRelation/NewRelation 은 관계
+type Change struct {
+	Kind        ChangeKind
+	Table       string
+	OldTable    *domain.Table
+	NewTable    *domain.Table
+	Column      string
+	OldColumn   *domain.Column
+	NewColumn   *domain.Column
+	OldRelation *domain.Relation
+	NewRelation *domain.Relation
+}
+
+type Changeset struct {
+	Changes []Change
+}
+
+func (cs *Changeset) Empty() bool {
+	return len(cs.Changes) == 0
+}
+
+type tablePair struct {
+	old, new *domain.Table
+}
+
+// Compare 는 from 에서 to 로 가는 구조 변경을 구한다. 테이블과 컬럼 이름은 대소문자를 구분하지 않는다.
+// 이름이 사라지고 새로 생긴 테이블의 컬럼이 충분히 비슷하면 이름 변경으로 본다.
+func Compare(from, to []domain.Table) *Changeset {
+	cs := &Changeset{}
+
+	var pairs []tablePair
+	var removed, added []*domain.Table
+	for i := range from {
+		if t := findTable(to, from[i].Name); t != nil {
+			pairs = append(pairs, tablePair{&from[i], t})
+		} else {
+			removed = append(removed, &from[i])
+		}
+	}
+	for i := range to {
+		if findTable(from, to[i].Name) == nil {
+			added = append(added, &to[i])
+		}
+	}
+
+	renamed := make(map[*domain.Table]*domain.Table)
+	for _, old := range removed {
+		best, score := -1, renameThreshold
+		for i, t := range added {
+			if t == nil {
+				continue
+			}
+			if s := similarity(old, t); s >= score && (best < 0 || s > score) {
+				best, score = i, s
+			}
+		}
+		if best >= 0 {
+			renamed[old] = added[best]
+			pairs = append(pairs, tablePair{old, added[best]})
+			added[best] = nil
+		}
+	}
+
+	for _, old := range removed {
+		if t, ok := renamed[old]; ok {
+			cs.Changes = append(cs.Changes, Change{Kind: TableRenamed, Table: t.Name, OldTable: old, NewTable: t})
+		} else {
+			cs.Changes = append(cs.Changes, Change{Kind: TableRemoved, Table: old.Name, OldTable: old})
+		}
+	}
+	for _, t := range added {
+		if t != nil {
+			cs.Changes = append(cs.Changes, Change{Kind: TableAdded, Table: t.Name, NewTable: t})
+		}
+	}
+
+	for _, p := range pairs {
+		cs.Changes = append(cs.Changes, compareColumns(p.old, p.new)...)
+	}
+
+	cs.Changes = append(cs.Changes, compareRelations(from, to, renamed)...)
+	return cs
+}
+
+func findTable(tables []domain.Table, name string) *domain.Table {
+	for i := range tables {
+		if strings.EqualFold(tables[i].Name, name) {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+func findColumn(columns *[]domain.Column, name string) *domain.Column {
+	if columns == nil {
+		return nil
+	}
+	for i := range *columns {
+		if strings.EqualFold((*columns)[i].Name, name) {
+			return &(*columns)[i]
+		}
+	}
+	return nil
+}
+
+// similarity 는 "이름 타입" 집합의 자카드 유사도다. 컬럼이 없는 테이블끼리는 비교하지 않는다
+func similarity(a, b *domain.Table) float64 {
+	left, right := signatures(a), signatures(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	common := 0
+	for sig := range left {
+		if right[sig] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(left)+len(right)-common)
+}
+
+func signatures(t *domain.Table) map[string]bool {
+	result := make(map[string]bool)
+	if t.Columns != nil {
+		for _, c := range *t.Columns {
+			result[strings.ToLower(c.Name+" "+c.Type)] = true
+		}
+	}
+	return result
+}
+
+func compareColumns(old, new *domain.Table) []Change {
+	var changes []Change
+
+	if old.Columns != nil {
+		for i := range *old.Columns {
+			c := &(*old.Columns)[i]
+			if findColumn(new.Columns, c.Name) == nil {
+				changes = append(changes, Change{Kind: ColumnRemoved, Table: new.Name, Column: c.Name, OldColumn: c})
+			}
+		}
+	}
+
+	if new.Columns == nil {
+		return changes
+	}
+	for i := range *new.Columns {
+		c := &(*new.Columns)[i]
+		prev := findColumn(old.Columns, c.Name)
+		if prev == nil {
+			changes = append(changes, Change{Kind: ColumnAdded, Table: new.Name, Column: c.Name, NewColumn: c})
+			continue
+		}
+
+		change := Change{Table: new.Name, Column: c.Name, OldColumn: prev, NewColumn: c}
+		if !strings.EqualFold(prev.Type, c.Type) {
+			change.Kind = ColumnTypeChanged
+			changes = append(changes, change)
+		}
+		if prev.Nullable != c.Nullable {
+			change.Kind = ColumnNullableChanged
+			changes = append(changes, change)
+		}
+		if prev.PK != c.PK {
+			change.Kind = ColumnPKChanged
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+type ownedRelation struct {
+	table    string
+	relation *domain.Relation
+}
+
+// compareRelations 는 From/To 쌍으로 관계를 맞춘다. 이름이 바뀐 테이블의 끝점은 새 이름으로 바꿔 비교한다
+func compareRelations(from, to []domain.Table, renamed map[*domain.Table]*domain.Table) []Change {
+	oldRelations := relations(from)
+	newRelations := relations(to)
+
+	rename := func(endpoint string) string {
+		if t, column := domain.ResolveEndpoint(from, endpoint); t != nil {
+			if n, ok := renamed[t]; ok {
+				endpoint = n.Name
+				if column != "" {
+					endpoint += "." + column
+				}
+			}
+		}
+		return strings.ToLower(endpoint)
+	}
+	key := func(r *domain.Relation, renameEndpoints bool) string {
+		if renameEndpoints {
+			return rename(r.From) + "\x00" + rename(r.To)
+		}
+		return strings.ToLower(r.From) + "\x00" + strings.ToLower(r.To)
+	}
+
+	newByKey := make(map[string]ownedRelation)
+	for _, r := range newRelations {
+		newByKey[key(r.relation, false)] = r
+	}
+	oldByKey := make(map[string]ownedRelation)
+	for _, r := range oldRelations {
+		oldByKey[key(r.relation, true)] = r
+	}
+
+	var changes []Change
+	for _, r := range oldRelations {
+		k := key(r.relation, true)
+		match, ok := newByKey[k]
+		switch {
+		case !ok:
+			table := r.table
+			if t := findTable(from, r.table); t != nil {
+				if n, ok := renamed[t]; ok {
+					table = n.Name
+				}
+			}
+			changes = append(changes, Change{Kind: RelationRemoved, Table: table, OldRelation: r.relation})
+		case match.relation.Type != r.relation.Type:
+			changes = append(changes, Change{Kind: RelationTypeChanged, Table: match.table, OldRelation: r.relation, NewRelation: match.relation})
+		}
+	}
+	for _, r := range newRelations {
+		if _, ok := oldByKey[key(r.relation, false)]; !ok {
+			changes = append(changes, Change{Kind: RelationAdded, Table: r.table, NewRelation: r.relation})
+		}
+	}
+	return changes
+}
+
+func relations(tables []domain.Table) []ownedRelation {
+	var result []ownedRelation
+	for _, t := range tables {
+		if t.Relations == nil {
+			continue
+		}
+		for i := range *t.Relations {
+			result = append(result, ownedRelation{table: t.Name, relation: &(*t.Relations)[i]})
+		}
+	}
+	return result
+}
+
+// Text 는 리뷰용 텍스트로 변경을 한 줄씩 적는다. + 는 추가, - 는 삭제, ~ 는 변경이다
+func (cs *Changeset) Text() string {
+	var sb strings.Builder
+	for _, c := range cs.Changes {
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (c Change) String() string {
+	column := c.Table + "." + c.Column
+	switch c.Kind {
+	case TableAdded:
+		return "+ table " + c.Table
+	case TableRemoved:
+		return "- table " + c.Table
+	case TableRenamed:
+		return fmt.Sprintf("~ table %s renamed to %s", c.OldTable.Name, c.Table)
+	case ColumnAdded:
+		return fmt.Sprintf("+ column %s %s", column, c.NewColumn.Type)
+	case ColumnRemoved:
+		return fmt.Sprintf("- column %s %s", column, c.OldColumn.Type)
+	case ColumnTypeChanged:
+		return fmt.Sprintf("~ column %s type %s -> %s", column, c.OldColumn.Type, c.NewColumn.Type)
+	case ColumnNullableChanged:
+		return fmt.Sprintf("~ column %s nullable %t -> %t", column, c.OldColumn.Nullable, c.NewColumn.Nullable)
+	case ColumnPKChanged:
+		return fmt.Sprintf("~ column %s pk %t -> %t", column, c.OldColumn.PK, c.NewColumn.PK)
+	case RelationAdded:
+		return fmt.Sprintf("+ relation %s -> %s (%s)", c.NewRelation.From, c.NewRelation.To, c.NewRelation.Type)
+	case RelationRemoved:
+		return fmt.Sprintf("- relation %s -> %s (%s)", c.OldRelation.From, c.OldRelation.To, c.OldRelation.Type)
+	case RelationTypeChanged:
+		return fmt.Sprintf("~ relation %s -> %s type %s -> %s", c.NewRelation.From, c.NewRelation.To, c.OldRelation.Type, c.NewRelation.Type)
+	}
+	return string(c.Kind)
+}
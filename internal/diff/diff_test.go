@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"diagram-server/internal/domain"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func table(name string, columns []domain.Column, relations ...domain.Relation) domain.Table {
+	return domain.Table{Name: name, Columns: &columns, Relations: &relations}
+}
+
+func usersTable(name string) domain.Table {
+	return table(name, []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "email", Type: "varchar(255)"},
+		{Name: "nickname", Type: "varchar(50)", Nullable: true},
+		{Name: "created_at", Type: "timestamp"},
+	})
+}
+
+func TestCompare(t *testing.T) {
+	orders := table("orders", []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "user_id", Type: "bigint"},
+	}, domain.Relation{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne})
+
+	tests := []struct {
+		name string
+		from []domain.Table
+		to   []domain.Table
+		want []string
+	}{
+		{
+			name: "변경이 없으면 빈 결과",
+			from: []domain.Table{usersTable("users"), orders},
+			to:   []domain.Table{usersTable("USERS"), orders},
+			want: nil,
+		},
+		{
+			name: "테이블 추가와 삭제",
+			from: []domain.Table{usersTable("users"), table("legacy", []domain.Column{{Name: "x", Type: "int"}})},
+			to:   []domain.Table{usersTable("users"), orders},
+			want: []string{
+				"- table legacy",
+				"+ table orders",
+				"+ relation orders.user_id -> users.id (many_to_one)",
+			},
+		},
+		{
+			name: "컬럼이 비슷하면 이름 변경으로 보고 관계 끝점도 따라간다",
+			from: []domain.Table{usersTable("users"), orders},
+			to: []domain.Table{
+				usersTable("accounts"),
+				table("orders", *orders.Columns, domain.Relation{From: "orders.user_id", To: "accounts.id", Type: domain.ManyToOne}),
+			},
+			want: []string{"~ table users renamed to accounts"},
+		},
+		{
+			name: "컬럼 추가, 삭제, 타입, NULL 허용, PK 변경",
+			from: []domain.Table{usersTable("users")},
+			to: []domain.Table{table("users", []domain.Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "email", Type: "varchar(100)", Nullable: true},
+				{Name: "nickname", Type: "varchar(50)", Nullable: true},
+				{Name: "age", Type: "int"},
+			})},
+			want: []string{
+				"- column users.created_at timestamp",
+				"~ column users.id pk true -> false",
+				"~ column users.email type varchar(255) -> varchar(100)",
+				"~ column users.email nullable false -> true",
+				"+ column users.age int",
+			},
+		},
+		{
+			name: "관계 타입 변경",
+			from: []domain.Table{usersTable("users"), orders},
+			to: []domain.Table{
+				usersTable("users"),
+				table("orders", *orders.Columns, domain.Relation{From: "orders.user_id", To: "users.id", Type: domain.OneToOne}),
+			},
+			want: []string{"~ relation orders.user_id -> users.id type many_to_one -> one_to_one"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := Compare(tt.from, tt.to)
+
+			var got []string
+			for _, c := range cs.Changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if cs.Empty() != (len(tt.want) == 0) {
+				t.Errorf("Empty() = %v", cs.Empty())
+			}
+		})
+	}
+}
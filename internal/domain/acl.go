@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidGrant = errors.New("invalid grant")
+
+// Role 은 다이어그램에 대한 권한이다. 위 역할은 아래 역할의 권한을 모두 가진다.
+//   - viewer: 읽기, 내보내기, 리비전/차이 보기
+//   - commenter: viewer + 의견 남기기
+//   - editor: 내용과 배치 수정, 리비전 복원
+//   - owner: 삭제와 공유 설정
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleCommenter Role = "commenter"
+	RoleEditor    Role = "editor"
+	RoleOwner     Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer:    1,
+	RoleCommenter: 2,
+	RoleEditor:    3,
+	RoleOwner:     4,
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("%w: unknown role %q", ErrInvalidGrant, s)
+	}
+	return role, nil
+}
+
+// Allows 는 r 이 required 이상의 권한인지 본다. 빈 역할은 아무것도 허용하지 않는다
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// Higher 는 두 역할 중 권한이 큰 쪽이다
+func (r Role) Higher(other Role) Role {
+	if roleRanks[other] > roleRanks[r] {
+		return other
+	}
+	return r
+}
+
+// Grant 는 소유자 외의 사용자에게 준 역할이다
+type Grant struct {
+	UserID string
+	Role   Role
+}
+
+func (b BaseDiagram) ACL() []Grant { return b.acl }
+
+// SetACL 은 저장소에서 읽은 권한 목록을 복원할 때 쓴다
+func (b *BaseDiagram) SetACL(acl []Grant) {
+	b.acl = acl
+}
+
+// RoleOf 는 사용자의 역할이다. 만든 사람은 항상 owner 이고, 권한이 없으면 빈 문자열이다
+func (b BaseDiagram) RoleOf(userID string) Role {
+	if userID == "" {
+		return ""
+	}
+	if userID == b.owner {
+		return RoleOwner
+	}
+	for _, g := range b.acl {
+		if g.UserID == userID {
+			return g.Role
+		}
+	}
+	return ""
+}
+
+// GrantAccess 는 사용자에게 역할을 주거나 바꾼다. 만든 사람의 역할은 바꿀 수 없다.
+// 권한은 내용이 아니므로 modifiedAt 은 갱신하지 않는다.
+func (b *BaseDiagram) GrantAccess(userID string, role Role) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user is required", ErrInvalidGrant)
+	}
+	if _, ok := roleRanks[role]; !ok {
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidGrant, role)
+	}
+	if userID == b.owner {
+		return fmt.Errorf("%w: %s already owns the diagram", ErrInvalidGrant, userID)
+	}
+
+	for i := range b.acl {
+		if b.acl[i].UserID == userID {
+			b.acl[i].Role = role
+			return nil
+		}
+	}
+	b.acl = append(b.acl, Grant{UserID: userID, Role: role})
+	return nil
+}
+
+// RevokeAccess 는 사용자의 역할을 없앤다. 없는 사용자면 아무것도 하지 않는다
+func (b *BaseDiagram) RevokeAccess(userID string) error {
+	if userID == b.owner {
+		return fmt.Errorf("%w: cannot revoke the owner", ErrInvalidGrant)
+	}
+
+	for i := range b.acl {
+		if b.acl[i].UserID == userID {
+			b.acl = append(b.acl[:i:i], b.acl[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRole_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     Role
+		required Role
+		want     bool
+	}{
+		{name: "owner 는 모든 권한을 가진다", role: RoleOwner, required: RoleEditor, want: true},
+		{name: "editor 는 viewer 권한을 가진다", role: RoleEditor, required: RoleViewer, want: true},
+		{name: "같은 역할은 허용한다", role: RoleCommenter, required: RoleCommenter, want: true},
+		{name: "viewer 는 편집할 수 없다", role: RoleViewer, required: RoleEditor, want: false},
+		{name: "editor 는 소유자 권한이 없다", role: RoleEditor, required: RoleOwner, want: false},
+		{name: "빈 역할은 아무것도 허용하지 않는다", role: "", required: RoleViewer, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.role.Allows(tt.required); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseDiagram_GrantAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		role    Role
+		wantErr error
+		want    Role
+	}{
+		{name: "새 사용자에게 역할을 준다", userID: "bob", role: RoleViewer, want: RoleViewer},
+		{name: "이미 있는 사용자의 역할을 바꾼다", userID: "carol", role: RoleEditor, want: RoleEditor},
+		{name: "만든 사람의 역할은 바꿀 수 없다", userID: "alice", role: RoleViewer, wantErr: ErrInvalidGrant, want: RoleOwner},
+		{name: "알 수 없는 역할은 거부한다", userID: "bob", role: "admin", wantErr: ErrInvalidGrant},
+		{name: "사용자가 없으면 거부한다", userID: "", role: RoleViewer, wantErr: ErrInvalidGrant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := BaseDiagram{owner: "alice", acl: []Grant{{UserID: "carol", Role: RoleCommenter}}}
+
+			err := d.GrantAccess(tt.userID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GrantAccess() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := d.RoleOf(tt.userID); got != tt.want {
+				t.Errorf("RoleOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseDiagram_RevokeAccess(t *testing.T) {
+	d := BaseDiagram{owner: "alice", acl: []Grant{{UserID: "bob", Role: RoleViewer}, {UserID: "carol", Role: RoleEditor}}}
+
+	if err := d.RevokeAccess("bob"); err != nil {
+		t.Fatalf("RevokeAccess() error = %v", err)
+	}
+	if got := d.RoleOf("bob"); got != "" {
+		t.Errorf("RoleOf(bob) = %q, want empty", got)
+	}
+	if got := d.RoleOf("carol"); got != RoleEditor {
+		t.Errorf("RoleOf(carol) = %q, want %q", got, RoleEditor)
+	}
+	if err := d.RevokeAccess("alice"); !errors.Is(err, ErrInvalidGrant) {
+		t.Errorf("RevokeAccess(owner) error = %v, want %v", err, ErrInvalidGrant)
+	}
+}
@@ -1,14 +1,29 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Diagram interface {
 	Type() DiagramType
 	ID() string
 	CreatedAt() time.Time
 	Owner() string
+	Workspace() string
+	Version() int64
+	SetVersion(version int64)
+
+	ACL() []Grant
+	RoleOf(userID string) Role
+	GrantAccess(userID string, role Role) error
+	RevokeAccess(userID string) error
 }
 
+var ErrUnknownTable = errors.New("unknown table")
+
 type DiagramType string
 
 const (
@@ -16,14 +31,20 @@ const (
 	TypeFlowChart DiagramType = "flowchart"
 )
 
+// DiagramTypes 는 지원하는 모든 다이어그램 타입이다
+var DiagramTypes = []DiagramType{TypeERD, TypeFlowChart}
+
 type BaseDiagram struct {
 	id          string
 	title       string
 	description *string
 	diagramType DiagramType
 	owner       string
+	workspace   string
 	createdAt   time.Time
 	modifiedAt  time.Time
+	version     int64
+	acl         []Grant
 }
 
 func (b BaseDiagram) ID() string            { return b.id }
@@ -31,8 +52,10 @@ func (b BaseDiagram) Title() string         { return b.title }
 func (b BaseDiagram) Description() *string  { return b.description }
 func (b BaseDiagram) Type() DiagramType     { return b.diagramType }
 func (b BaseDiagram) Owner() string         { return b.owner }
+func (b BaseDiagram) Workspace() string     { return b.workspace }
 func (b BaseDiagram) CreatedAt() time.Time  { return b.createdAt }
 func (b BaseDiagram) ModifiedAt() time.Time { return b.modifiedAt }
+func (b BaseDiagram) Version() int64        { return b.version }
 
 func NewBaseDiagram(title string, description *string, dtype DiagramType, owner string) BaseDiagram {
 	now := time.Now()
@@ -43,6 +66,7 @@ func NewBaseDiagram(title string, description *string, dtype DiagramType, owner
 		owner:       owner,
 		createdAt:   now,
 		modifiedAt:  now,
+		version:     1,
 	}
 }
 
@@ -62,6 +86,16 @@ func (b *BaseDiagram) SetID(id string) {
 	b.id = id
 }
 
+// SetWorkspace 는 다이어그램을 워크스페이스에 넣는다. 빈 문자열이면 개인 다이어그램이다
+func (b *BaseDiagram) SetWorkspace(workspace string) {
+	b.workspace = workspace
+}
+
+// SetVersion 은 저장소가 저장에 성공한 뒤 새 버전을 반영할 때 쓴다
+func (b *BaseDiagram) SetVersion(version int64) {
+	b.version = version
+}
+
 func (b *BaseDiagram) UpdateTitle(title string) {
 	b.title = title
 	b.modifiedAt = time.Now()
@@ -72,17 +106,26 @@ func (b *BaseDiagram) UpdateDescription(description *string) {
 	b.modifiedAt = time.Now()
 }
 
+// clearEmpty 는 빈 문자열을 설명 없음(nil)으로 바꾼다
+func clearEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
 func (e *ERDiagram) UpdateTables(tables []Table) {
 	e.Tables = tables
 	e.modifiedAt = time.Now()
 }
 
+// Update 는 nil 이 아닌 값만 바꾼다. 빈 설명은 설명을 지운다
 func (e *ERDiagram) Update(title *string, description *string, tables []Table) {
 	if title != nil {
 		e.title = *title
 	}
 	if description != nil {
-		e.description = description
+		e.description = clearEmpty(description)
 	}
 	if tables != nil {
 		e.Tables = tables
@@ -90,6 +133,39 @@ func (e *ERDiagram) Update(title *string, description *string, tables []Table) {
 	e.modifiedAt = time.Now()
 }
 
+// Replace 는 제목, 설명, 테이블을 모두 주어진 값으로 바꾼다. 소유자와 생성 시각은 유지한다.
+// 빈 설명은 Update 와 같이 설명 없음으로 저장한다.
+func (e *ERDiagram) Replace(title string, description *string, tables []Table) {
+	e.title = title
+	e.description = clearEmpty(description)
+	e.Tables = tables
+	e.modifiedAt = time.Now()
+}
+
+// UpdateLayout 은 이름이 일치하는 테이블의 배치만 바꾼다. nil 은 자동 배치로 되돌린다.
+// 스키마가 바뀌는 것이 아니므로 modifiedAt 은 갱신하지 않는다.
+func (e *ERDiagram) UpdateLayout(layouts map[string]*TableLayout) error {
+	indexes := make(map[string]int, len(layouts))
+	for name := range layouts {
+		idx := -1
+		for i := range e.Tables {
+			if e.Tables[i].Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownTable, name)
+		}
+		indexes[name] = idx
+	}
+
+	for name, layout := range layouts {
+		e.Tables[indexes[name]].Layout = layout
+	}
+	return nil
+}
+
 type ERDiagram struct {
 	BaseDiagram
 	Tables []Table
@@ -107,6 +183,17 @@ type Table struct {
 	OriginalQuery *string
 	Columns       *[]Column
 	Relations     *[]Relation
+	Layout        *TableLayout
+}
+
+// TableLayout 은 사용자가 직접 정한 테이블의 위치와 모양이다. nil 이면 자동 배치한다
+type TableLayout struct {
+	X         float64
+	Y         float64
+	Width     *float64
+	Collapsed bool
+	Color     *string
+	ZIndex    int
 }
 
 type Column struct {
@@ -123,6 +210,28 @@ type Relation struct {
 	Type RelationType
 }
 
+// ResolveEndpoint 는 "table.column" 형태의 관계 끝점이 가리키는 테이블과 컬럼을 찾는다.
+// 테이블 이름에 스키마가 포함될 수 있으므로 가장 길게 일치하는 테이블을 고른다.
+func ResolveEndpoint(tables []Table, endpoint string) (*Table, string) {
+	var found *Table
+	column := ""
+
+	for i := range tables {
+		name := tables[i].Name
+		if found != nil && len(name) <= len(found.Name) {
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(endpoint, name):
+			found, column = &tables[i], ""
+		case len(endpoint) > len(name) && endpoint[len(name)] == '.' && strings.EqualFold(endpoint[:len(name)], name):
+			found, column = &tables[i], endpoint[len(name)+1:]
+		}
+	}
+	return found, column
+}
+
 type RelationType string
 
 const (
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -240,6 +242,9 @@ func TestNewBaseDiagram(t *testing.T) {
 			if got.ID() != "" {
 				t.Errorf("ID() should be empty for new diagram, got %v", got.ID())
 			}
+			if got.Version() != 1 {
+				t.Errorf("Version() = %v, want 1 for new diagram", got.Version())
+			}
 			if got.CreatedAt().Before(before) || got.CreatedAt().After(after) {
 				t.Errorf("CreatedAt() = %v, should be between %v and %v", got.CreatedAt(), before, after)
 			}
@@ -324,3 +329,162 @@ func TestNewERDiagram(t *testing.T) {
 func TestERDiagram_ImplementsDiagram(t *testing.T) {
 	var _ Diagram = (*ERDiagram)(nil)
 }
+
+func TestResolveEndpoint(t *testing.T) {
+	tables := []Table{{Name: "users"}, {Name: "app.users"}, {Name: "orders"}}
+
+	tests := []struct {
+		name       string
+		endpoint   string
+		wantTable  string
+		wantColumn string
+	}{
+		{
+			name:       "table.column 을 나눈다",
+			endpoint:   "orders.user_id",
+			wantTable:  "orders",
+			wantColumn: "user_id",
+		},
+		{
+			name:       "스키마가 붙은 테이블을 우선한다",
+			endpoint:   "app.users.id",
+			wantTable:  "app.users",
+			wantColumn: "id",
+		},
+		{
+			name:       "컬럼이 없으면 테이블만 반환한다",
+			endpoint:   "USERS",
+			wantTable:  "users",
+			wantColumn: "",
+		},
+		{
+			name:     "일치하는 테이블이 없으면 nil",
+			endpoint: "missing.id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, column := ResolveEndpoint(tables, tt.endpoint)
+			if tt.wantTable == "" {
+				if table != nil {
+					t.Errorf("ResolveEndpoint() table = %v, want nil", table.Name)
+				}
+				return
+			}
+			if table == nil || table.Name != tt.wantTable {
+				t.Fatalf("ResolveEndpoint() table = %v, want %v", table, tt.wantTable)
+			}
+			if column != tt.wantColumn {
+				t.Errorf("ResolveEndpoint() column = %v, want %v", column, tt.wantColumn)
+			}
+		})
+	}
+}
+
+func TestERDiagram_UpdateLayout(t *testing.T) {
+	color := "#ff0000"
+	modifiedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		current *TableLayout
+		layouts map[string]*TableLayout
+		want    *TableLayout
+		wantErr error
+	}{
+		{
+			name:    "테이블 배치를 바꾼다",
+			layouts: map[string]*TableLayout{"users": {X: 10, Y: 20, Color: &color, ZIndex: 2}},
+			want:    &TableLayout{X: 10, Y: 20, Color: &color, ZIndex: 2},
+		},
+		{
+			name:    "nil 이면 자동 배치로 되돌린다",
+			current: &TableLayout{X: 10, Y: 20},
+			layouts: map[string]*TableLayout{"users": nil},
+			want:    nil,
+		},
+		{
+			name:    "없는 테이블이면 아무것도 바꾸지 않는다",
+			current: &TableLayout{X: 10, Y: 20},
+			layouts: map[string]*TableLayout{"users": {X: 1}, "orders": {X: 2}},
+			want:    &TableLayout{X: 10, Y: 20},
+			wantErr: ErrUnknownTable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erd := &ERDiagram{
+				BaseDiagram: RestoreBaseDiagram("id", "title", nil, TypeERD, "owner", modifiedAt, modifiedAt),
+				Tables:      []Table{{Name: "users", Layout: tt.current}},
+			}
+
+			err := erd.UpdateLayout(tt.layouts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateLayout() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(erd.Tables[0].Layout, tt.want) {
+				t.Errorf("Layout = %+v, want %+v", erd.Tables[0].Layout, tt.want)
+			}
+			if !erd.ModifiedAt().Equal(modifiedAt) {
+				t.Errorf("ModifiedAt() = %v, want %v", erd.ModifiedAt(), modifiedAt)
+			}
+		})
+	}
+}
+
+func TestERDiagram_Replace(t *testing.T) {
+	desc := "설명"
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	erd := &ERDiagram{
+		BaseDiagram: RestoreBaseDiagram("id", "old", &desc, TypeERD, "owner", createdAt, createdAt),
+		Tables:      []Table{{Name: "users"}},
+	}
+
+	erd.Replace("new", nil, nil)
+
+	if erd.Title() != "new" {
+		t.Errorf("Title() = %v, want new", erd.Title())
+	}
+	if erd.Description() != nil {
+		t.Errorf("Description() = %v, want nil", *erd.Description())
+	}
+	if erd.Tables != nil {
+		t.Errorf("Tables = %v, want nil", erd.Tables)
+	}
+	if erd.Owner() != "owner" || !erd.CreatedAt().Equal(createdAt) {
+		t.Errorf("소유자와 생성 시각은 유지되어야 함: %v %v", erd.Owner(), erd.CreatedAt())
+	}
+	if !erd.ModifiedAt().After(createdAt) {
+		t.Errorf("ModifiedAt() = %v, want after %v", erd.ModifiedAt(), createdAt)
+	}
+}
+
+func TestERDiagram_Update(t *testing.T) {
+	desc := "설명"
+	empty := ""
+	other := "다른 설명"
+
+	tests := []struct {
+		name        string
+		description *string
+		want        *string
+	}{
+		{name: "nil 이면 설명을 유지한다", description: nil, want: &desc},
+		{name: "빈 문자열이면 설명을 지운다", description: &empty, want: nil},
+		{name: "값이 있으면 바꾼다", description: &other, want: &other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erd := NewERDiagram("title", &desc, "owner", nil)
+			erd.Update(nil, tt.description, nil)
+
+			got := erd.Description()
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Description() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewFlowChart(t *testing.T) {
+	nodes := []FlowNode{{ID: "start", Type: NodeStart, Label: "시작"}}
+
+	got := NewFlowChart("Checkout", nil, "owner-123", nodes, nil)
+
+	if got.Type() != TypeFlowChart {
+		t.Errorf("Type() = %v, want %v", got.Type(), TypeFlowChart)
+	}
+	if got.Title() != "Checkout" {
+		t.Errorf("Title() = %v, want Checkout", got.Title())
+	}
+	if len(got.Nodes) != 1 {
+		t.Errorf("Nodes length = %v, want 1", len(got.Nodes))
+	}
+}
+
+func TestFlowChart_ImplementsDiagram(t *testing.T) {
+	var _ Diagram = (*FlowChart)(nil)
+}
+
+func TestFlowChart_Validate(t *testing.T) {
+	yes := "yes"
+	nodes := []FlowNode{
+		{ID: "start", Type: NodeStart},
+		{ID: "paid", Type: NodeDecision, Label: "결제 완료?"},
+		{ID: "end", Type: NodeEnd},
+	}
+
+	tests := []struct {
+		name    string
+		nodes   []FlowNode
+		edges   []FlowEdge
+		wantErr bool
+	}{
+		{
+			name:  "올바른 플로우차트",
+			nodes: nodes,
+			edges: []FlowEdge{{From: "start", To: "paid"}, {From: "paid", To: "end", Label: &yes}},
+		},
+		{
+			name:    "노드 ID 가 중복되면 에러",
+			nodes:   append(append([]FlowNode(nil), nodes...), FlowNode{ID: "end", Type: NodeEnd}),
+			wantErr: true,
+		},
+		{
+			name:    "알 수 없는 노드 타입이면 에러",
+			nodes:   []FlowNode{{ID: "a", Type: "circle"}},
+			wantErr: true,
+		},
+		{
+			name:    "없는 노드를 잇는 간선이면 에러",
+			nodes:   nodes,
+			edges:   []FlowEdge{{From: "start", To: "missing"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewFlowChart("flow", nil, "owner", tt.nodes, tt.edges).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidFlowChart) {
+				t.Errorf("Validate() error = %v, want ErrInvalidFlowChart", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Revision 은 다이어그램을 저장할 때마다 남기는 변경 불가능한 스냅샷이다. Number 는 다이어그램마다 1 부터 증가한다
+type Revision struct {
+	DiagramID string
+	Number    int
+	Author    string
+	Summary   string
+	CreatedAt time.Time
+	Diagram   Diagram
+}
+
+func NewRevision(diagram Diagram, author, summary string) *Revision {
+	return &Revision{
+		DiagramID: diagram.ID(),
+		Author:    author,
+		Summary:   summary,
+		CreatedAt: time.Now(),
+		Diagram:   diagram,
+	}
+}
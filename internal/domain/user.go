@@ -1,17 +1,88 @@
 package domain
 
+import (
+	"errors"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidUser = errors.New("invalid user")
+
+const (
+	MinPasswordLength = 8
+	// bcrypt 는 72 바이트 뒤를 무시하므로 더 긴 비밀번호는 받지 않는다
+	MaxPasswordLength = 72
+	MaxNameLength     = 100
+)
+
+// ValidationError 는 필드별 검증 실패 사유를 모은다. Err 는 무엇을 검증했는지 알려 주는 센티널이다
+type ValidationError struct {
+	Err    error
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = name + ": " + e.Fields[name]
+	}
+	return e.Err.Error() + ": " + strings.Join(msgs, ", ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
 type User struct {
-	id       string
-	email    string
-	password string
-	name     string
+	id        string
+	email     string
+	password  string
+	name      string
+	createdAt time.Time
 }
 
-func NewUser(email, password, name string) User {
-	if len(password) < 8 {
+// NewUser 는 입력을 검증하고 이메일을 소문자로 맞춘다. password 는 평문이며, 저장 전에 SetPassword 로 해시를 넣어야 한다
+func NewUser(email, password, name string) (*User, error) {
+	email = NormalizeEmail(email)
+	name = strings.TrimSpace(name)
+
+	fields := make(map[string]string)
+	// "이름 <주소>" 형태는 받지 않는다
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		fields["email"] = "must be a valid email address"
+	}
+	if len(password) < MinPasswordLength {
+		fields["password"] = "must be at least 8 characters"
+	} else if len(password) > MaxPasswordLength {
+		fields["password"] = "must be at most 72 bytes"
+	}
+	if name == "" {
+		fields["name"] = "is required"
+	} else if len([]rune(name)) > MaxNameLength {
+		fields["name"] = "must be at most 100 characters"
 	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Err: ErrInvalidUser, Fields: fields}
+	}
+
+	return &User{email: email, password: password, name: name, createdAt: time.Now()}, nil
+}
 
-	return User{email: email, password: password, name: name}
+func RestoreUser(id, email, passwordHash, name string, createdAt time.Time) *User {
+	return &User{id: id, email: email, password: passwordHash, name: name, createdAt: createdAt}
+}
+
+// NormalizeEmail 은 이메일 비교와 유일성 검사에 쓰는 형태로 바꾼다
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 func (u *User) ID() string {
@@ -22,6 +93,7 @@ func (u *User) Email() string {
 	return u.email
 }
 
+// Password 는 저장된 비밀번호 해시다
 func (u *User) Password() string {
 	return u.password
 }
@@ -30,10 +102,18 @@ func (u *User) Name() string {
 	return u.name
 }
 
+func (u *User) CreatedAt() time.Time {
+	return u.createdAt
+}
+
 func (u *User) SetID(id string) {
 	u.id = id
 }
 
-func (u *User) SetEmail(encrypt string) {
-	u.password = encrypt
+func (u *User) SetEmail(email string) {
+	u.email = NormalizeEmail(email)
+}
+
+func (u *User) SetPassword(hash string) {
+	u.password = hash
 }
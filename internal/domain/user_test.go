@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		userName string
+		invalid  []string
+	}{
+		{name: "정상 입력", email: " Alice@Example.com ", password: "correct-horse", userName: "Alice"},
+		{name: "이메일 형식이 아니면 실패한다", email: "alice", password: "correct-horse", userName: "Alice", invalid: []string{"email"}},
+		{name: "이름이 붙은 주소는 받지 않는다", email: "Alice <alice@example.com>", password: "correct-horse", userName: "Alice", invalid: []string{"email"}},
+		{name: "비밀번호가 8자보다 짧으면 실패한다", email: "alice@example.com", password: "short", userName: "Alice", invalid: []string{"password"}},
+		{name: "비밀번호가 72바이트를 넘으면 실패한다", email: "alice@example.com", password: strings.Repeat("a", 73), userName: "Alice", invalid: []string{"password"}},
+		{name: "여러 필드가 틀리면 모두 알려준다", email: "", password: "", userName: "  ", invalid: []string{"email", "password", "name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewUser(tt.email, tt.password, tt.userName)
+
+			if len(tt.invalid) == 0 {
+				if err != nil {
+					t.Fatalf("NewUser() error = %v", err)
+				}
+				if got.Email() != "alice@example.com" {
+					t.Errorf("Email() = %q, want normalized address", got.Email())
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidUser) {
+				t.Fatalf("NewUser() error = %v, want ValidationError", err)
+			}
+			if len(verr.Fields) != len(tt.invalid) {
+				t.Errorf("Fields = %v, want %v", verr.Fields, tt.invalid)
+			}
+			for _, field := range tt.invalid {
+				if _, ok := verr.Fields[field]; !ok {
+					t.Errorf("Fields = %v, missing %s", verr.Fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestUser_SetEmail(t *testing.T) {
+	u := RestoreUser("u1", "old@example.com", "hash", "Alice", time.Time{})
+
+	u.SetEmail("New@Example.com")
+
+	if u.Email() != "new@example.com" {
+		t.Errorf("Email() = %q, want new@example.com", u.Email())
+	}
+	if u.Password() != "hash" {
+		t.Errorf("SetEmail must not touch the password, got %q", u.Password())
+	}
+}
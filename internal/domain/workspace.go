@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidWorkspace = errors.New("invalid workspace")
+
+const MaxWorkspaceNameLength = 100
+
+// Member 는 워크스페이스 구성원이다. 역할은 워크스페이스의 모든 다이어그램에 그대로 적용된다
+type Member struct {
+	UserID string
+	Role   Role
+}
+
+// Workspace 는 팀이 함께 소유하는 다이어그램 묶음이다
+type Workspace struct {
+	id        string
+	name      string
+	members   []Member
+	createdAt time.Time
+	version   int64
+}
+
+// NewWorkspace 는 만든 사람을 owner 로 하는 워크스페이스를 만든다
+func NewWorkspace(name, creator string) (*Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxWorkspaceNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidWorkspace, MaxWorkspaceNameLength)
+	}
+	if creator == "" {
+		return nil, fmt.Errorf("%w: creator is required", ErrInvalidWorkspace)
+	}
+
+	return &Workspace{
+		name:      name,
+		members:   []Member{{UserID: creator, Role: RoleOwner}},
+		createdAt: time.Now(),
+		version:   1,
+	}, nil
+}
+
+func RestoreWorkspace(id, name string, members []Member, createdAt time.Time, version int64) *Workspace {
+	return &Workspace{id: id, name: name, members: members, createdAt: createdAt, version: version}
+}
+
+func (w *Workspace) ID() string           { return w.id }
+func (w *Workspace) Name() string         { return w.name }
+func (w *Workspace) Members() []Member    { return w.members }
+func (w *Workspace) CreatedAt() time.Time { return w.createdAt }
+func (w *Workspace) Version() int64       { return w.version }
+
+func (w *Workspace) SetID(id string) {
+	w.id = id
+}
+
+// SetVersion 은 저장소가 저장에 성공한 뒤 새 버전을 반영할 때 쓴다
+func (w *Workspace) SetVersion(version int64) {
+	w.version = version
+}
+
+// RoleOf 는 구성원의 역할이다. 구성원이 아니면 빈 문자열이다
+func (w *Workspace) RoleOf(userID string) Role {
+	if userID == "" {
+		return ""
+	}
+	for _, m := range w.members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// AddMember 는 구성원을 추가하거나 역할을 바꾼다. 마지막 owner 의 역할은 낮출 수 없다
+func (w *Workspace) AddMember(userID string, role Role) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user is required", ErrInvalidWorkspace)
+	}
+	if _, ok := roleRanks[role]; !ok {
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidWorkspace, role)
+	}
+
+	for i := range w.members {
+		if w.members[i].UserID != userID {
+			continue
+		}
+		if role != RoleOwner && w.isLastOwner(userID) {
+			return fmt.Errorf("%w: workspace needs at least one owner", ErrInvalidWorkspace)
+		}
+		w.members[i].Role = role
+		return nil
+	}
+	w.members = append(w.members, Member{UserID: userID, Role: role})
+	return nil
+}
+
+// RemoveMember 는 구성원을 뺀다. 마지막 owner 는 뺄 수 없다
+func (w *Workspace) RemoveMember(userID string) error {
+	if w.isLastOwner(userID) {
+		return fmt.Errorf("%w: workspace needs at least one owner", ErrInvalidWorkspace)
+	}
+
+	for i := range w.members {
+		if w.members[i].UserID == userID {
+			w.members = append(w.members[:i:i], w.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (w *Workspace) isLastOwner(userID string) bool {
+	owners := 0
+	isOwner := false
+	for _, m := range w.members {
+		if m.Role == RoleOwner {
+			owners++
+			isOwner = isOwner || m.UserID == userID
+		}
+	}
+	return isOwner && owners == 1
+}
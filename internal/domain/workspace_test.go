@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewWorkspace(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		creator string
+		wantErr error
+	}{
+		{name: "만든 사람이 owner 인 워크스페이스를 만든다", input: "  Platform  ", creator: "alice"},
+		{name: "이름이 비어 있으면 거부한다", input: "   ", creator: "alice", wantErr: ErrInvalidWorkspace},
+		{name: "이름이 너무 길면 거부한다", input: strings.Repeat("a", MaxWorkspaceNameLength+1), creator: "alice", wantErr: ErrInvalidWorkspace},
+		{name: "만든 사람이 없으면 거부한다", input: "Platform", creator: "", wantErr: ErrInvalidWorkspace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, err := NewWorkspace(tt.input, tt.creator)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewWorkspace() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ws.Name() != "Platform" {
+				t.Errorf("Name() = %q, want %q", ws.Name(), "Platform")
+			}
+			if got := ws.RoleOf(tt.creator); got != RoleOwner {
+				t.Errorf("RoleOf(creator) = %q, want %q", got, RoleOwner)
+			}
+		})
+	}
+}
+
+func TestWorkspace_Members(t *testing.T) {
+	tests := []struct {
+		name    string
+		apply   func(ws *Workspace) error
+		wantErr error
+		user    string
+		want    Role
+	}{
+		{
+			name:  "구성원을 초대한다",
+			apply: func(ws *Workspace) error { return ws.AddMember("bob", RoleEditor) },
+			user:  "bob",
+			want:  RoleEditor,
+		},
+		{
+			name: "이미 있는 구성원의 역할을 바꾼다",
+			apply: func(ws *Workspace) error {
+				_ = ws.AddMember("bob", RoleEditor)
+				return ws.AddMember("bob", RoleViewer)
+			},
+			user: "bob",
+			want: RoleViewer,
+		},
+		{
+			name:    "마지막 owner 의 역할은 낮출 수 없다",
+			apply:   func(ws *Workspace) error { return ws.AddMember("alice", RoleEditor) },
+			wantErr: ErrInvalidWorkspace,
+			user:    "alice",
+			want:    RoleOwner,
+		},
+		{
+			name:    "마지막 owner 는 뺄 수 없다",
+			apply:   func(ws *Workspace) error { return ws.RemoveMember("alice") },
+			wantErr: ErrInvalidWorkspace,
+			user:    "alice",
+			want:    RoleOwner,
+		},
+		{
+			name: "다른 owner 가 있으면 owner 를 뺄 수 있다",
+			apply: func(ws *Workspace) error {
+				_ = ws.AddMember("bob", RoleOwner)
+				return ws.RemoveMember("alice")
+			},
+			user: "alice",
+			want: "",
+		},
+		{
+			name:    "알 수 없는 역할은 거부한다",
+			apply:   func(ws *Workspace) error { return ws.AddMember("bob", "admin") },
+			wantErr: ErrInvalidWorkspace,
+			user:    "bob",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, _ := NewWorkspace("Platform", "alice")
+
+			if err := tt.apply(ws); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := ws.RoleOf(tt.user); got != tt.want {
+				t.Errorf("RoleOf(%s) = %q, want %q", tt.user, got, tt.want)
+			}
+		})
+	}
+}
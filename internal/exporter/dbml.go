@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	dbmlIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	dbmlType  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\([0-9, ]*\))?(\[\])*$`)
+)
+
+var dbmlOperators = map[domain.RelationType]string{
+	domain.OneToOne:   "-",
+	domain.OneToMany:  "<",
+	domain.ManyToOne:  ">",
+	domain.ManyToMany: "<>",
+}
+
+// DBML 은 ERDiagram 을 dbdiagram.io 의 DBML 로 만든다
+func DBML(erd *domain.ERDiagram) string {
+	var sb strings.Builder
+
+	for i, t := range erd.Tables {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "Table %s {\n", dbmlTableName(t.Name))
+
+		pk := primaryKey(&t)
+		if t.Columns != nil {
+			for _, c := range *t.Columns {
+				sb.WriteString("  " + dbmlColumn(c, len(pk) == 1) + "\n")
+			}
+		}
+		if len(pk) > 1 {
+			names := make([]string, len(pk))
+			for i, c := range pk {
+				names[i] = dbmlName(c)
+			}
+			fmt.Fprintf(&sb, "\n  indexes {\n    (%s) [pk]\n  }\n", strings.Join(names, ", "))
+		}
+		sb.WriteString("}\n")
+	}
+
+	var refs []string
+	for _, t := range erd.Tables {
+		if t.Relations == nil {
+			continue
+		}
+		for _, r := range *t.Relations {
+			if ref, ok := dbmlRef(erd.Tables, r); ok {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	if len(refs) > 0 {
+		sb.WriteString("\n")
+		for _, ref := range refs {
+			sb.WriteString(ref + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func dbmlName(name string) string {
+	if dbmlIdent.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
+
+// schema.table 은 각 부분을 따로 인용한다
+func dbmlTableName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = dbmlName(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+func dbmlColumn(c domain.Column, inlinePK bool) string {
+	dtype := c.Type
+	if dtype == "" {
+		dtype = "varchar"
+	}
+	if !dbmlType.MatchString(dtype) {
+		dtype = `"` + strings.ReplaceAll(dtype, `"`, `\"`) + `"`
+	}
+
+	var settings []string
+	if c.PK && inlinePK {
+		settings = append(settings, "pk")
+	}
+	if !c.Nullable && !c.PK {
+		settings = append(settings, "not null")
+	}
+	if c.Description != nil {
+		note := strings.ReplaceAll(*c.Description, `\`, `\\`)
+		settings = append(settings, "note: '"+strings.ReplaceAll(note, "'", `\'`)+"'")
+	}
+
+	col := dbmlName(c.Name) + " " + dtype
+	if len(settings) > 0 {
+		col += " [" + strings.Join(settings, ", ") + "]"
+	}
+	return col
+}
+
+func dbmlRef(tables []domain.Table, r domain.Relation) (string, bool) {
+	op, ok := dbmlOperators[r.Type]
+	if !ok {
+		return "", false
+	}
+
+	from, ok := dbmlEndpoint(tables, r.From)
+	if !ok {
+		return "", false
+	}
+	to, ok := dbmlEndpoint(tables, r.To)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("Ref: %s %s %s", from, op, to), true
+}
+
+// DBML 의 Ref 는 컬럼 단위이므로 컬럼이 없는 끝점은 단일 PK 로 대신한다
+func dbmlEndpoint(tables []domain.Table, endpoint string) (string, bool) {
+	t, column := domain.ResolveEndpoint(tables, endpoint)
+	if t == nil {
+		return "", false
+	}
+	if column == "" {
+		pk := primaryKey(t)
+		if len(pk) != 1 {
+			return "", false
+		}
+		column = pk[0]
+	}
+	return dbmlTableName(t.Name) + "." + dbmlName(column), true
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"diagram-server/internal/parser"
+	"strings"
+	"testing"
+)
+
+func TestDBML(t *testing.T) {
+	got := DBML(newTestDiagram())
+
+	want := []string{
+		"Table orders {\n  id bigint [pk]\n  user_id bigint [not null]\n}\n",
+		"  email varchar(255) [not null, note: 'user\\'s email']\n",
+		"Ref: orders.user_id > users.id\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("DBML() = %s\nmissing %q", got, w)
+		}
+	}
+}
+
+func TestDBML_RoundTrip(t *testing.T) {
+	columns := []domain.Column{
+		{Name: "order_id", Type: "bigint", PK: true},
+		{Name: "line no", Type: "timestamp with time zone", PK: true},
+	}
+	erd := newTestDiagram()
+	erd.Tables = append(erd.Tables, domain.Table{Name: "app.items", Columns: &columns})
+
+	tables, err := parser.Parse(parser.DBML, DBML(erd))
+	if err != nil {
+		t.Fatalf("Parse() error = %v\n%s", err, DBML(erd))
+	}
+	if len(tables) != 3 {
+		t.Fatalf("tables length = %v, want 3", len(tables))
+	}
+
+	items := tables[2]
+	if items.Name != "app.items" {
+		t.Errorf("tables[2].Name = %v, want app.items", items.Name)
+	}
+	for i, c := range *items.Columns {
+		if c != columns[i] {
+			t.Errorf("columns[%d] = %+v, want %+v", i, c, columns[i])
+		}
+	}
+
+	email := (*tables[1].Columns)[1]
+	if email.Description == nil || *email.Description != "user's email" {
+		t.Errorf("email Description = %v, want user's email", email.Description)
+	}
+
+	want := domain.Relation{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}
+	if tables[0].Relations == nil || (*tables[0].Relations)[0] != want {
+		t.Errorf("Relations = %v, want %v", tables[0].Relations, want)
+	}
+}
@@ -0,0 +1,130 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var mermaidIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Mermaid 는 관계 양 끝의 카디널리티를 crow's foot 기호로 표현한다.
+// 왼쪽 기호는 From 쪽, 오른쪽 기호는 To 쪽 개수를 나타낸다.
+var mermaidCardinality = map[domain.RelationType][2]string{
+	domain.OneToOne:   {"||", "||"},
+	domain.OneToMany:  {"||", "o{"},
+	domain.ManyToOne:  {"}o", "||"},
+	domain.ManyToMany: {"}o", "o{"},
+}
+
+// Mermaid 는 ERDiagram 을 Mermaid erDiagram 문법으로 만든다
+func Mermaid(erd *domain.ERDiagram) string {
+	var sb strings.Builder
+	sb.WriteString("erDiagram\n")
+
+	fkColumns := make(map[string][]string)
+	for _, fk := range foreignKeys(erd.Tables) {
+		fkColumns[fk.table.Name] = append(fkColumns[fk.table.Name], fk.columns...)
+	}
+
+	for _, t := range erd.Tables {
+		fmt.Fprintf(&sb, "    %s {\n", mermaidName(t.Name))
+		if t.Columns != nil {
+			for _, c := range *t.Columns {
+				sb.WriteString("        ")
+				sb.WriteString(mermaidAttribute(c, contains(fkColumns[t.Name], c.Name)))
+				sb.WriteString("\n")
+			}
+		}
+		sb.WriteString("    }\n")
+	}
+
+	for _, t := range erd.Tables {
+		if t.Relations == nil {
+			continue
+		}
+		for _, r := range *t.Relations {
+			if line, ok := mermaidRelation(erd.Tables, r); ok {
+				sb.WriteString("    ")
+				sb.WriteString(line)
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+func mermaidName(name string) string {
+	if mermaidIdent.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `'`) + `"`
+}
+
+func mermaidAttribute(c domain.Column, fk bool) string {
+	// 타입에는 공백을 쓸 수 없다
+	dtype := strings.ReplaceAll(c.Type, ", ", ",")
+	dtype = strings.Join(strings.Fields(dtype), "_")
+	if dtype == "" {
+		dtype = "string"
+	}
+
+	attr := dtype + " " + c.Name
+
+	var keys []string
+	if c.PK {
+		keys = append(keys, "PK")
+	}
+	if fk {
+		keys = append(keys, "FK")
+	}
+	if len(keys) > 0 {
+		attr += " " + strings.Join(keys, ", ")
+	}
+	if c.Description != nil {
+		attr += ` "` + strings.ReplaceAll(*c.Description, `"`, `'`) + `"`
+	}
+	return attr
+}
+
+func mermaidRelation(tables []domain.Table, r domain.Relation) (string, bool) {
+	card, ok := mermaidCardinality[r.Type]
+	if !ok {
+		return "", false
+	}
+
+	fromTable, fromColumn := domain.ResolveEndpoint(tables, r.From)
+	toTable, toColumn := domain.ResolveEndpoint(tables, r.To)
+	if fromTable == nil || toTable == nil {
+		return "", false
+	}
+
+	// 외래키 컬럼이 NULL 을 허용하면 참조 대상은 0 또는 1 개다
+	if r.Type == domain.ManyToOne && nullableColumn(fromTable, fromColumn) {
+		card[1] = "o|"
+	}
+
+	label := fromColumn
+	if pk := primaryKey(toTable); toColumn != "" && !(len(pk) == 1 && strings.EqualFold(pk[0], toColumn)) {
+		label = fromColumn + " -> " + toColumn
+	}
+	if label == "" {
+		label = string(r.Type)
+	}
+
+	return fmt.Sprintf(`%s %s--%s %s : "%s"`,
+		mermaidName(fromTable.Name), card[0], card[1], mermaidName(toTable.Name), label), true
+}
+
+func nullableColumn(t *domain.Table, name string) bool {
+	if t.Columns == nil {
+		return false
+	}
+	for _, c := range *t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c.Nullable
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"diagram-server/internal/parser"
+	"strings"
+	"testing"
+)
+
+func TestMermaid(t *testing.T) {
+	got := Mermaid(newTestDiagram())
+
+	want := []string{
+		"erDiagram\n",
+		"    users {\n        bigint id PK\n        varchar(255) email \"user's email\"\n",
+		"        bigint user_id FK\n",
+		`    orders }o--|| users : "user_id"`,
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("Mermaid() = %s\nmissing %q", got, w)
+		}
+	}
+}
+
+func TestMermaid_RoundTrip(t *testing.T) {
+	erd := newTestDiagram()
+
+	tables, err := parser.Parse(parser.Mermaid, Mermaid(erd))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != len(erd.Tables) {
+		t.Fatalf("tables length = %v, want %v", len(tables), len(erd.Tables))
+	}
+
+	orders := tables[0]
+	if orders.Name != "orders" || len(*orders.Columns) != 2 {
+		t.Errorf("tables[0] = %+v, want orders with 2 columns", orders)
+	}
+	want := domain.Relation{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}
+	if orders.Relations == nil || (*orders.Relations)[0] != want {
+		t.Errorf("Relations = %v, want %v", orders.Relations, want)
+	}
+}
@@ -0,0 +1,383 @@
+package exporter
+
+import (
+	"diagram-server/internal/diff"
+	"diagram-server/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Script 는 한 방향의 마이그레이션이다. Warnings 의 내용은 SQL 에도 주석으로 들어간다
+type Script struct {
+	SQL      string
+	Warnings []string
+}
+
+type Migration struct {
+	Up   Script
+	Down Script
+}
+
+// MigrationSQL 은 from 을 to 로 바꾸는 스크립트(Up)와 되돌리는 스크립트(Down)를 만든다.
+// 컬럼/테이블 삭제, 타입 축소처럼 데이터를 잃을 수 있는 작업과 dialect 가 지원하지 않는 작업은 경고로 남긴다.
+func MigrationSQL(from, to []domain.Table, dialect Dialect) (*Migration, error) {
+	d, err := sqlDialectFor(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migration{
+		Up:   d.migrate(from, to),
+		Down: d.migrate(to, from),
+	}, nil
+}
+
+type migrationWriter struct {
+	d          sqlDialect
+	statements []string
+	warnings   []string
+}
+
+func (w *migrationWriter) add(format string, args ...any) {
+	w.statements = append(w.statements, fmt.Sprintf(format, args...))
+}
+
+func (w *migrationWriter) warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	w.warnings = append(w.warnings, msg)
+	w.statements = append(w.statements, "-- WARNING: "+msg)
+}
+
+func (w *migrationWriter) script() Script {
+	if len(w.statements) == 0 {
+		return Script{SQL: "-- no changes\n"}
+	}
+	return Script{SQL: strings.Join(w.statements, "\n") + "\n", Warnings: w.warnings}
+}
+
+// migrate 는 외래키 삭제 → 테이블 이름 변경 → 테이블 생성 → 컬럼 추가/변경 → PK 변경 → 컬럼 삭제 → 테이블 삭제 → 외래키 추가 순으로 적는다
+func (d sqlDialect) migrate(from, to []domain.Table) Script {
+	w := &migrationWriter{d: d}
+	cs := diff.Compare(from, to)
+
+	// 변경 전 테이블 → 변경 후 테이블
+	pairs := make(map[*domain.Table]*domain.Table)
+	removed := make(map[*domain.Table]bool)
+	added := make(map[*domain.Table]bool)
+	for _, c := range cs.Changes {
+		switch c.Kind {
+		case diff.TableRenamed:
+			pairs[c.OldTable] = c.NewTable
+		case diff.TableRemoved:
+			removed[c.OldTable] = true
+		case diff.TableAdded:
+			added[c.NewTable] = true
+		}
+	}
+	for i := range from {
+		if t := tableNamed(to, from[i].Name); t != nil {
+			pairs[&from[i]] = t
+		}
+	}
+	newName := func(t *domain.Table) string {
+		if n, ok := pairs[t]; ok {
+			return n.Name
+		}
+		return t.Name
+	}
+
+	oldFKs, newFKs := foreignKeys(from), foreignKeys(to)
+	oldKeys := make(map[string]bool, len(oldFKs))
+	for _, fk := range oldFKs {
+		oldKeys[fkKey(newName(fk.table), fk.columns, newName(fk.refTable), fk.refColumns)] = true
+	}
+	newKeys := make(map[string]bool, len(newFKs))
+	for _, fk := range newFKs {
+		newKeys[fkKey(fk.table.Name, fk.columns, fk.refTable.Name, fk.refColumns)] = true
+	}
+
+	for _, fk := range oldFKs {
+		if removed[fk.table] || newKeys[fkKey(newName(fk.table), fk.columns, newName(fk.refTable), fk.refColumns)] {
+			continue
+		}
+		w.dropForeignKey(fk)
+	}
+
+	for _, c := range cs.Changes {
+		if c.Kind == diff.TableRenamed {
+			w.renameTable(c.OldTable.Name, c.NewTable.Name)
+		}
+	}
+
+	for _, t := range dependencyOrder(to, newFKs) {
+		if !added[t] {
+			continue
+		}
+		// ALTER TABLE 로 외래키를 붙일 수 없으면 CREATE TABLE 에 넣는다
+		var inline []foreignKey
+		if !d.alterConstraint {
+			for _, fk := range newFKs {
+				if fk.table == t {
+					inline = append(inline, fk)
+				}
+			}
+		}
+		w.add("%s", d.createTable(t, inline))
+		for _, comment := range d.columnComments(t) {
+			w.add("%s", comment)
+		}
+	}
+
+	for i := range from {
+		old := &from[i]
+		t, ok := pairs[old]
+		if !ok {
+			continue
+		}
+		w.alterColumns(cs, old, t)
+	}
+
+	order := dependencyOrder(from, oldFKs)
+	for i := len(order) - 1; i >= 0; i-- {
+		if t := order[i]; removed[t] {
+			w.warn("dropping table %s deletes all of its data", t.Name)
+			w.add("DROP TABLE %s;", d.quote(t.Name))
+		}
+	}
+
+	for _, fk := range newFKs {
+		if oldKeys[fkKey(fk.table.Name, fk.columns, fk.refTable.Name, fk.refColumns)] || (added[fk.table] && !d.alterConstraint) {
+			continue
+		}
+		if !d.alterConstraint {
+			w.warn("cannot add foreign key %s to existing table %s; the table must be rebuilt", fk.name(), fk.table.Name)
+			continue
+		}
+		w.add("ALTER TABLE %s ADD %s;", d.quote(fk.table.Name), d.foreignKey(fk))
+	}
+
+	return w.script()
+}
+
+// alterColumns 는 이름이 같거나 바뀐 테이블 한 쌍의 컬럼 변경을 적는다. PK 는 컬럼 삭제 전에 바꾼다
+func (w *migrationWriter) alterColumns(cs *diff.Changeset, old, t *domain.Table) {
+	d := w.d
+	table := d.quote(t.Name)
+
+	altered := make(map[string]bool)
+	for _, c := range cs.Changes {
+		if !strings.EqualFold(c.Table, t.Name) {
+			continue
+		}
+
+		switch c.Kind {
+		case diff.ColumnAdded:
+			if !c.NewColumn.Nullable && !c.NewColumn.PK {
+				w.warn("adding NOT NULL column %s.%s without a default fails if the table has rows", t.Name, c.Column)
+			}
+			w.add("ALTER TABLE %s ADD COLUMN %s;", table, d.column(*c.NewColumn))
+
+		case diff.ColumnTypeChanged, diff.ColumnNullableChanged:
+			key := strings.ToLower(c.Column)
+			if altered[key] {
+				continue
+			}
+			altered[key] = true
+			w.alterColumn(t.Name, c.OldColumn, c.NewColumn)
+		}
+	}
+
+	oldPK, newPK := primaryKey(old), primaryKey(t)
+	if !sameNames(oldPK, newPK) {
+		w.changePrimaryKey(t.Name, oldPK, newPK)
+	}
+
+	for _, c := range cs.Changes {
+		if c.Kind == diff.ColumnRemoved && strings.EqualFold(c.Table, t.Name) {
+			w.warn("dropping column %s.%s deletes its data", t.Name, c.Column)
+			w.add("ALTER TABLE %s DROP COLUMN %s;", table, d.quote(c.Column))
+		}
+	}
+}
+
+func (w *migrationWriter) alterColumn(table string, old, new *domain.Column) {
+	d := w.d
+	typeChanged := !strings.EqualFold(old.Type, new.Type)
+
+	if typeChanged && narrows(old.Type, new.Type) {
+		w.warn("changing %s.%s from %s to %s may truncate or reject existing data", table, new.Name, old.Type, new.Type)
+	}
+	if old.Nullable && !new.Nullable {
+		w.warn("making %s.%s NOT NULL fails if it contains NULL values", table, new.Name)
+	}
+
+	switch {
+	case d.modifyColumn:
+		w.add("ALTER TABLE %s MODIFY COLUMN %s;", d.quote(table), d.column(*new))
+	case d.alterColumn:
+		if typeChanged {
+			w.add("ALTER TABLE %s ALTER COLUMN %s TYPE %s;", d.quote(table), d.quote(new.Name), new.Type)
+		}
+		if old.Nullable != new.Nullable {
+			op := "DROP NOT NULL"
+			if !new.Nullable {
+				op = "SET NOT NULL"
+			}
+			w.add("ALTER TABLE %s ALTER COLUMN %s %s;", d.quote(table), d.quote(new.Name), op)
+		}
+	default:
+		w.warn("cannot alter column %s.%s in place; the table must be rebuilt", table, new.Name)
+	}
+}
+
+func (w *migrationWriter) changePrimaryKey(table string, oldPK, newPK []string) {
+	d := w.d
+	if !d.alterConstraint {
+		w.warn("cannot change the primary key of %s in place; the table must be rebuilt", table)
+		return
+	}
+
+	if len(oldPK) > 0 {
+		if d.namedPrimaryKey {
+			w.add("ALTER TABLE %s DROP CONSTRAINT %s;", d.quote(table), d.quote(unqualifiedName(table)+"_pkey"))
+		} else {
+			w.add("ALTER TABLE %s DROP PRIMARY KEY;", d.quote(table))
+		}
+	}
+	if len(newPK) > 0 {
+		w.add("ALTER TABLE %s ADD PRIMARY KEY (%s);", d.quote(table), d.quoteList(newPK))
+	}
+}
+
+func (w *migrationWriter) dropForeignKey(fk foreignKey) {
+	d := w.d
+	if d.dropForeignKey == "" {
+		w.warn("cannot drop foreign key %s from %s; the table must be rebuilt", fk.name(), fk.table.Name)
+		return
+	}
+	w.add("ALTER TABLE %s DROP %s %s;", d.quote(fk.table.Name), d.dropForeignKey, d.quote(fk.name()))
+}
+
+func (w *migrationWriter) renameTable(old, new string) {
+	d := w.d
+	if d.renameTable {
+		w.add("RENAME TABLE %s TO %s;", d.quote(old), d.quote(new))
+		return
+	}
+	// ALTER TABLE ... RENAME TO 의 새 이름에는 스키마를 붙일 수 없다
+	w.add("ALTER TABLE %s RENAME TO %s;", d.quote(old), d.quote(unqualifiedName(new)))
+}
+
+func tableNamed(tables []domain.Table, name string) *domain.Table {
+	for i := range tables {
+		if strings.EqualFold(tables[i].Name, name) {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+func unqualifiedName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func fkKey(table string, columns []string, refTable string, refColumns []string) string {
+	return strings.ToLower(table + "(" + strings.Join(columns, ",") + ")>" + refTable + "(" + strings.Join(refColumns, ",") + ")")
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var integerRanks = map[string]int{
+	"tinyint":     1,
+	"smallint":    2,
+	"smallserial": 2,
+	"mediumint":   3,
+	"int":         4,
+	"integer":     4,
+	"serial":      4,
+	"bigint":      5,
+	"bigserial":   5,
+}
+
+var unboundedText = map[string]bool{
+	"text":       true,
+	"tinytext":   true,
+	"mediumtext": true,
+	"longtext":   true,
+	"clob":       true,
+}
+
+var boundedText = map[string]bool{
+	"char":              true,
+	"character":         true,
+	"varchar":           true,
+	"character varying": true,
+	"nchar":             true,
+	"nvarchar":          true,
+}
+
+// narrows 는 old 에서 new 로 타입을 바꿀 때 값이 잘리거나 변환에 실패할 수 있는지 판단한다.
+// 알 수 없는 타입끼리의 변경은 안전하다고 볼 수 없으므로 true 다.
+func narrows(old, new string) bool {
+	oldBase, oldParams := parseType(old)
+	newBase, newParams := parseType(new)
+
+	if oldBase == newBase || (boundedText[oldBase] && boundedText[newBase]) {
+		if len(newParams) > 0 && len(oldParams) == 0 {
+			return true
+		}
+		for i := 0; i < len(oldParams) && i < len(newParams); i++ {
+			if newParams[i] < oldParams[i] {
+				return true
+			}
+		}
+		return false
+	}
+
+	if oldRank, ok := integerRanks[oldBase]; ok {
+		if newRank, ok := integerRanks[newBase]; ok {
+			return newRank < oldRank
+		}
+	}
+	if boundedText[oldBase] && unboundedText[newBase] {
+		return false
+	}
+	if unboundedText[oldBase] && unboundedText[newBase] {
+		return false
+	}
+	return true
+}
+
+// parseType 은 "varchar(255)" 를 ("varchar", [255]) 로 나눈다. UNSIGNED 같은 뒤쪽 수식어는 버린다
+func parseType(t string) (string, []int) {
+	t = strings.ToLower(strings.TrimSpace(t))
+	base, rest, _ := strings.Cut(t, "(")
+	base = strings.TrimSpace(base)
+
+	var params []int
+	if args, _, ok := strings.Cut(rest, ")"); ok {
+		for _, arg := range strings.Split(args, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(arg))
+			if err != nil {
+				break
+			}
+			params = append(params, n)
+		}
+	}
+
+	for _, suffix := range []string{" unsigned", " signed", " zerofill"} {
+		base = strings.TrimSuffix(base, suffix)
+	}
+	return base, params
+}
@@ -0,0 +1,213 @@
+package exporter
+
+import (
+	"diagram-server/internal/diff"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/parser"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func migrationTables() (before, after []domain.Table) {
+	userColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "email", Type: "varchar(255)"},
+		{Name: "nickname", Type: "varchar(50)", Nullable: true},
+	}
+	before = []domain.Table{{Name: "users", Columns: &userColumns}}
+
+	accountColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "email", Type: "varchar(100)"},
+		{Name: "nickname", Type: "varchar(50)"},
+		{Name: "age", Type: "int", Nullable: true},
+	}
+	orderColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "user_id", Type: "bigint"},
+	}
+	orderRelations := []domain.Relation{{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}}
+	after = []domain.Table{
+		{Name: "users", Columns: &accountColumns},
+		{Name: "orders", Columns: &orderColumns, Relations: &orderRelations},
+	}
+	return before, after
+}
+
+func TestMigrationSQL(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		parse    parser.Dialect
+		up       []string
+		down     []string
+		warnings []string
+	}{
+		{
+			dialect: Postgres,
+			parse:   parser.Postgres,
+			up: []string{
+				`CREATE TABLE "orders"`,
+				`ALTER TABLE "users" ADD COLUMN "age" int;`,
+				`ALTER TABLE "users" ALTER COLUMN "email" TYPE varchar(100);`,
+				`ALTER TABLE "users" ALTER COLUMN "nickname" SET NOT NULL;`,
+				`ALTER TABLE "orders" ADD CONSTRAINT "fk_orders_user_id" FOREIGN KEY ("user_id") REFERENCES "users" ("id");`,
+			},
+			down: []string{
+				`ALTER TABLE "users" ALTER COLUMN "nickname" DROP NOT NULL;`,
+				`ALTER TABLE "users" DROP COLUMN "age";`,
+				`DROP TABLE "orders";`,
+			},
+			warnings: []string{
+				"changing users.email from varchar(255) to varchar(100) may truncate or reject existing data",
+				"making users.nickname NOT NULL fails if it contains NULL values",
+			},
+		},
+		{
+			dialect: MySQL,
+			parse:   parser.MySQL,
+			up: []string{
+				"ALTER TABLE `users` MODIFY COLUMN `email` varchar(100) NOT NULL;",
+				"ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_user_id`",
+			},
+			down: []string{
+				"ALTER TABLE `users` MODIFY COLUMN `nickname` varchar(50);",
+				"DROP TABLE `orders`;",
+			},
+			warnings: []string{
+				"changing users.email from varchar(255) to varchar(100) may truncate or reject existing data",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			before, after := migrationTables()
+
+			got, err := MigrationSQL(before, after, tt.dialect)
+			if err != nil {
+				t.Fatalf("MigrationSQL() error = %v", err)
+			}
+			for _, w := range tt.up {
+				if !strings.Contains(got.Up.SQL, w) {
+					t.Errorf("Up =\n%s\nmissing %s", got.Up.SQL, w)
+				}
+			}
+			for _, w := range tt.down {
+				if !strings.Contains(got.Down.SQL, w) {
+					t.Errorf("Down =\n%s\nmissing %s", got.Down.SQL, w)
+				}
+			}
+			for _, w := range tt.warnings {
+				if !contains(got.Up.Warnings, w) {
+					t.Errorf("Up.Warnings = %v, missing %q", got.Up.Warnings, w)
+				}
+			}
+			if !contains(got.Down.Warnings, "dropping table orders deletes all of its data") {
+				t.Errorf("Down.Warnings = %v, want table drop warning", got.Down.Warnings)
+			}
+
+			// Up 을 변경 전 스키마에 적용하면 변경 후 스키마와 같아야 한다
+			applied, err := parser.Apply(tt.parse, before, got.Up.SQL)
+			if err != nil {
+				t.Fatalf("Apply(Up) error = %v\n%s", err, got.Up.SQL)
+			}
+			if cs := diff.Compare(applied, after); !cs.Empty() {
+				t.Errorf("Up 적용 결과가 다름:\n%s", cs.Text())
+			}
+
+			reverted, err := parser.Apply(tt.parse, after, got.Down.SQL)
+			if err != nil {
+				t.Fatalf("Apply(Down) error = %v\n%s", err, got.Down.SQL)
+			}
+			if cs := diff.Compare(reverted, before); !cs.Empty() {
+				t.Errorf("Down 적용 결과가 다름:\n%s", cs.Text())
+			}
+		})
+	}
+}
+
+func TestMigrationSQL_DropForeignKey(t *testing.T) {
+	_, after := migrationTables()
+	orderColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "user_id", Type: "bigint"},
+	}
+	withoutFK := []domain.Table{after[0], {Name: "orders", Columns: &orderColumns}}
+
+	got, err := MigrationSQL(after, withoutFK, Postgres)
+	if err != nil {
+		t.Fatalf("MigrationSQL() error = %v", err)
+	}
+	if want := "ALTER TABLE \"orders\" DROP CONSTRAINT \"fk_orders_user_id\";\n"; got.Up.SQL != want {
+		t.Errorf("Up = %q, want %q", got.Up.SQL, want)
+	}
+	if !strings.Contains(got.Down.SQL, `ALTER TABLE "orders" ADD CONSTRAINT "fk_orders_user_id"`) {
+		t.Errorf("Down =\n%s", got.Down.SQL)
+	}
+}
+
+func TestMigrationSQL_RenameTable(t *testing.T) {
+	before, _ := migrationTables()
+	after := []domain.Table{{Name: "accounts", Columns: before[0].Columns}}
+
+	got, err := MigrationSQL(before, after, MySQL)
+	if err != nil {
+		t.Fatalf("MigrationSQL() error = %v", err)
+	}
+	if want := "RENAME TABLE `users` TO `accounts`;\n"; got.Up.SQL != want {
+		t.Errorf("Up = %q, want %q", got.Up.SQL, want)
+	}
+	if strings.Contains(got.Up.SQL, "DROP TABLE") {
+		t.Errorf("rename must not drop the table:\n%s", got.Up.SQL)
+	}
+}
+
+func TestMigrationSQL_SQLiteWarnings(t *testing.T) {
+	before, after := migrationTables()
+
+	got, err := MigrationSQL(before, after, SQLite)
+	if err != nil {
+		t.Fatalf("MigrationSQL() error = %v", err)
+	}
+	if !contains(got.Up.Warnings, "cannot alter column users.email in place; the table must be rebuilt") {
+		t.Errorf("Up.Warnings = %v", got.Up.Warnings)
+	}
+	// SQLite 는 새 테이블의 외래키를 CREATE TABLE 안에 둔다
+	if !strings.Contains(got.Up.SQL, `CONSTRAINT "fk_orders_user_id" FOREIGN KEY ("user_id") REFERENCES "users" ("id")`) {
+		t.Errorf("Up =\n%s", got.Up.SQL)
+	}
+}
+
+func TestMigrationSQL_UnsupportedDialect(t *testing.T) {
+	_, err := MigrationSQL(nil, nil, "oracle")
+	if !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("MigrationSQL() error = %v, want ErrUnsupportedDialect", err)
+	}
+}
+
+func TestNarrows(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{"varchar(255)", "varchar(100)", true},
+		{"varchar(100)", "varchar(255)", false},
+		{"char(10)", "varchar(20)", false},
+		{"text", "varchar(255)", true},
+		{"varchar(255)", "text", false},
+		{"bigint", "int", true},
+		{"int", "bigint", false},
+		{"int unsigned", "int", false},
+		{"decimal(10,2)", "decimal(10,1)", true},
+		{"varchar(20)", "int", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.old+" -> "+tt.new, func(t *testing.T) {
+			if got := narrows(tt.old, tt.new); got != tt.want {
+				t.Errorf("narrows(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
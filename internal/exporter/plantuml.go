@@ -0,0 +1,121 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var plantUMLIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PlantUML IE 표기법에서 왼쪽 기호는 From, 오른쪽 기호는 To 쪽 개수를 나타낸다
+var plantUMLArrows = map[domain.RelationType]string{
+	domain.OneToOne:   "||--||",
+	domain.OneToMany:  "||--o{",
+	domain.ManyToOne:  "}o--||",
+	domain.ManyToMany: "}o--o{",
+}
+
+// PlantUML 은 ERDiagram 을 PlantUML entity 다이어그램으로 만든다.
+// PK 컬럼은 * 를 붙여 구분선(--) 위에 둔다.
+func PlantUML(erd *domain.ERDiagram) string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n")
+	if title := erd.Title(); title != "" {
+		fmt.Fprintf(&sb, "title %s\n", title)
+	}
+	sb.WriteString("hide circle\n")
+	sb.WriteString("skinparam linetype ortho\n\n")
+
+	fkColumns := make(map[string][]string)
+	for _, fk := range foreignKeys(erd.Tables) {
+		fkColumns[fk.table.Name] = append(fkColumns[fk.table.Name], fk.columns...)
+	}
+
+	for _, t := range erd.Tables {
+		fmt.Fprintf(&sb, "entity %s {\n", plantUMLName(t.Name))
+
+		var keys, others []domain.Column
+		if t.Columns != nil {
+			for _, c := range *t.Columns {
+				if c.PK {
+					keys = append(keys, c)
+				} else {
+					others = append(others, c)
+				}
+			}
+		}
+
+		for _, c := range keys {
+			sb.WriteString("  " + plantUMLAttribute(c, contains(fkColumns[t.Name], c.Name)) + "\n")
+		}
+		sb.WriteString("  --\n")
+		for _, c := range others {
+			sb.WriteString("  " + plantUMLAttribute(c, contains(fkColumns[t.Name], c.Name)) + "\n")
+		}
+		sb.WriteString("}\n\n")
+	}
+
+	for _, t := range erd.Tables {
+		if t.Relations == nil {
+			continue
+		}
+		for _, r := range *t.Relations {
+			if line, ok := plantUMLRelation(erd.Tables, r); ok {
+				sb.WriteString(line + "\n")
+			}
+		}
+	}
+
+	sb.WriteString("@enduml\n")
+	return sb.String()
+}
+
+func plantUMLName(name string) string {
+	if plantUMLIdent.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `'`) + `"`
+}
+
+func plantUMLAttribute(c domain.Column, fk bool) string {
+	attr := c.Name + " : " + c.Type
+	switch {
+	case c.PK:
+		attr = "*" + attr
+	case !c.Nullable:
+		attr += " NOT NULL"
+	}
+	if fk {
+		attr += " <<FK>>"
+	}
+	if c.Description != nil {
+		attr += " // " + *c.Description
+	}
+	return attr
+}
+
+func plantUMLRelation(tables []domain.Table, r domain.Relation) (string, bool) {
+	arrow, ok := plantUMLArrows[r.Type]
+	if !ok {
+		return "", false
+	}
+
+	fromTable, fromColumn := domain.ResolveEndpoint(tables, r.From)
+	toTable, _ := domain.ResolveEndpoint(tables, r.To)
+	if fromTable == nil || toTable == nil {
+		return "", false
+	}
+
+	// 외래키 컬럼이 NULL 을 허용하면 참조 대상은 0 또는 1 개다
+	if r.Type == domain.ManyToOne && nullableColumn(fromTable, fromColumn) {
+		arrow = "}o--o|"
+	}
+
+	line := fmt.Sprintf("%s %s %s", plantUMLName(fromTable.Name), arrow, plantUMLName(toTable.Name))
+	if fromColumn != "" {
+		line += " : " + fromColumn
+	}
+	return line, true
+}
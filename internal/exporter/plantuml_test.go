@@ -0,0 +1,24 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlantUML(t *testing.T) {
+	got := PlantUML(newTestDiagram())
+
+	want := []string{
+		"@startuml\ntitle shop\n",
+		"entity orders {\n  *id : bigint\n  --\n  user_id : bigint NOT NULL <<FK>>\n}\n",
+		"  email : varchar(255) NOT NULL // user's email\n",
+		"  nickname : varchar(50)\n",
+		"orders }o--|| users : user_id\n",
+		"@enduml\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("PlantUML() = %s\nmissing %q", got, w)
+		}
+	}
+}
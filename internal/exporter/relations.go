@@ -0,0 +1,153 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"strings"
+)
+
+type foreignKey struct {
+	table      *domain.Table
+	columns    []string
+	refTable   *domain.Table
+	refColumns []string
+}
+
+func (fk foreignKey) name() string {
+	name := "fk_" + fk.table.Name + "_" + strings.Join(fk.columns, "_")
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// foreignKeys 는 관계 목록을 외래키 제약으로 변환한다.
+// ONE_TO_MANY 는 To 쪽이, 나머지는 From 쪽이 외래키를 가진다. MANY_TO_MANY 는 연결 테이블이 필요하므로 제외한다.
+func foreignKeys(tables []domain.Table) []foreignKey {
+	var result []foreignKey
+
+	for i := range tables {
+		if tables[i].Relations == nil {
+			continue
+		}
+
+		for _, r := range *tables[i].Relations {
+			child, parent := r.From, r.To
+			switch r.Type {
+			case domain.ManyToMany:
+				continue
+			case domain.OneToMany:
+				child, parent = r.To, r.From
+			}
+
+			childTable, childColumn := domain.ResolveEndpoint(tables, child)
+			parentTable, parentColumn := domain.ResolveEndpoint(tables, parent)
+			if childTable == nil || parentTable == nil || childColumn == "" {
+				continue
+			}
+			if parentColumn == "" {
+				pk := primaryKey(parentTable)
+				if len(pk) != 1 {
+					continue
+				}
+				parentColumn = pk[0]
+			}
+
+			result = appendForeignKey(result, childTable, childColumn, parentTable, parentColumn)
+		}
+	}
+	return result
+}
+
+// 같은 테이블 쌍의 관계가 대상 테이블의 복합 PK 를 이루면 하나의 복합 외래키로 묶는다
+func appendForeignKey(fks []foreignKey, table *domain.Table, column string, refTable *domain.Table, refColumn string) []foreignKey {
+	pk := primaryKey(refTable)
+	if len(pk) > 1 && contains(pk, refColumn) {
+		for i := range fks {
+			fk := &fks[i]
+			if fk.table == table && fk.refTable == refTable && len(fk.refColumns) < len(pk) &&
+				contains(pk, fk.refColumns[0]) && !contains(fk.refColumns, refColumn) {
+				fk.columns = append(fk.columns, column)
+				fk.refColumns = append(fk.refColumns, refColumn)
+				return fks
+			}
+		}
+	}
+
+	return append(fks, foreignKey{
+		table:      table,
+		columns:    []string{column},
+		refTable:   refTable,
+		refColumns: []string{refColumn},
+	})
+}
+
+func primaryKey(t *domain.Table) []string {
+	if t.Columns == nil {
+		return nil
+	}
+
+	var pk []string
+	for _, c := range *t.Columns {
+		if c.PK {
+			pk = append(pk, c.Name)
+		}
+	}
+	return pk
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyOrder 는 참조되는 테이블이 먼저 오도록 테이블 순서를 정한다.
+// 순환 참조에 걸린 테이블은 원래 순서대로 뒤에 붙는다.
+func dependencyOrder(tables []domain.Table, fks []foreignKey) []*domain.Table {
+	index := make(map[*domain.Table]int, len(tables))
+	for i := range tables {
+		index[&tables[i]] = i
+	}
+
+	deps := make([]map[int]bool, len(tables))
+	for i := range deps {
+		deps[i] = make(map[int]bool)
+	}
+	for _, fk := range fks {
+		from, to := index[fk.table], index[fk.refTable]
+		if from != to {
+			deps[from][to] = true
+		}
+	}
+
+	done := make([]bool, len(tables))
+	var order []*domain.Table
+	for progress := true; progress; {
+		progress = false
+		for i := range tables {
+			if done[i] || !ready(deps[i], done) {
+				continue
+			}
+			done[i] = true
+			order = append(order, &tables[i])
+			progress = true
+			break
+		}
+	}
+
+	for i := range tables {
+		if !done[i] {
+			order = append(order, &tables[i])
+		}
+	}
+	return order
+}
+
+func ready(deps map[int]bool, done []bool) bool {
+	for d := range deps {
+		if !done[d] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+var ErrUnsupportedDialect = errors.New("unsupported dialect")
+
+type sqlDialect struct {
+	quoteChar string
+
+	// MySQL 은 컬럼 정의에 COMMENT 를, PostgreSQL 은 COMMENT ON COLUMN 을 쓴다
+	inlineComment bool
+	commentOn     bool
+
+	// SQLite 는 ALTER TABLE ADD CONSTRAINT 를 지원하지 않는다
+	alterConstraint bool
+
+	backslashEscape bool
+
+	// 마이그레이션용 문법 차이.
+	// PostgreSQL 은 ALTER COLUMN ... TYPE / SET NOT NULL 을, MySQL 은 MODIFY COLUMN 으로 정의 전체를 바꾼다.
+	// SQLite 는 둘 다 없어 테이블을 다시 만들어야 한다.
+	alterColumn    bool
+	modifyColumn   bool
+	renameTable    bool
+	dropForeignKey string
+	// PostgreSQL 의 PK 제약 이름은 기본값인 <table>_pkey 로 가정한다
+	namedPrimaryKey bool
+}
+
+var sqlDialects = map[Dialect]sqlDialect{
+	Postgres: {quoteChar: `"`, commentOn: true, alterConstraint: true, alterColumn: true, dropForeignKey: "CONSTRAINT", namedPrimaryKey: true},
+	MySQL:    {quoteChar: "`", inlineComment: true, alterConstraint: true, backslashEscape: true, modifyColumn: true, renameTable: true, dropForeignKey: "FOREIGN KEY"},
+	SQLite:   {quoteChar: `"`},
+}
+
+func sqlDialectFor(dialect Dialect) (sqlDialect, error) {
+	d, ok := sqlDialects[Dialect(strings.ToLower(string(dialect)))]
+	if !ok {
+		return sqlDialect{}, fmt.Errorf("%w: %s", ErrUnsupportedDialect, dialect)
+	}
+	return d, nil
+}
+
+func (d sqlDialect) quote(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, d.quoteChar, d.quoteChar+d.quoteChar)
+		parts[i] = d.quoteChar + p + d.quoteChar
+	}
+	return strings.Join(parts, ".")
+}
+
+func (d sqlDialect) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = d.quote(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (d sqlDialect) quoteString(s string) string {
+	if d.backslashEscape {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// SQL 은 ERDiagram 의 테이블을 dialect 에 맞는 CREATE TABLE 구문으로 만든다.
+// 참조되는 테이블이 먼저 생성되며, 순환 참조의 외래키는 ALTER TABLE 로 따로 추가한다.
+func SQL(erd *domain.ERDiagram, dialect Dialect) (string, error) {
+	d, err := sqlDialectFor(dialect)
+	if err != nil {
+		return "", err
+	}
+
+	fks := foreignKeys(erd.Tables)
+	order := dependencyOrder(erd.Tables, fks)
+
+	created := make(map[*domain.Table]bool, len(order))
+	var deferred []foreignKey
+	var statements []string
+
+	for _, t := range order {
+		var inline []foreignKey
+		for _, fk := range fks {
+			if fk.table != t {
+				continue
+			}
+			if created[fk.refTable] || fk.refTable == t || !d.alterConstraint {
+				inline = append(inline, fk)
+			} else {
+				deferred = append(deferred, fk)
+			}
+		}
+
+		statements = append(statements, d.createTable(t, inline))
+		statements = append(statements, d.columnComments(t)...)
+		created[t] = true
+	}
+
+	for _, fk := range deferred {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s;", d.quote(fk.table.Name), d.foreignKey(fk)))
+	}
+
+	return strings.Join(statements, "\n\n") + "\n", nil
+}
+
+func (d sqlDialect) createTable(t *domain.Table, fks []foreignKey) string {
+	var defs []string
+
+	if t.Columns != nil {
+		for _, c := range *t.Columns {
+			defs = append(defs, d.column(c))
+		}
+	}
+	if pk := primaryKey(t); len(pk) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", d.quoteList(pk)))
+	}
+	for _, fk := range fks {
+		defs = append(defs, d.foreignKey(fk))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE %s (\n", d.quote(t.Name))
+	for i, def := range defs {
+		sb.WriteString("    ")
+		sb.WriteString(def)
+		if i < len(defs)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(");")
+	return sb.String()
+}
+
+func (d sqlDialect) column(c domain.Column) string {
+	dtype := c.Type
+	if dtype == "" {
+		dtype = "TEXT"
+	}
+
+	def := d.quote(c.Name) + " " + dtype
+	if !c.Nullable || c.PK {
+		def += " NOT NULL"
+	}
+	if d.inlineComment && c.Description != nil {
+		def += " COMMENT " + d.quoteString(*c.Description)
+	}
+	return def
+}
+
+func (d sqlDialect) foreignKey(fk foreignKey) string {
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.quote(fk.name()), d.quoteList(fk.columns), d.quote(fk.refTable.Name), d.quoteList(fk.refColumns))
+}
+
+func (d sqlDialect) columnComments(t *domain.Table) []string {
+	if !d.commentOn || t.Columns == nil {
+		return nil
+	}
+
+	var comments []string
+	for _, c := range *t.Columns {
+		if c.Description == nil {
+			continue
+		}
+		comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;",
+			d.quote(t.Name), d.quote(c.Name), d.quoteString(*c.Description)))
+	}
+	return comments
+}
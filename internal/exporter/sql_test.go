@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"diagram-server/internal/domain"
+	"diagram-server/internal/parser"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestDiagram() *domain.ERDiagram {
+	desc := "user's email"
+	orderColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "user_id", Type: "bigint"},
+	}
+	orderRelations := []domain.Relation{{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}}
+	userColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "email", Type: "varchar(255)", Description: &desc},
+		{Name: "nickname", Type: "varchar(50)", Nullable: true},
+	}
+
+	// orders 가 users 보다 먼저 선언되어 있어도 users 가 먼저 생성되어야 한다
+	return domain.NewERDiagram("shop", nil, "owner", []domain.Table{
+		{Name: "orders", Columns: &orderColumns, Relations: &orderRelations},
+		{Name: "users", Columns: &userColumns},
+	})
+}
+
+func TestSQL(t *testing.T) {
+	tests := []struct {
+		dialect parser.Dialect
+		export  Dialect
+		want    []string
+	}{
+		{
+			dialect: parser.Postgres,
+			export:  Postgres,
+			want: []string{
+				`CREATE TABLE "users"`,
+				`"email" varchar(255) NOT NULL`,
+				`CONSTRAINT "fk_orders_user_id" FOREIGN KEY ("user_id") REFERENCES "users" ("id")`,
+				`COMMENT ON COLUMN "users"."email" IS 'user''s email';`,
+			},
+		},
+		{
+			dialect: parser.MySQL,
+			export:  MySQL,
+			want: []string{
+				"CREATE TABLE `users`",
+				"`email` varchar(255) NOT NULL COMMENT 'user''s email'",
+				"PRIMARY KEY (`id`)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.export), func(t *testing.T) {
+			got, err := SQL(newTestDiagram(), tt.export)
+			if err != nil {
+				t.Fatalf("SQL() error = %v", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("SQL() = %s\nmissing %s", got, w)
+				}
+			}
+			if strings.Index(got, "users") > strings.Index(got, "orders") {
+				t.Errorf("users must be created before orders:\n%s", got)
+			}
+
+			// 생성된 DDL 을 다시 파싱하면 같은 구조가 나와야 한다
+			tables, err := parser.Parse(tt.dialect, got)
+			if err != nil {
+				t.Fatalf("Parse() error = %v\n%s", err, got)
+			}
+			if len(tables) != 2 || tables[1].Relations == nil || (*tables[1].Relations)[0].To != "users.id" {
+				t.Errorf("round trip tables = %+v", tables)
+			}
+		})
+	}
+}
+
+func TestSQL_CyclicReferences(t *testing.T) {
+	aColumns := []domain.Column{{Name: "id", Type: "int", PK: true}, {Name: "b_id", Type: "int"}}
+	bColumns := []domain.Column{{Name: "id", Type: "int", PK: true}, {Name: "a_id", Type: "int"}}
+	aRelations := []domain.Relation{{From: "a.b_id", To: "b.id", Type: domain.ManyToOne}}
+	bRelations := []domain.Relation{{From: "b.a_id", To: "a.id", Type: domain.ManyToOne}}
+	erd := domain.NewERDiagram("cycle", nil, "owner", []domain.Table{
+		{Name: "a", Columns: &aColumns, Relations: &aRelations},
+		{Name: "b", Columns: &bColumns, Relations: &bRelations},
+	})
+
+	got, err := SQL(erd, Postgres)
+	if err != nil {
+		t.Fatalf("SQL() error = %v", err)
+	}
+	if !strings.Contains(got, `ALTER TABLE "a" ADD CONSTRAINT "fk_a_b_id"`) {
+		t.Errorf("cyclic foreign key should be added with ALTER TABLE:\n%s", got)
+	}
+}
+
+func TestSQL_UnsupportedDialect(t *testing.T) {
+	_, err := SQL(newTestDiagram(), "oracle")
+	if !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("SQL() error = %v, want ErrUnsupportedDialect", err)
+	}
+}
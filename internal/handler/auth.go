@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"diagram-server/internal/auth"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/service"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type AuthHandler struct {
+	svc    service.UserService
+	tokens *auth.Issuer
+}
+
+func NewAuthHandler(svc service.UserService, tokens *auth.Issuer) *AuthHandler {
+	return &AuthHandler{svc: svc, tokens: tokens}
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var dto RegisterDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.svc.Register(r.Context(), service.RegisterRequest{
+		Email:    dto.Email,
+		Password: dto.Password,
+		Name:     dto.Name,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toUserResponse(user))
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var dto LoginDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.svc.Login(r.Context(), dto.Email, dto.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	token, expiresAt, err := h.tokens.Issue(auth.Identity{UserID: user.ID(), Email: user.Email(), Name: user.Name()})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt.Format(time.RFC3339),
+		User:        toUserResponse(user),
+	})
+}
+
+func toUserResponse(u *domain.User) UserResponse {
+	return UserResponse{
+		ID:        u.ID(),
+		Email:     u.Email(),
+		Name:      u.Name(),
+		CreatedAt: u.CreatedAt().Format(time.RFC3339),
+	}
+}
@@ -1,17 +1,44 @@
 package handler
 
 type CreateDiagramDTO struct {
-	Title       string     `json:"title"`
-	Owner       string     `json:"owner"`
-	Description *string    `json:"description,omitempty"`
-	Tables      []TableDTO `json:"tables,omitempty"`
+	Type        string        `json:"type,omitempty"`
+	Workspace   string        `json:"workspace,omitempty"`
+	Title       string        `json:"title"`
+	Description *string       `json:"description,omitempty"`
+	Tables      []TableDTO    `json:"tables,omitempty"`
+	Nodes       []FlowNodeDTO `json:"nodes,omitempty"`
+	Edges       []FlowEdgeDTO `json:"edges,omitempty"`
+}
+
+// UpdateDiagramDTO 는 PATCH 본문이다. 생략한 필드는 바뀌지 않고, description 을 "" 로 보내면 설명을 지운다
+type UpdateDiagramDTO struct {
+	Title       *string       `json:"title,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Tables      []TableDTO    `json:"tables,omitempty"`
+	Nodes       []FlowNodeDTO `json:"nodes,omitempty"`
+	Edges       []FlowEdgeDTO `json:"edges,omitempty"`
 }
 
 type TableDTO struct {
-	Name          string        `json:"name"`
-	OriginalQuery *string       `json:"original_query,omitempty"`
-	Columns       []ColumnDTO   `json:"columns,omitempty"`
-	Relations     []RelationDTO `json:"relations,omitempty"`
+	Name          string          `json:"name"`
+	OriginalQuery *string         `json:"original_query,omitempty"`
+	Columns       []ColumnDTO     `json:"columns,omitempty"`
+	Relations     []RelationDTO   `json:"relations,omitempty"`
+	Layout        *TableLayoutDTO `json:"layout,omitempty"`
+}
+
+type TableLayoutDTO struct {
+	X         float64  `json:"x"`
+	Y         float64  `json:"y"`
+	Width     *float64 `json:"width,omitempty"`
+	Collapsed bool     `json:"collapsed"`
+	Color     *string  `json:"color,omitempty"`
+	ZIndex    int      `json:"z"`
+}
+
+// UpdateLayoutDTO 의 키는 테이블 이름이다. null 이면 자동 배치로 되돌린다
+type UpdateLayoutDTO struct {
+	Tables map[string]*TableLayoutDTO `json:"tables"`
 }
 
 type ColumnDTO struct {
@@ -28,13 +55,204 @@ type RelationDTO struct {
 	Type string `json:"type"`
 }
 
+type FlowNodeDTO struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+type FlowEdgeDTO struct {
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Label *string `json:"label,omitempty"`
+}
+
+type DDLDTO struct {
+	Dialect string `json:"dialect"`
+	Query   string `json:"query"`
+}
+
+type ImportDiagramDTO struct {
+	Workspace   string  `json:"workspace,omitempty"`
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+	Dialect     string  `json:"dialect"`
+	Source      string  `json:"source"`
+}
+
+// DiagramResponse 는 목록과 수정 응답에도 쓰므로 자동 배치를 담지 않는다
 type DiagramResponse struct {
-	ID          string     `json:"id"`
-	Type        string     `json:"type"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description,omitempty"`
-	Tables      []TableDTO `json:"tables,omitempty"`
-	Owner       string     `json:"owner"`
-	CreatedAt   string     `json:"createdAt"`
-	ModifiedAt  string     `json:"modifiedAt"`
+	ID          string        `json:"id"`
+	Type        string        `json:"type"`
+	Title       string        `json:"title"`
+	Description *string       `json:"description,omitempty"`
+	Tables      []TableDTO    `json:"tables,omitempty"`
+	Nodes       []FlowNodeDTO `json:"nodes,omitempty"`
+	Edges       []FlowEdgeDTO `json:"edges,omitempty"`
+	Owner       string        `json:"owner"`
+	Workspace   string        `json:"workspace,omitempty"`
+	CreatedAt   string        `json:"createdAt"`
+	ModifiedAt  string        `json:"modifiedAt"`
+	Version     int64         `json:"version"`
+}
+
+// DiagramDetailResponse 는 단건 조회 응답이다. Layout 은 GET /api/diagrams/{id}?layout=true 일 때만 채운다
+type DiagramDetailResponse struct {
+	DiagramResponse
+	Layout *LayoutDTO `json:"layout,omitempty"`
+}
+
+// DiagramPageResponse 의 NextCursor 는 마지막 페이지면 생략한다
+type DiagramPageResponse struct {
+	Items      []DiagramResponse `json:"items"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+type SearchResponse struct {
+	Items []SearchResultDTO `json:"items"`
+}
+
+// SearchResultDTO 는 다이어그램 요약과 일치한 위치다. 전체 내용은 GET /api/diagrams/{id} 로 읽는다
+type SearchResultDTO struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Title      string   `json:"title"`
+	Owner      string   `json:"owner"`
+	Workspace  string   `json:"workspace,omitempty"`
+	ModifiedAt string   `json:"modifiedAt"`
+	Score      float64  `json:"score"`
+	Hits       []HitDTO `json:"hits"`
+}
+
+// HitDTO 의 path 는 테이블이면 "orders", 컬럼이면 "orders.customer_id" 이고 제목/설명이면 비어 있다.
+// ranges 는 text 안에서 일치한 구간으로, 문자 단위 [start, end) 다.
+type HitDTO struct {
+	Path   string     `json:"path,omitempty"`
+	Field  string     `json:"field"`
+	Text   string     `json:"text"`
+	Ranges []RangeDTO `json:"ranges"`
+}
+
+type RangeDTO struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type LayoutDTO struct {
+	Algorithm string        `json:"algorithm"`
+	Width     float64       `json:"width"`
+	Height    float64       `json:"height"`
+	Tables    []TableBoxDTO `json:"tables"`
+}
+
+type TableBoxDTO struct {
+	Table  string  `json:"table"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type RevisionResponse struct {
+	Number    int              `json:"number"`
+	Author    string           `json:"author"`
+	Summary   string           `json:"summary"`
+	CreatedAt string           `json:"createdAt"`
+	Diagram   *DiagramResponse `json:"diagram,omitempty"`
+}
+
+// ChangeDTO 의 From/To 는 변경 전후 값이다. 테이블 이름 변경이면 이름, 컬럼 변경이면 타입이나 true/false 다
+type ChangeDTO struct {
+	Kind     string       `json:"kind"`
+	Table    string       `json:"table"`
+	Column   string       `json:"column,omitempty"`
+	From     string       `json:"from,omitempty"`
+	To       string       `json:"to,omitempty"`
+	Relation *RelationDTO `json:"relation,omitempty"`
+}
+
+type DiffResponse struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Changes []ChangeDTO `json:"changes"`
+}
+
+type MigrationResponse struct {
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Dialect string    `json:"dialect"`
+	Up      ScriptDTO `json:"up"`
+	Down    ScriptDTO `json:"down"`
+}
+
+type ScriptDTO struct {
+	SQL      string   `json:"sql"`
+	Warnings []string `json:"warnings"`
+}
+
+type RegisterDTO struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+type LoginDTO struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type TokenResponse struct {
+	AccessToken string       `json:"accessToken"`
+	TokenType   string       `json:"tokenType"`
+	ExpiresAt   string       `json:"expiresAt"`
+	User        UserResponse `json:"user"`
+}
+
+type UserResponse struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ValidationErrorResponse 의 Fields 키는 요청 본문의 필드 이름이다
+type ValidationErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields"`
+}
+
+// GrantDTO 는 userId 나 email 중 하나만 채운다
+type GrantDTO struct {
+	UserID string `json:"userId,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role"`
+}
+
+type ACLEntryDTO struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+}
+
+type ACLResponse struct {
+	ID      string        `json:"id"`
+	Version int64         `json:"version"`
+	Entries []ACLEntryDTO `json:"entries"`
+}
+
+type CreateWorkspaceDTO struct {
+	Name string `json:"name"`
+}
+
+// MemberDTO 는 초대할 때 userId 나 email 중 하나만 채운다
+type MemberDTO struct {
+	UserID string `json:"userId,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role"`
+}
+
+type WorkspaceResponse struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Members   []ACLEntryDTO `json:"members"`
+	CreatedAt string        `json:"createdAt"`
 }
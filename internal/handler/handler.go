@@ -1,19 +1,32 @@
 package handler
 
 import (
+	"diagram-server/internal/auth"
+	"diagram-server/internal/diff"
 	"diagram-server/internal/domain"
+	"diagram-server/internal/exporter"
+	"diagram-server/internal/layout"
+	"diagram-server/internal/live"
+	"diagram-server/internal/parser"
+	"diagram-server/internal/persistance"
+	"diagram-server/internal/render"
 	"diagram-server/internal/service"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type DiagramHandler struct {
-	svc service.DiagramService
+	svc  service.DiagramService
+	live *live.Hub
 }
 
-func NewDiagramHandler(svc service.DiagramService) *DiagramHandler {
-	return &DiagramHandler{svc: svc}
+func NewDiagramHandler(svc service.DiagramService, hub *live.Hub) *DiagramHandler {
+	return &DiagramHandler{svc: svc, live: hub}
 }
 
 func (h *DiagramHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -24,18 +37,22 @@ func (h *DiagramHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req := service.CreateDiagramRequest{
+		Type:        domain.DiagramType(dto.Type),
+		Workspace:   dto.Workspace,
 		Title:       dto.Title,
 		Description: dto.Description,
-		Owner:       dto.Owner,
 		Tables:      toTableDomains(dto.Tables),
+		Nodes:       toFlowNodeDomains(dto.Nodes),
+		Edges:       toFlowEdgeDomains(dto.Edges),
 	}
 
 	diagram, err := h.svc.Create(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
+	setETag(w, diagram)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(toResponse(diagram))
@@ -43,15 +60,67 @@ func (h *DiagramHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 func (h *DiagramHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
+	contentType, draw := negotiate(r)
+
+	// 와일드카드는 경로 세그먼트 전체와 매칭되므로 /api/diagrams/{id}.svg 는 여기서 처리한다
+	if trimmed, ok := strings.CutSuffix(id, ".svg"); ok {
+		id = trimmed
+		contentType, draw = svgContentType, render.SVG
+	}
 
 	diagram, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, diagram)
+
+	if draw != nil {
+		erd, ok := diagram.(*domain.ERDiagram)
+		if !ok {
+			http.Error(w, service.ErrInvalidDiagramType.Error(), http.StatusNotAcceptable)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(draw(erd)))
 		return
 	}
 
+	resp := DiagramDetailResponse{DiagramResponse: toResponse(diagram)}
+	// 자동 배치는 테이블 수의 제곱에 비례하므로 ?layout=true 로 요청할 때만 계산한다
+	if erd, ok := diagram.(*domain.ERDiagram); ok && wantLayout(r) {
+		resp.Layout = toDiagramLayoutDTO(render.Layout(erd))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(toResponse(diagram))
+	json.NewEncoder(w).Encode(resp)
+}
+
+func wantLayout(r *http.Request) bool {
+	want, _ := strconv.ParseBool(r.URL.Query().Get("layout"))
+	return want
+}
+
+const svgContentType = "image/svg+xml; charset=utf-8"
+
+var textRenderers = map[string]func(*domain.ERDiagram) string{
+	"text/x-plantuml":  exporter.PlantUML,
+	"text/vnd.mermaid": exporter.Mermaid,
+	"text/x-dbml":      exporter.DBML,
+	"image/svg+xml":    render.SVG,
+}
+
+// Accept 헤더가 텍스트 다이어그램 형식을 요청하면 해당 렌더러를 반환한다. 없으면 JSON 으로 응답한다
+func negotiate(r *http.Request) (string, func(*domain.ERDiagram) string) {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(accept, ";", 2)[0])
+		if draw, ok := textRenderers[mediaType]; ok {
+			return mediaType + "; charset=utf-8", draw
+		}
+	}
+	return "", nil
 }
 
 func (h *DiagramHandler) GetAllByType(w http.ResponseWriter, r *http.Request) {
@@ -59,10 +128,111 @@ func (h *DiagramHandler) GetAllByType(w http.ResponseWriter, r *http.Request) {
 
 	diagrams, err := h.svc.GetAllByType(r.Context(), domain.DiagramType(dtype))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	writeDiagrams(w, diagrams)
+}
+
+// List 는 GET /api/diagrams 를 처리한다.
+// 필터는 type, owner, title(접두사), createdAfter/createdBefore, modifiedAfter/modifiedBefore(RFC3339) 이고,
+// sort 는 modifiedAt, title 이며 앞에 - 를 붙이면 내림차순이다. 다음 페이지는 nextCursor 를 cursor 로 넘긴다.
+func (h *DiagramHandler) List(w http.ResponseWriter, r *http.Request) {
+	req, err := toListRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.svc.List(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	items := make([]DiagramResponse, len(page.Diagrams))
+	for i, d := range page.Diagrams {
+		items[i] = toResponse(d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DiagramPageResponse{Items: items, NextCursor: page.Next})
+}
+
+// Search 는 GET /api/diagrams/search?q=...&limit=... 를 처리한다
+func (h *DiagramHandler) Search(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, fmt.Errorf("%w: limit must be a positive integer", persistance.ErrInvalidQuery))
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.svc.Search(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
+	items := make([]SearchResultDTO, len(results))
+	for i, res := range results {
+		items[i] = toSearchResultDTO(res)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SearchResponse{Items: items})
+}
+
+func toListRequest(r *http.Request) (service.ListDiagramsRequest, error) {
+	q := r.URL.Query()
+	req := service.ListDiagramsRequest{
+		Type:        domain.DiagramType(q.Get("type")),
+		Owner:       q.Get("owner"),
+		TitlePrefix: q.Get("title"),
+		Cursor:      q.Get("cursor"),
+	}
+
+	times := []struct {
+		param  string
+		target *time.Time
+	}{
+		{"createdAfter", &req.CreatedAfter},
+		{"createdBefore", &req.CreatedBefore},
+		{"modifiedAfter", &req.ModifiedAfter},
+		{"modifiedBefore", &req.ModifiedBefore},
+	}
+	for _, t := range times {
+		v := q.Get(t.param)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return req, fmt.Errorf("%w: %s must be RFC3339", persistance.ErrInvalidQuery, t.param)
+		}
+		*t.target = parsed
+	}
+
+	if sort := q.Get("sort"); sort != "" {
+		req.Descending = strings.HasPrefix(sort, "-")
+		req.Sort = persistance.SortField(strings.TrimPrefix(sort, "-"))
+	}
+
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return req, fmt.Errorf("%w: limit must be a positive integer", persistance.ErrInvalidQuery)
+		}
+		req.Limit = n
+	}
+	return req, nil
+}
+
+func writeDiagrams(w http.ResponseWriter, diagrams []domain.Diagram) {
 	responses := make([]DiagramResponse, len(diagrams))
 	for i, d := range diagrams {
 		responses[i] = toResponse(d)
@@ -72,22 +242,584 @@ func (h *DiagramHandler) GetAllByType(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(responses)
 }
 
+func (h *DiagramHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var dto UpdateDiagramDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req := service.UpdateDiagramRequest{
+		Version:     version,
+		Title:       dto.Title,
+		Description: dto.Description,
+		Tables:      toTableDomains(dto.Tables),
+		Nodes:       toFlowNodeDomains(dto.Nodes),
+		Edges:       toFlowEdgeDomains(dto.Edges),
+	}
+
+	diagram, err := h.svc.Update(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, diagram)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toResponse(diagram))
+}
+
+func (h *DiagramHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var dto CreateDiagramDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req := service.ReplaceDiagramRequest{
+		Version:     version,
+		Type:        domain.DiagramType(dto.Type),
+		Title:       dto.Title,
+		Description: dto.Description,
+		Tables:      toTableDomains(dto.Tables),
+		Nodes:       toFlowNodeDomains(dto.Nodes),
+		Edges:       toFlowEdgeDomains(dto.Edges),
+	}
+
+	diagram, err := h.svc.Replace(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, diagram)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toResponse(diagram))
+}
+
 func (h *DiagramHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
 	if err := h.svc.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *DiagramHandler) ParseDDL(w http.ResponseWriter, r *http.Request) {
+	var dto DDLDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tables, err := parser.Parse(toDialect(dto.Dialect), dto.Query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toTableDTOs(tables))
+}
+
+// Import 는 여러 DDL/Mermaid/DBML 문서를 한 번에 다이어그램으로 만든다.
+// 모든 문서를 먼저 파싱해서 하나라도 실패하면 아무것도 저장하지 않는다.
+func (h *DiagramHandler) Import(w http.ResponseWriter, r *http.Request) {
+	var dtos []ImportDiagramDTO
+	if err := json.NewDecoder(r.Body).Decode(&dtos); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqs := make([]service.CreateDiagramRequest, len(dtos))
+	for i, dto := range dtos {
+		tables, err := parser.Parse(toDialect(dto.Dialect), dto.Source)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("diagram %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+
+		reqs[i] = service.CreateDiagramRequest{
+			Workspace:   dto.Workspace,
+			Title:       dto.Title,
+			Description: dto.Description,
+			Tables:      tables,
+		}
+	}
+
+	diagrams, err := h.svc.CreateAll(r.Context(), reqs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responses := make([]DiagramResponse, len(diagrams))
+	for i, diagram := range diagrams {
+		responses[i] = toResponse(diagram)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(responses)
+}
+
+func (h *DiagramHandler) ApplyMigration(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var dto DDLDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req := service.MigrationRequest{
+		Version: version,
+		Dialect: toDialect(dto.Dialect),
+		Script:  dto.Query,
+	}
+
+	diagram, err := h.svc.ApplyMigration(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, diagram)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toResponse(diagram))
+}
+
+func (h *DiagramHandler) UpdateLayout(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var dto UpdateLayoutDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	layouts := make(map[string]*domain.TableLayout, len(dto.Tables))
+	for name, l := range dto.Tables {
+		layouts[name] = toLayoutDomain(l)
+	}
+
+	diagram, err := h.svc.UpdateLayout(r.Context(), id, version, layouts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, diagram)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toResponse(diagram))
+}
+
+// Live 는 ERD 의 실시간 공동 편집 웹소켓을 연다
+func (h *DiagramHandler) Live(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, auth.ErrUnauthenticated)
+		return
+	}
+
+	id := r.PathValue("id")
+	erd, err := h.getERDiagram(r, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	role, err := h.svc.RoleOf(r.Context(), erd)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.live.Serve(w, r, id, live.Participant{
+		UserID:   identity.UserID,
+		Name:     identity.Name,
+		ReadOnly: !role.Allows(domain.RoleEditor),
+	})
+}
+
+func (h *DiagramHandler) GetACL(w http.ResponseWriter, r *http.Request) {
+	diagram, err := h.svc.GetACL(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeACL(w, diagram)
+}
+
+// Grant 는 사용자 ID 나 이메일로 역할을 주거나 바꾼다
+func (h *DiagramHandler) Grant(w http.ResponseWriter, r *http.Request) {
+	var dto GrantDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := domain.ParseRole(dto.Role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	diagram, err := h.svc.Grant(r.Context(), r.PathValue("id"), service.GrantRequest{
+		UserID: dto.UserID,
+		Email:  dto.Email,
+		Role:   role,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeACL(w, diagram)
+}
+
+// Revoke 의 {user} 는 사용자 ID 나 이메일이다
+func (h *DiagramHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	diagram, err := h.svc.Revoke(r.Context(), r.PathValue("id"), r.PathValue("user"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeACL(w, diagram)
+}
+
+func writeACL(w http.ResponseWriter, d domain.Diagram) {
+	setETag(w, d)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toACLResponse(d))
+}
+
+func (h *DiagramHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.svc.GetRevisions(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responses := make([]RevisionResponse, len(revisions))
+	for i, rev := range revisions {
+		responses[i] = toRevisionResponse(rev, false)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+func (h *DiagramHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	rev, err := h.svc.GetRevision(r.Context(), r.PathValue("id"), number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toRevisionResponse(rev, true))
+}
+
+func (h *DiagramHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	diagram, err := h.svc.RestoreRevision(r.Context(), r.PathValue("id"), number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, diagram)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toResponse(diagram))
+}
+
+// Diff 는 두 다이어그램의 구조 차이를 반환한다. "id@rev" 로 리비전을 지정할 수 있고,
+// ?format=text 이거나 Accept 가 text/plain 이면 텍스트로 응답한다.
+func (h *DiagramHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	from, err := toDiagramRef(r.PathValue("a"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := toDiagramRef(r.PathValue("b"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cs, err := h.svc.Diff(r.Context(), from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "text" || strings.Contains(r.Header.Get("Accept"), "text/plain") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(cs.Text()))
+		return
+	}
+
+	changes := make([]ChangeDTO, len(cs.Changes))
+	for i, c := range cs.Changes {
+		changes[i] = toChangeDTO(c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DiffResponse{
+		From:    r.PathValue("a"),
+		To:      r.PathValue("b"),
+		Changes: changes,
+	})
+}
+
+// Migration 은 a 를 b 로 바꾸는 마이그레이션 SQL 을 반환한다. ?dialect 기본값은 postgres 이고,
+// ?format=text 이면 ?direction(up, down) 쪽 스크립트만 텍스트로 응답한다.
+func (h *DiagramHandler) Migration(w http.ResponseWriter, r *http.Request) {
+	from, err := toDiagramRef(r.PathValue("a"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := toDiagramRef(r.PathValue("b"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	dialect := exporter.Postgres
+	if d := query.Get("dialect"); d != "" {
+		dialect = exporter.Dialect(d)
+	}
+
+	migration, err := h.svc.Migration(r.Context(), from, to, dialect)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if query.Get("format") == "text" {
+		script := migration.Up
+		switch query.Get("direction") {
+		case "", "up":
+		case "down":
+			script = migration.Down
+		default:
+			http.Error(w, "direction must be up or down", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/sql; charset=utf-8")
+		_, _ = w.Write([]byte(script.SQL))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MigrationResponse{
+		From:    r.PathValue("a"),
+		To:      r.PathValue("b"),
+		Dialect: string(dialect),
+		Up:      toScriptDTO(migration.Up),
+		Down:    toScriptDTO(migration.Down),
+	})
+}
+
+func toDiagramRef(s string) (service.DiagramRef, error) {
+	id, rev, ok := strings.Cut(s, "@")
+	if !ok {
+		return service.DiagramRef{ID: id}, nil
+	}
+
+	number, err := strconv.Atoi(rev)
+	if err != nil || number < 1 {
+		return service.DiagramRef{}, fmt.Errorf("invalid revision number %q", rev)
+	}
+	return service.DiagramRef{ID: id, Revision: number}, nil
+}
+
+func (h *DiagramHandler) ExportSQL(w http.ResponseWriter, r *http.Request) {
+	erd, err := h.getERDiagram(r, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dialect := exporter.Postgres
+	if d := r.URL.Query().Get("dialect"); d != "" {
+		dialect = exporter.Dialect(d)
+	}
+
+	ddl, err := exporter.SQL(erd, dialect)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/sql; charset=utf-8")
+	_, _ = w.Write([]byte(ddl))
+}
+
+func (h *DiagramHandler) ExportMermaid(w http.ResponseWriter, r *http.Request) {
+	erd, err := h.getERDiagram(r, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(exporter.Mermaid(erd)))
+}
+
+func (h *DiagramHandler) ExportDBML(w http.ResponseWriter, r *http.Request) {
+	erd, err := h.getERDiagram(r, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(exporter.DBML(erd)))
+}
+
+func (h *DiagramHandler) getERDiagram(r *http.Request, id string) (*domain.ERDiagram, error) {
+	diagram, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	erd, ok := diagram.(*domain.ERDiagram)
+	if !ok {
+		return nil, service.ErrInvalidDiagramType
+	}
+	return erd, nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var parseErr *parser.Error
+	var validationErr *domain.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ValidationErrorResponse{
+			Error:  validationErr.Err.Error(),
+			Fields: validationErr.Fields,
+		})
+	case errors.Is(err, persistance.ErrEmailTaken),
+		errors.Is(err, persistance.ErrWorkspaceConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidCredentials),
+		errors.Is(err, auth.ErrUnauthenticated):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, persistance.ErrNotFound),
+		errors.Is(err, persistance.ErrRevisionNotFound),
+		errors.Is(err, persistance.ErrUserNotFound),
+		errors.Is(err, persistance.ErrWorkspaceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, persistance.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.As(err, &parseErr),
+		errors.Is(err, parser.ErrUnsupportedDialect),
+		errors.Is(err, exporter.ErrUnsupportedDialect),
+		errors.Is(err, service.ErrInvalidDiagramType),
+		errors.Is(err, domain.ErrUnknownTable),
+		errors.Is(err, domain.ErrInvalidFlowChart),
+		errors.Is(err, domain.ErrInvalidGrant),
+		errors.Is(err, domain.ErrInvalidWorkspace),
+		errors.Is(err, persistance.ErrInvalidQuery),
+		errors.Is(err, persistance.ErrInvalidCursor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ETag 는 다이어그램 버전이다. 표현 형식과 관계없이 같은 버전이면 같은 값을 쓴다
+func setETag(w http.ResponseWriter, d domain.Diagram) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(d.Version(), 10)+`"`)
+}
+
+// ifMatch 는 If-Match 헤더의 버전을 읽는다. 헤더가 없거나 * 이면 AnyVersion 을 반환해 검사하지 않는다.
+// "0" 은 버전이 없던 문서를 가리키는 값이므로 검사한다.
+// 약한 ETag 나 형식이 맞지 않는 값은 어떤 버전과도 일치하지 않으므로 충돌로 처리한다.
+func ifMatch(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return persistance.AnyVersion, nil
+	}
+
+	tag, ok := strings.CutPrefix(value, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if !ok || err != nil || version < 0 {
+		return 0, persistance.ErrVersionConflict
+	}
+	return version, nil
+}
+
+func toDialect(s string) parser.Dialect {
+	if s == "" {
+		return parser.MySQL
+	}
+	return parser.Dialect(s)
+}
+
 func toResponse(d domain.Diagram) DiagramResponse {
 	resp := DiagramResponse{
 		ID:        d.ID(),
 		Type:      string(d.Type()),
 		CreatedAt: d.CreatedAt().Format(time.RFC3339),
+		Workspace: d.Workspace(),
+		Version:   d.Version(),
 	}
 
 	if erd, ok := d.(*domain.ERDiagram); ok {
@@ -98,9 +830,124 @@ func toResponse(d domain.Diagram) DiagramResponse {
 		resp.Tables = toTableDTOs(erd.Tables)
 	}
 
+	if flow, ok := d.(*domain.FlowChart); ok {
+		resp.Title = flow.Title()
+		resp.Owner = flow.Owner()
+		resp.Description = flow.Description()
+		resp.ModifiedAt = flow.ModifiedAt().Format(time.RFC3339)
+		resp.Nodes = toFlowNodeDTOs(flow.Nodes)
+		resp.Edges = toFlowEdgeDTOs(flow.Edges)
+	}
+
+	return resp
+}
+
+// toACLResponse 는 만든 사람을 맨 앞에 두고 권한 목록을 만든다
+func toACLResponse(d domain.Diagram) ACLResponse {
+	entries := []ACLEntryDTO{{UserID: d.Owner(), Role: string(domain.RoleOwner)}}
+	for _, g := range d.ACL() {
+		entries = append(entries, ACLEntryDTO{UserID: g.UserID, Role: string(g.Role)})
+	}
+	return ACLResponse{ID: d.ID(), Version: d.Version(), Entries: entries}
+}
+
+func toSearchResultDTO(res service.SearchResult) SearchResultDTO {
+	d := res.Diagram
+	hits := make([]HitDTO, len(res.Hits))
+	for i, hit := range res.Hits {
+		ranges := make([]RangeDTO, len(hit.Ranges))
+		for j, rg := range hit.Ranges {
+			ranges[j] = RangeDTO{Start: rg.Start, End: rg.End}
+		}
+		hits[i] = HitDTO{Path: hit.Path, Field: string(hit.Field), Text: hit.Text, Ranges: ranges}
+	}
+
+	dto := SearchResultDTO{
+		ID:        d.ID(),
+		Type:      string(d.Type()),
+		Owner:     d.Owner(),
+		Workspace: d.Workspace(),
+		Score:     res.Score,
+		Hits:      hits,
+	}
+	// 검색 결과는 요약이므로 테이블 내용 없이 필요한 필드만 채운다
+	if t, ok := d.(interface {
+		Title() string
+		ModifiedAt() time.Time
+	}); ok {
+		dto.Title = t.Title()
+		dto.ModifiedAt = t.ModifiedAt().Format(time.RFC3339)
+	}
+	return dto
+}
+
+func toRevisionResponse(rev *domain.Revision, withDiagram bool) RevisionResponse {
+	resp := RevisionResponse{
+		Number:    rev.Number,
+		Author:    rev.Author,
+		Summary:   rev.Summary,
+		CreatedAt: rev.CreatedAt.Format(time.RFC3339),
+	}
+	if withDiagram {
+		diagram := toResponse(rev.Diagram)
+		resp.Diagram = &diagram
+	}
 	return resp
 }
 
+func toChangeDTO(c diff.Change) ChangeDTO {
+	dto := ChangeDTO{Kind: string(c.Kind), Table: c.Table, Column: c.Column}
+
+	switch c.Kind {
+	case diff.TableRenamed:
+		dto.From, dto.To = c.OldTable.Name, c.NewTable.Name
+	case diff.ColumnAdded:
+		dto.To = c.NewColumn.Type
+	case diff.ColumnRemoved:
+		dto.From = c.OldColumn.Type
+	case diff.ColumnTypeChanged:
+		dto.From, dto.To = c.OldColumn.Type, c.NewColumn.Type
+	case diff.ColumnNullableChanged:
+		dto.From, dto.To = strconv.FormatBool(c.OldColumn.Nullable), strconv.FormatBool(c.NewColumn.Nullable)
+	case diff.ColumnPKChanged:
+		dto.From, dto.To = strconv.FormatBool(c.OldColumn.PK), strconv.FormatBool(c.NewColumn.PK)
+	case diff.RelationAdded:
+		dto.Relation = toRelationDTO(*c.NewRelation)
+	case diff.RelationRemoved:
+		dto.Relation = toRelationDTO(*c.OldRelation)
+	case diff.RelationTypeChanged:
+		dto.From, dto.To = string(c.OldRelation.Type), string(c.NewRelation.Type)
+		dto.Relation = toRelationDTO(*c.NewRelation)
+	}
+	return dto
+}
+
+func toRelationDTO(r domain.Relation) *RelationDTO {
+	return &RelationDTO{From: r.From, To: r.To, Type: string(r.Type)}
+}
+
+// toScriptDTO 는 경고가 없어도 warnings 를 빈 배열로 내보낸다
+func toScriptDTO(s exporter.Script) ScriptDTO {
+	warnings := s.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return ScriptDTO{SQL: s.SQL, Warnings: warnings}
+}
+
+func toDiagramLayoutDTO(l *layout.Result) *LayoutDTO {
+	boxes := make([]TableBoxDTO, len(l.Boxes))
+	for i, b := range l.Boxes {
+		boxes[i] = TableBoxDTO{Table: b.Table, X: b.X, Y: b.Y, Width: b.Width, Height: b.Height}
+	}
+	return &LayoutDTO{
+		Algorithm: string(l.Algorithm),
+		Width:     l.Width,
+		Height:    l.Height,
+		Tables:    boxes,
+	}
+}
+
 func toTableDomains(dtos []TableDTO) []domain.Table {
 	if dtos == nil {
 		return nil
@@ -113,6 +960,7 @@ func toTableDomains(dtos []TableDTO) []domain.Table {
 			OriginalQuery: dto.OriginalQuery,
 			Columns:       toColumnDomains(dto.Columns),
 			Relations:     toRelationDomains(dto.Relations),
+			Layout:        toLayoutDomain(dto.Layout),
 		}
 	}
 	return result
@@ -160,9 +1008,11 @@ func toTableDTOs(tables []domain.Table) []TableDTO {
 	result := make([]TableDTO, len(tables))
 	for i, t := range tables {
 		result[i] = TableDTO{
-			Name:      t.Name,
-			Columns:   toColumnDTOs(t.Columns),
-			Relations: toRelationDTOs(t.Relations),
+			Name:          t.Name,
+			OriginalQuery: t.OriginalQuery,
+			Columns:       toColumnDTOs(t.Columns),
+			Relations:     toRelationDTOs(t.Relations),
+			Layout:        toLayoutDTO(t.Layout),
 		}
 	}
 	return result
@@ -201,3 +1051,97 @@ func toRelationDTOs(relations *[]domain.Relation) []RelationDTO {
 	}
 	return result
 }
+
+func toLayoutDTO(l *domain.TableLayout) *TableLayoutDTO {
+	if l == nil {
+		return nil
+	}
+
+	return &TableLayoutDTO{
+		X:         l.X,
+		Y:         l.Y,
+		Width:     l.Width,
+		Collapsed: l.Collapsed,
+		Color:     l.Color,
+		ZIndex:    l.ZIndex,
+	}
+}
+
+func toLayoutDomain(dto *TableLayoutDTO) *domain.TableLayout {
+	if dto == nil {
+		return nil
+	}
+
+	return &domain.TableLayout{
+		X:         dto.X,
+		Y:         dto.Y,
+		Width:     dto.Width,
+		Collapsed: dto.Collapsed,
+		Color:     dto.Color,
+		ZIndex:    dto.ZIndex,
+	}
+}
+
+func toFlowNodeDomains(dtos []FlowNodeDTO) []domain.FlowNode {
+	if dtos == nil {
+		return nil
+	}
+
+	result := make([]domain.FlowNode, len(dtos))
+	for i, dto := range dtos {
+		result[i] = domain.FlowNode{
+			ID:    dto.ID,
+			Type:  domain.NodeType(dto.Type),
+			Label: dto.Label,
+		}
+	}
+	return result
+}
+
+func toFlowEdgeDomains(dtos []FlowEdgeDTO) []domain.FlowEdge {
+	if dtos == nil {
+		return nil
+	}
+
+	result := make([]domain.FlowEdge, len(dtos))
+	for i, dto := range dtos {
+		result[i] = domain.FlowEdge{
+			From:  dto.From,
+			To:    dto.To,
+			Label: dto.Label,
+		}
+	}
+	return result
+}
+
+func toFlowNodeDTOs(nodes []domain.FlowNode) []FlowNodeDTO {
+	if nodes == nil {
+		return nil
+	}
+
+	result := make([]FlowNodeDTO, len(nodes))
+	for i, n := range nodes {
+		result[i] = FlowNodeDTO{
+			ID:    n.ID,
+			Type:  string(n.Type),
+			Label: n.Label,
+		}
+	}
+	return result
+}
+
+func toFlowEdgeDTOs(edges []domain.FlowEdge) []FlowEdgeDTO {
+	if edges == nil {
+		return nil
+	}
+
+	result := make([]FlowEdgeDTO, len(edges))
+	for i, e := range edges {
+		result[i] = FlowEdgeDTO{
+			From:  e.From,
+			To:    e.To,
+			Label: e.Label,
+		}
+	}
+	return result
+}
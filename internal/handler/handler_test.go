@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"diagram-server/internal/auth"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"diagram-server/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int64
+		wantErr error
+	}{
+		{name: "헤더가 없으면 조건 없이 수정한다", header: "", want: persistance.AnyVersion},
+		{name: "* 는 조건 없이 수정한다", header: "*", want: persistance.AnyVersion},
+		{name: "0 버전은 조건 없음과 구분한다", header: `"0"`, want: 0},
+		{name: "따옴표로 감싼 버전을 읽는다", header: ` "12" `, want: 12},
+		{name: "따옴표가 없으면 충돌", header: "12", wantErr: persistance.ErrVersionConflict},
+		{name: "weak ETag 는 충돌", header: `W/"12"`, wantErr: persistance.ErrVersionConflict},
+		{name: "음수 버전은 충돌", header: `"-1"`, wantErr: persistance.ErrVersionConflict},
+		{name: "숫자가 아니면 충돌", header: `"abc"`, wantErr: persistance.ErrVersionConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/diagrams/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ifMatch(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ifMatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ifMatch() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "버전 충돌은 412", err: persistance.ErrVersionConflict, want: http.StatusPreconditionFailed},
+		{name: "감싼 버전 충돌도 412", err: fmt.Errorf("diagram 1: %w", persistance.ErrVersionConflict), want: http.StatusPreconditionFailed},
+		{name: "없는 다이어그램은 404", err: persistance.ErrNotFound, want: http.StatusNotFound},
+		{name: "이미 가입한 이메일은 409", err: persistance.ErrEmailTaken, want: http.StatusConflict},
+		{name: "워크스페이스 동시 수정은 409", err: persistance.ErrWorkspaceConflict, want: http.StatusConflict},
+		{name: "로그인 실패는 401", err: service.ErrInvalidCredentials, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, tt.err)
+			if w.Code != tt.want {
+				t.Errorf("writeError() status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteError_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "사용자 검증 실패는 invalid user", err: &domain.ValidationError{Err: domain.ErrInvalidUser, Fields: map[string]string{"email": "is required"}}, want: domain.ErrInvalidUser.Error()},
+		{name: "다른 검증 실패는 그 센티널 메시지", err: &domain.ValidationError{Err: domain.ErrInvalidWorkspace, Fields: map[string]string{"name": "is required"}}, want: domain.ErrInvalidWorkspace.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, tt.err)
+
+			var body ValidationErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusBadRequest || body.Error != tt.want {
+				t.Errorf("writeError() = %d %q, want 400 %q", w.Code, body.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiagramHandler_Layout(t *testing.T) {
+	svc := service.NewDiagramService(persistance.NewMemoryDiagramRepository(), persistance.NewMemoryRevisionRepository(),
+		persistance.NewMemoryUserRepository(), persistance.NewMemoryWorkspaceRepository())
+	h := NewDiagramHandler(svc, nil)
+	ctx := auth.WithIdentity(t.Context(), auth.Identity{UserID: "alice"})
+
+	d, err := svc.Create(ctx, service.CreateDiagramRequest{Title: "Orders", Tables: []domain.Table{{Name: "orders"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		serve      http.HandlerFunc
+		wantLayout bool
+		wantItems  int
+	}{
+		{name: "요청하면 단건 조회에 자동 배치를 담는다", target: "/api/diagrams/" + d.ID() + "?layout=true", serve: h.GetByID, wantLayout: true},
+		{name: "요청하지 않으면 자동 배치를 계산하지 않는다", target: "/api/diagrams/" + d.ID(), serve: h.GetByID},
+		{name: "목록에는 자동 배치가 없다", target: "/api/diagrams?layout=true", serve: h.List, wantItems: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil).WithContext(ctx)
+			r.SetPathValue("id", d.ID())
+			w := httptest.NewRecorder()
+
+			tt.serve(w, r)
+
+			var body struct {
+				Layout json.RawMessage   `json:"layout"`
+				Items  []json.RawMessage `json:"items"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("status %d: %v", w.Code, err)
+			}
+			if len(body.Items) != tt.wantItems {
+				t.Fatalf("items = %d, want %d", len(body.Items), tt.wantItems)
+			}
+			for _, item := range body.Items {
+				var fields map[string]json.RawMessage
+				_ = json.Unmarshal(item, &fields)
+				if _, ok := fields["layout"]; ok {
+					t.Errorf("list item has layout: %s", item)
+				}
+			}
+			if got := body.Layout != nil; got != tt.wantLayout {
+				t.Errorf("layout present = %v, want %v", got, tt.wantLayout)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"diagram-server/internal/domain"
+	"diagram-server/internal/service"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type WorkspaceHandler struct {
+	svc service.WorkspaceService
+}
+
+func NewWorkspaceHandler(svc service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{svc: svc}
+}
+
+func (h *WorkspaceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var dto CreateWorkspaceDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := h.svc.Create(r.Context(), dto.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toWorkspaceResponse(workspace))
+}
+
+func (h *WorkspaceHandler) GetMine(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := h.svc.GetMine(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responses := make([]WorkspaceResponse, len(workspaces))
+	for i, ws := range workspaces {
+		responses[i] = toWorkspaceResponse(ws)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+func (h *WorkspaceHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	workspace, err := h.svc.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeWorkspace(w, workspace)
+}
+
+// AddMember 는 사용자 ID 나 이메일로 구성원을 초대하거나 역할을 바꾼다
+func (h *WorkspaceHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var dto MemberDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := domain.ParseRole(dto.Role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	workspace, err := h.svc.AddMember(r.Context(), r.PathValue("id"), service.MemberRequest{
+		UserID: dto.UserID,
+		Email:  dto.Email,
+		Role:   role,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeWorkspace(w, workspace)
+}
+
+// RemoveMember 의 {user} 는 사용자 ID 나 이메일이다
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	workspace, err := h.svc.RemoveMember(r.Context(), r.PathValue("id"), r.PathValue("user"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeWorkspace(w, workspace)
+}
+
+func (h *WorkspaceHandler) GetDiagrams(w http.ResponseWriter, r *http.Request) {
+	diagrams, err := h.svc.GetDiagrams(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeDiagrams(w, diagrams)
+}
+
+func writeWorkspace(w http.ResponseWriter, workspace *domain.Workspace) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toWorkspaceResponse(workspace))
+}
+
+func toWorkspaceResponse(ws *domain.Workspace) WorkspaceResponse {
+	members := make([]ACLEntryDTO, len(ws.Members()))
+	for i, m := range ws.Members() {
+		members[i] = ACLEntryDTO{UserID: m.UserID, Role: string(m.Role)}
+	}
+
+	return WorkspaceResponse{
+		ID:        ws.ID(),
+		Name:      ws.Name(),
+		Members:   members,
+		CreatedAt: ws.CreatedAt().Format(time.RFC3339),
+	}
+}
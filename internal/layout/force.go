@@ -0,0 +1,136 @@
+package layout
+
+import "math"
+
+const (
+	iterations      = 300
+	overlapPasses   = 200
+	minimumDistance = 0.01
+)
+
+// forceDirected 는 Fruchterman-Reingold 방식으로 노드 중심을 정한 뒤 겹치는 박스를 밀어낸다.
+// 초기 배치를 원 위에 고정하고 난수를 쓰지 않으므로 결과가 항상 같다.
+func forceDirected(sizes []Size, edges []edge) []point {
+	n := len(sizes)
+	if n == 0 {
+		return nil
+	}
+
+	// 이상적인 간선 길이는 평균 박스 크기에 간격을 더한 값이다
+	k := 0.0
+	for _, s := range sizes {
+		k += math.Max(s.Width, s.Height)
+	}
+	k = k/float64(n) + gapX
+
+	centers := make([]point, n)
+	radius := k * float64(n) / (2 * math.Pi)
+	for i := range centers {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		centers[i] = point{radius * math.Cos(angle), radius * math.Sin(angle)}
+	}
+
+	initial := math.Max(radius, k) / 2
+	disp := make([]point, n)
+	for it := 0; it < iterations; it++ {
+		for i := range disp {
+			disp[i] = point{}
+		}
+
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy, dist := delta(centers[i], centers[j], i, j)
+				f := k * k / dist
+				disp[i].x += dx / dist * f
+				disp[i].y += dy / dist * f
+				disp[j].x -= dx / dist * f
+				disp[j].y -= dy / dist * f
+			}
+		}
+		for _, e := range edges {
+			dx, dy, dist := delta(centers[e.child], centers[e.parent], e.child, e.parent)
+			f := dist * dist / k
+			disp[e.child].x -= dx / dist * f
+			disp[e.child].y -= dy / dist * f
+			disp[e.parent].x += dx / dist * f
+			disp[e.parent].y += dy / dist * f
+		}
+
+		temperature := initial * (1 - float64(it)/iterations)
+		for i := range centers {
+			length := math.Hypot(disp[i].x, disp[i].y)
+			if length < minimumDistance {
+				continue
+			}
+			step := math.Min(length, temperature)
+			centers[i].x += disp[i].x / length * step
+			centers[i].y += disp[i].y / length * step
+		}
+	}
+
+	removeOverlaps(centers, sizes)
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	for i, c := range centers {
+		minX = math.Min(minX, c.x-sizes[i].Width/2)
+		minY = math.Min(minY, c.y-sizes[i].Height/2)
+	}
+	points := make([]point, n)
+	for i, c := range centers {
+		points[i] = point{
+			x: c.x - sizes[i].Width/2 - minX + Margin,
+			y: c.y - sizes[i].Height/2 - minY + Margin,
+		}
+	}
+	return points
+}
+
+// delta 는 a-b 벡터와 길이를 반환한다. 두 점이 겹치면 인덱스로 정한 방향을 쓴다
+func delta(a, b point, i, j int) (float64, float64, float64) {
+	dx, dy := a.x-b.x, a.y-b.y
+	dist := math.Hypot(dx, dy)
+	if dist < minimumDistance {
+		angle := float64(i*31+j*17) * 0.618
+		dx, dy, dist = math.Cos(angle)*minimumDistance, math.Sin(angle)*minimumDistance, minimumDistance
+	}
+	return dx, dy, dist
+}
+
+// removeOverlaps 는 간격을 포함해 겹치는 박스 쌍을 겹침이 작은 축으로 반씩 밀어낸다
+func removeOverlaps(centers []point, sizes []Size) {
+	for pass := 0; pass < overlapPasses; pass++ {
+		moved := false
+		for i := range centers {
+			for j := i + 1; j < len(centers); j++ {
+				dx := centers[j].x - centers[i].x
+				dy := centers[j].y - centers[i].y
+				overlapX := (sizes[i].Width+sizes[j].Width)/2 + gapX/2 - math.Abs(dx)
+				overlapY := (sizes[i].Height+sizes[j].Height)/2 + gapY/2 - math.Abs(dy)
+				if overlapX <= 0 || overlapY <= 0 {
+					continue
+				}
+
+				moved = true
+				if overlapX < overlapY {
+					shift := sign(dx) * overlapX / 2
+					centers[i].x -= shift
+					centers[j].x += shift
+				} else {
+					shift := sign(dy) * overlapY / 2
+					centers[i].y -= shift
+					centers[j].y += shift
+				}
+			}
+		}
+		if !moved {
+			return
+		}
+	}
+}
+
+func sign(f float64) float64 {
+	if f < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,169 @@
+package layout
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	dummyWidth = 20.0
+	sweeps     = 8
+)
+
+// layered 는 DAG 를 층으로 나누고, 층을 가로지르는 간선에 더미 노드를 넣은 뒤
+// barycenter 정렬로 교차를 줄여 좌표를 정한다. 연결이 없는 테이블은 맨 아래 격자에 둔다.
+func layered(sizes []Size, edges []edge) []point {
+	n := len(sizes)
+	points := make([]point, n)
+
+	connected := make([]bool, n)
+	for _, e := range edges {
+		connected[e.parent] = true
+		connected[e.child] = true
+	}
+
+	// 가장 긴 경로 기준으로 층을 정한다
+	layer := make([]int, n)
+	for _, v := range topologicalOrder(n, edges) {
+		for _, e := range edges {
+			if e.parent == v && layer[e.child] < layer[v]+1 {
+				layer[e.child] = layer[v] + 1
+			}
+		}
+	}
+
+	depth := 0
+	for v := 0; v < n; v++ {
+		if connected[v] {
+			depth = max(depth, layer[v]+1)
+		}
+	}
+
+	// 노드 0..n-1 은 테이블, 그 뒤는 더미 노드다
+	widths := make([]float64, n)
+	for v, s := range sizes {
+		widths[v] = s.Width
+	}
+	up := make([][]int, n)
+	down := make([][]int, n)
+	layers := make([][]int, depth)
+	for v := 0; v < n; v++ {
+		if connected[v] {
+			layers[layer[v]] = append(layers[layer[v]], v)
+		}
+	}
+
+	addNode := func(l int) int {
+		v := len(widths)
+		widths = append(widths, dummyWidth)
+		up = append(up, nil)
+		down = append(down, nil)
+		layers[l] = append(layers[l], v)
+		return v
+	}
+	for _, e := range edges {
+		prev := e.parent
+		for l := layer[e.parent] + 1; l < layer[e.child]; l++ {
+			d := addNode(l)
+			up[d] = append(up[d], prev)
+			down[prev] = append(down[prev], d)
+			prev = d
+		}
+		up[e.child] = append(up[e.child], prev)
+		down[prev] = append(down[prev], e.child)
+	}
+
+	position := make([]float64, len(widths))
+	for _, nodes := range layers {
+		for i, v := range nodes {
+			position[v] = float64(i)
+		}
+	}
+	for i := 0; i < sweeps; i++ {
+		for l := 1; l < depth; l++ {
+			orderByBarycenter(layers[l], up, position)
+		}
+		for l := depth - 2; l >= 0; l-- {
+			orderByBarycenter(layers[l], down, position)
+		}
+	}
+
+	widest := 0.0
+	for _, nodes := range layers {
+		widest = math.Max(widest, rowWidth(nodes, widths))
+	}
+
+	y := Margin
+	for _, nodes := range layers {
+		x := Margin + (widest-rowWidth(nodes, widths))/2
+		height := 0.0
+		for _, v := range nodes {
+			if v < n {
+				points[v] = point{x, y}
+				height = math.Max(height, sizes[v].Height)
+			}
+			x += widths[v] + gapX
+		}
+		y += height + gapY
+	}
+
+	var isolated []int
+	for v := 0; v < n; v++ {
+		if !connected[v] {
+			isolated = append(isolated, v)
+		}
+	}
+	grid(isolated, sizes, points, y)
+	return points
+}
+
+// orderByBarycenter 는 이웃 층에서의 평균 위치로 노드를 정렬한다. 이웃이 없으면 제자리를 유지한다
+func orderByBarycenter(nodes []int, neighbors [][]int, position []float64) {
+	barycenter := make(map[int]float64, len(nodes))
+	for _, v := range nodes {
+		if len(neighbors[v]) == 0 {
+			barycenter[v] = position[v]
+			continue
+		}
+		sum := 0.0
+		for _, u := range neighbors[v] {
+			sum += position[u]
+		}
+		barycenter[v] = sum / float64(len(neighbors[v]))
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return barycenter[nodes[i]] < barycenter[nodes[j]]
+	})
+	for i, v := range nodes {
+		position[v] = float64(i)
+	}
+}
+
+func rowWidth(nodes []int, widths []float64) float64 {
+	if len(nodes) == 0 {
+		return 0
+	}
+	w := gapX * float64(len(nodes)-1)
+	for _, v := range nodes {
+		w += widths[v]
+	}
+	return w
+}
+
+// grid 는 노드를 top 아래에 정사각형에 가까운 격자로 배치한다
+func grid(nodes []int, sizes []Size, points []point, top float64) {
+	perRow := int(math.Ceil(math.Sqrt(float64(len(nodes)))))
+	x, y, height := Margin, top, 0.0
+
+	for i, v := range nodes {
+		if i > 0 && i%perRow == 0 {
+			x = Margin
+			y += height + gapY
+			height = 0
+		}
+		points[v] = point{x, y}
+		x += sizes[v].Width + gapX
+		height = math.Max(height, sizes[v].Height)
+	}
+}
@@ -0,0 +1,182 @@
+package layout
+
+import (
+	"diagram-server/internal/domain"
+	"math"
+)
+
+const (
+	Margin = 40.0
+	gapX   = 80.0
+	gapY   = 80.0
+)
+
+type Algorithm string
+
+const (
+	// Layered 는 참조되는 테이블을 위 층에 두는 Sugiyama 방식 배치다
+	Layered Algorithm = "layered"
+	// ForceDirected 는 순환 참조가 있어 층을 나눌 수 없을 때 쓰는 배치다
+	ForceDirected Algorithm = "force_directed"
+	// Manual 은 모든 테이블을 사용자가 직접 배치한 경우다
+	Manual Algorithm = "manual"
+)
+
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+type Box struct {
+	Table  string
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+type Result struct {
+	Algorithm Algorithm
+	Width     float64
+	Height    float64
+	// Boxes 는 입력 테이블과 같은 순서다
+	Boxes []Box
+}
+
+// edge 는 참조되는 테이블(parent)에서 참조하는 테이블(child)로 향한다
+type edge struct {
+	parent, child int
+}
+
+// Compute 는 테이블 좌표를 계산한다. sizes 는 tables 와 같은 순서의 박스 크기이며,
+// 같은 입력에 대해서는 항상 같은 결과를 돌려준다. Layout 이 있는 테이블은 그 위치를 그대로 쓴다.
+func Compute(tables []domain.Table, sizes []Size) *Result {
+	edges := buildEdges(tables)
+
+	var points []point
+	result := &Result{Algorithm: Layered}
+	if hasCycle(len(tables), edges) {
+		result.Algorithm = ForceDirected
+		points = forceDirected(sizes, edges)
+	} else {
+		points = layered(sizes, edges)
+	}
+
+	if pin(tables, sizes, points) {
+		result.Algorithm = Manual
+	}
+
+	result.Boxes = make([]Box, len(tables))
+	for i, t := range tables {
+		b := Box{
+			Table:  t.Name,
+			X:      math.Round(points[i].x),
+			Y:      math.Round(points[i].y),
+			Width:  sizes[i].Width,
+			Height: sizes[i].Height,
+		}
+		result.Boxes[i] = b
+		result.Width = math.Max(result.Width, b.X+b.Width+Margin)
+		result.Height = math.Max(result.Height, b.Y+b.Height+Margin)
+	}
+	return result
+}
+
+type point struct {
+	x, y float64
+}
+
+// pin 은 사용자가 배치한 테이블을 제자리에 두고, 자동 배치된 테이블은 겹치지 않도록 그 아래로 내린다.
+// 모든 테이블이 사용자 배치이면 true 를 반환한다.
+func pin(tables []domain.Table, sizes []Size, points []point) bool {
+	pinned, bottom := 0, 0.0
+	for i, t := range tables {
+		if t.Layout == nil {
+			continue
+		}
+		pinned++
+		points[i] = point{t.Layout.X, t.Layout.Y}
+		bottom = math.Max(bottom, t.Layout.Y+sizes[i].Height)
+	}
+	if pinned == 0 || pinned == len(tables) {
+		return pinned > 0
+	}
+
+	for i, t := range tables {
+		if t.Layout == nil {
+			points[i].y += bottom + gapY - Margin
+		}
+	}
+	return false
+}
+
+// buildEdges 는 관계를 중복과 자기 참조가 없는 간선 목록으로 만든다.
+// ONE_TO_MANY 와 MANY_TO_MANY 는 From 이, 나머지는 To 가 parent 다.
+func buildEdges(tables []domain.Table) []edge {
+	indexOf := make(map[*domain.Table]int, len(tables))
+	for i := range tables {
+		indexOf[&tables[i]] = i
+	}
+
+	seen := make(map[edge]bool)
+	var edges []edge
+	for i := range tables {
+		if tables[i].Relations == nil {
+			continue
+		}
+		for _, r := range *tables[i].Relations {
+			fromTable, _ := domain.ResolveEndpoint(tables, r.From)
+			toTable, _ := domain.ResolveEndpoint(tables, r.To)
+			if fromTable == nil || toTable == nil {
+				continue
+			}
+
+			e := edge{parent: indexOf[toTable], child: indexOf[fromTable]}
+			if r.Type == domain.OneToMany || r.Type == domain.ManyToMany {
+				e.parent, e.child = e.child, e.parent
+			}
+			if e.parent == e.child || seen[e] {
+				continue
+			}
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// topologicalOrder 는 진입 차수가 0 인 노드 중 인덱스가 가장 작은 것부터 꺼낸다.
+// 순환이 있으면 일부 노드만 담긴다.
+func topologicalOrder(n int, edges []edge) []int {
+	indegree := make([]int, n)
+	children := make([][]int, n)
+	for _, e := range edges {
+		indegree[e.child]++
+		children[e.parent] = append(children[e.parent], e.child)
+	}
+
+	done := make([]bool, n)
+	order := make([]int, 0, n)
+	for len(order) < n {
+		next := -1
+		for v := 0; v < n; v++ {
+			if !done[v] && indegree[v] == 0 {
+				next = v
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, c := range children[next] {
+			indegree[c]--
+		}
+	}
+	return order
+}
+
+func hasCycle(n int, edges []edge) bool {
+	return len(topologicalOrder(n, edges)) < n
+}
@@ -0,0 +1,154 @@
+package layout
+
+import (
+	"diagram-server/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func table(name string, relations ...domain.Relation) domain.Table {
+	columns := []domain.Column{{Name: "id", Type: "bigint", PK: true}}
+	return domain.Table{Name: name, Columns: &columns, Relations: &relations}
+}
+
+func manyToOne(from, to string) domain.Relation {
+	return domain.Relation{From: from + ".id", To: to + ".id", Type: domain.ManyToOne}
+}
+
+func pinned(t domain.Table, x, y float64) domain.Table {
+	t.Layout = &domain.TableLayout{X: x, Y: y}
+	return t
+}
+
+func sizesOf(tables []domain.Table) []Size {
+	sizes := make([]Size, len(tables))
+	for i := range sizes {
+		sizes[i] = Size{Width: 160, Height: 50 + float64(i%3)*22}
+	}
+	return sizes
+}
+
+func assertNoOverlap(t *testing.T, boxes []Box) {
+	t.Helper()
+	for i := range boxes {
+		for j := i + 1; j < len(boxes); j++ {
+			a, b := boxes[i], boxes[j]
+			if a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height {
+				t.Errorf("%s 와 %s 가 겹침: %+v %+v", a.Table, b.Table, a, b)
+			}
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name      string
+		tables    []domain.Table
+		algorithm Algorithm
+		// above 의 각 쌍은 [위, 아래] 테이블 인덱스다
+		above [][2]int
+		at    map[int][2]float64
+	}{
+		{
+			name: "참조되는 테이블이 위 층에 놓인다",
+			tables: []domain.Table{
+				table("order_items", manyToOne("order_items", "orders"), manyToOne("order_items", "products")),
+				table("orders", manyToOne("orders", "users")),
+				table("users"),
+				table("products"),
+			},
+			algorithm: Layered,
+			above:     [][2]int{{1, 0}, {2, 1}, {3, 0}},
+		},
+		{
+			name: "ONE_TO_MANY 는 From 이 위에 놓인다",
+			tables: []domain.Table{
+				table("users", domain.Relation{From: "users.id", To: "orders.user_id", Type: domain.OneToMany}),
+				table("orders"),
+			},
+			algorithm: Layered,
+			above:     [][2]int{{0, 1}},
+		},
+		{
+			name: "관계가 없는 테이블은 연결된 테이블 아래에 놓인다",
+			tables: []domain.Table{
+				table("audit_log"),
+				table("orders", manyToOne("orders", "users")),
+				table("users"),
+			},
+			algorithm: Layered,
+			above:     [][2]int{{2, 1}, {1, 0}},
+		},
+		{
+			name: "순환 참조는 force directed 로 배치한다",
+			tables: []domain.Table{
+				table("a", manyToOne("a", "b")),
+				table("b", manyToOne("b", "c")),
+				table("c", manyToOne("c", "a")),
+				table("d", manyToOne("d", "a")),
+			},
+			algorithm: ForceDirected,
+		},
+		{
+			name: "사용자가 배치한 테이블은 제자리에 두고 나머지는 아래로 내린다",
+			tables: []domain.Table{
+				pinned(table("orders", manyToOne("orders", "users")), 300, 200),
+				table("users"),
+				table("audit_log"),
+			},
+			algorithm: Layered,
+			above:     [][2]int{{0, 1}, {0, 2}},
+			at:        map[int][2]float64{0: {300, 200}},
+		},
+		{
+			name: "모든 테이블을 사용자가 배치하면 manual 이다",
+			tables: []domain.Table{
+				pinned(table("orders", manyToOne("orders", "users")), 400, 60),
+				pinned(table("users"), 60, 60),
+			},
+			algorithm: Manual,
+			at:        map[int][2]float64{0: {400, 60}, 1: {60, 60}},
+		},
+		{
+			name: "자기 참조는 순환으로 보지 않는다",
+			tables: []domain.Table{
+				table("categories", manyToOne("categories", "categories")),
+			},
+			algorithm: Layered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.tables, sizesOf(tt.tables))
+
+			if got.Algorithm != tt.algorithm {
+				t.Errorf("algorithm = %s, want %s", got.Algorithm, tt.algorithm)
+			}
+			if len(got.Boxes) != len(tt.tables) {
+				t.Fatalf("boxes = %d, want %d", len(got.Boxes), len(tt.tables))
+			}
+			for _, pair := range tt.above {
+				upper, lower := got.Boxes[pair[0]], got.Boxes[pair[1]]
+				if upper.Y+upper.Height > lower.Y {
+					t.Errorf("%s 가 %s 보다 위에 있어야 함: %+v %+v", upper.Table, lower.Table, upper, lower)
+				}
+			}
+			for i, want := range tt.at {
+				if b := got.Boxes[i]; b.X != want[0] || b.Y != want[1] {
+					t.Errorf("%s 위치 = (%v, %v), want %v", b.Table, b.X, b.Y, want)
+				}
+			}
+			for _, b := range got.Boxes {
+				if b.X < Margin || b.Y < Margin || b.X+b.Width > got.Width || b.Y+b.Height > got.Height {
+					t.Errorf("%s 가 캔버스를 벗어남: %+v (%vx%v)", b.Table, b, got.Width, got.Height)
+				}
+			}
+			assertNoOverlap(t, got.Boxes)
+
+			if again := Compute(tt.tables, sizesOf(tt.tables)); !reflect.DeepEqual(got, again) {
+				t.Errorf("같은 입력에 다른 결과:\n%+v\n%+v", got, again)
+			}
+		})
+	}
+}
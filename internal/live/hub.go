@@ -0,0 +1,143 @@
+package live
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultSaveInterval = 5 * time.Second
+	closeTimeout        = 10 * time.Second
+)
+
+// Store 는 방이 다이어그램을 읽고 저장하는 곳이다. 저장은 ctx 의 사용자 권한을 확인하고 리비전을 남긴다.
+// RolesLive 는 연결한 뒤 바뀐 권한을 세션에 반영할 때 저장 주기마다 한 번 부른다.
+type Store interface {
+	LoadLive(ctx context.Context, id string) (*domain.ERDiagram, error)
+	SaveLive(ctx context.Context, erd *domain.ERDiagram) error
+	RolesLive(ctx context.Context, id string, userIDs []string) (map[string]domain.Role, error)
+}
+
+// Hub 는 다이어그램마다 하나의 편집 방(room)을 두고 웹소켓 세션을 연결한다.
+// 방은 첫 세션이 들어올 때 만들어지고 마지막 세션이 나가면 저장한 뒤 사라진다.
+type Hub struct {
+	store        Store
+	saveInterval time.Duration
+	upgrader     websocket.Upgrader
+	sessions     atomic.Int64
+
+	mu     sync.Mutex
+	rooms  map[string]*room
+	closed bool
+}
+
+func NewHub(store Store) *Hub {
+	return &Hub{
+		store:        store,
+		saveInterval: defaultSaveInterval,
+		rooms:        make(map[string]*room),
+	}
+}
+
+// Participant 는 세션의 사용자다. ReadOnly 면 다른 사람의 편집과 커서만 볼 수 있다.
+// ReadOnly 는 연결할 때의 역할이고, 이후에는 방이 저장된 권한으로 다시 확인한다.
+type Participant struct {
+	UserID   string
+	Name     string
+	ReadOnly bool
+}
+
+// Serve 는 요청을 웹소켓으로 올리고 연결이 끊길 때까지 세션을 처리한다.
+// 다이어그램이 있는 ERD 인지와 사용자 인증은 호출하는 쪽에서 먼저 확인한다.
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, id string, user Participant) {
+	rm := h.acquire(id)
+	if rm == nil {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.release(rm)
+
+	// Upgrade 는 실패하면 응답을 직접 쓴다
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	s := newSession(conn, fmt.Sprintf("s%d", h.sessions.Add(1)), user)
+	go s.writeLoop()
+
+	rm.join <- s
+	s.readLoop(rm.inbox)
+	rm.leave <- s
+}
+
+// acquire 는 방을 찾거나 만들고 참조 수를 늘린다. 종료 중이면 nil 이다
+func (h *Hub) acquire(id string) *room {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	rm, ok := h.rooms[id]
+	if !ok {
+		rm = newRoom(h, id)
+		h.rooms[id] = rm
+		go rm.run()
+	}
+	rm.refs++
+	return rm
+}
+
+// release 는 참조 수를 줄이고, 아무도 없으면 방을 목록에서 빼고 멈춘다.
+// 멈춘 방은 남은 변경을 저장하고 끝난다.
+func (h *Hub) release(rm *room) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm.refs--
+	if rm.refs == 0 {
+		delete(h.rooms, rm.id)
+		close(rm.stop)
+	}
+}
+
+// Close 는 새 세션을 막고, 모든 방의 변경을 저장한 뒤 세션을 닫는다. 서버 종료 시 DB 연결을 끊기 전에 부른다
+func (h *Hub) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	rooms := make([]*room, 0, len(h.rooms))
+	for _, rm := range h.rooms {
+		rooms = append(rooms, rm)
+	}
+	h.mu.Unlock()
+
+	for _, rm := range rooms {
+		close(rm.closing)
+	}
+	for _, rm := range rooms {
+		select {
+		case <-rm.done:
+		case <-time.After(closeTimeout):
+		}
+	}
+}
+
+// loadDiagram 은 방이 편집할 ERD 를 읽는다
+func (h *Hub) loadDiagram(id string) (*domain.ERDiagram, error) {
+	ctx, cancel := repoContext()
+	defer cancel()
+
+	return h.store.LoadLive(ctx, id)
+}
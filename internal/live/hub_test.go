@@ -0,0 +1,252 @@
+package live
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"diagram-server/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestHub 는 alice 와 bob 이 편집자, viewer 가 뷰어인 다이어그램 d1 의 허브를 서비스 위에 띄운다
+func newTestHub(t *testing.T) (persistance.DiagramRepository, persistance.RevisionRepository, string) {
+	t.Helper()
+
+	erd := liveDiagram()
+	erd.SetID("d1")
+	grants := map[string]domain.Role{"alice": domain.RoleEditor, "bob": domain.RoleEditor, "viewer": domain.RoleViewer}
+	for user, role := range grants {
+		if err := erd.GrantAccess(user, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := persistance.NewMemoryDiagramRepository()
+	if _, err := repo.Save(context.Background(), erd); err != nil {
+		t.Fatal(err)
+	}
+	revisions := persistance.NewMemoryRevisionRepository()
+	svc := service.NewDiagramService(repo, revisions, persistance.NewMemoryUserRepository(), persistance.NewMemoryWorkspaceRepository())
+
+	hub := NewHub(svc)
+	hub.saveInterval = 20 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.URL.Query().Get("user")
+		// viewer 는 읽기 전용으로 참여한다
+		hub.Serve(w, r, "d1", Participant{UserID: user, Name: user, ReadOnly: user == "viewer"})
+	}))
+	t.Cleanup(func() {
+		hub.Close()
+		server.Close()
+	})
+	return repo, revisions, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dial(t *testing.T, url, user string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?user="+user, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// expect 는 원하는 종류의 메시지가 올 때까지 읽는다. 다른 메시지는 건너뛴다
+func expect(t *testing.T, conn *websocket.Conn, msgType string) serverMessage {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg serverMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for %s: %v", msgType, err)
+		}
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	repo, revisions, url := newTestHub(t)
+
+	alice := dial(t, url, "alice")
+	snapshot := expect(t, alice, "snapshot")
+	if snapshot.Diagram == nil || len(snapshot.Diagram.Tables) != 2 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	bob := dial(t, url, "bob")
+	expect(t, bob, "snapshot")
+	for p := expect(t, alice, "presence"); len(p.Presence) != 2; p = expect(t, alice, "presence") {
+	}
+
+	op := Op{Kind: AddColumn, Table: "users", Def: &ColumnDef{Name: "nickname", Type: "varchar(50)"}}
+	if err := alice.WriteJSON(clientMessage{Type: "op", Ref: "a1", Op: &op}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 보낸 쪽과 다른 쪽 모두 같은 seq 로 연산을 받는다
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		got := expect(t, conn, "op")
+		if got.Seq != 1 || got.User != "alice" || got.Ref != "a1" || got.Op.Def.Name != "nickname" {
+			t.Errorf("op message = %+v", got)
+		}
+	}
+
+	saved := expect(t, bob, "saved")
+	stored, _ := repo.FindByID(context.Background(), "d1")
+	if saved.Version != 2 || stored.Version() != 2 || columnIndex(findTable(stored.(*domain.ERDiagram).Tables, "users"), "nickname") < 0 {
+		t.Errorf("saved = %+v, stored version = %d", saved, stored.Version())
+	}
+	// 실시간 편집도 서비스를 거쳐 리비전을 남긴다
+	if revs, _ := revisions.FindByDiagram(context.Background(), "d1"); len(revs) != 1 || revs[0].Author != "alice" || revs[0].Diagram.Version() != 2 {
+		t.Errorf("revisions = %+v, want one by alice at version 2", revs)
+	}
+
+	// 잘못된 연산은 보낸 쪽에만 오류로 돌아간다
+	bad := Op{Kind: RemoveTable, Table: "nope"}
+	if err := bob.WriteJSON(clientMessage{Type: "op", Ref: "b1", Op: &bad}); err != nil {
+		t.Fatal(err)
+	}
+	if got := expect(t, bob, "error"); got.Ref != "b1" {
+		t.Errorf("error message = %+v", got)
+	}
+}
+
+func TestHub_RebaseOnConflict(t *testing.T) {
+	repo, _, url := newTestHub(t)
+
+	alice := dial(t, url, "alice")
+	expect(t, alice, "snapshot")
+
+	// REST 로 다른 사람이 먼저 저장한 상황
+	external, _ := repo.FindByID(context.Background(), "d1")
+	erd := external.(*domain.ERDiagram)
+	if err := Apply(erd, Op{Kind: AddTable, Table: "items"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(context.Background(), erd); err != nil {
+		t.Fatal(err)
+	}
+
+	op := Op{Kind: AddColumn, Table: "users", Def: &ColumnDef{Name: "age", Type: "int"}}
+	if err := alice.WriteJSON(clientMessage{Type: "op", Op: &op}); err != nil {
+		t.Fatal(err)
+	}
+
+	rebased := expect(t, alice, "snapshot")
+	if len(rebased.Diagram.Tables) != 3 {
+		t.Errorf("rebased snapshot tables = %d, want 3", len(rebased.Diagram.Tables))
+	}
+	expect(t, alice, "saved")
+
+	stored, _ := repo.FindByID(context.Background(), "d1")
+	tables := stored.(*domain.ERDiagram).Tables
+	if findTable(tables, "items") == nil || columnIndex(findTable(tables, "users"), "age") < 0 {
+		t.Errorf("stored tables = %+v", tables)
+	}
+}
+
+func TestHub_ReadOnly(t *testing.T) {
+	repo, _, url := newTestHub(t)
+
+	viewer := dial(t, url, "viewer")
+	expect(t, viewer, "snapshot")
+
+	op := Op{Kind: RemoveTable, Table: "posts"}
+	if err := viewer.WriteJSON(clientMessage{Type: "op", Ref: "v1", Op: &op}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := expect(t, viewer, "error")
+	if msg.Ref != "v1" || msg.Error != ErrReadOnly.Error() {
+		t.Errorf("error = %+v", msg)
+	}
+	if d, _ := repo.FindByID(context.Background(), "d1"); len(d.(*domain.ERDiagram).Tables) != 2 {
+		t.Error("read-only op was applied")
+	}
+}
+
+func TestHub_RefreshesExternalChanges(t *testing.T) {
+	repo, _, url := newTestHub(t)
+
+	alice := dial(t, url, "alice")
+	expect(t, alice, "snapshot")
+
+	// 방에 저장할 연산이 없어도 REST 로 저장된 변경을 다시 읽어 보낸다
+	external, _ := repo.FindByID(context.Background(), "d1")
+	erd := external.(*domain.ERDiagram)
+	if err := Apply(erd, Op{Kind: AddTable, Table: "items"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(context.Background(), erd); err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed := expect(t, alice, "snapshot")
+	if refreshed.Version != erd.Version() || len(refreshed.Diagram.Tables) != 3 {
+		t.Errorf("refreshed snapshot = version %d with %d tables, want version %d with 3", refreshed.Version, len(refreshed.Diagram.Tables), erd.Version())
+	}
+}
+
+func TestHub_RechecksRole(t *testing.T) {
+	repo, _, url := newTestHub(t)
+	ctx := context.Background()
+
+	// 권한이 없는 사용자는 다음 확인 주기에 내보낸다
+	mallory := dial(t, url, "mallory")
+	expect(t, mallory, "snapshot")
+	if msg := expect(t, mallory, "error"); msg.Error != service.ErrForbidden.Error() {
+		t.Errorf("error = %+v, want %v", msg, service.ErrForbidden)
+	}
+
+	// 뷰어로 바뀐 편집자는 다음 확인 주기부터 읽기 전용이 되어 연산이 거절된다
+	bob := dial(t, url, "bob")
+	expect(t, bob, "snapshot")
+	changeRole(t, repo, func(erd *domain.ERDiagram) error { return erd.GrantAccess("bob", domain.RoleViewer) })
+	if msg := expect(t, bob, "error"); msg.Error != ErrReadOnly.Error() {
+		t.Errorf("error = %+v, want %v", msg, ErrReadOnly)
+	}
+	remove := Op{Kind: RemoveTable, Table: "orders"}
+	if err := bob.WriteJSON(clientMessage{Type: "op", Ref: "b1", Op: &remove}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := expect(t, bob, "error"); msg.Ref != "b1" || msg.Error != ErrReadOnly.Error() {
+		t.Errorf("error = %+v, want %v", msg, ErrReadOnly)
+	}
+
+	// 권한을 모두 잃은 세션은 연산을 보내지 않아도 다음 확인 주기에 닫힌다
+	changeRole(t, repo, func(erd *domain.ERDiagram) error { return erd.RevokeAccess("bob") })
+	if msg := expect(t, bob, "error"); msg.Error != service.ErrForbidden.Error() {
+		t.Errorf("error = %+v, want %v", msg, service.ErrForbidden)
+	}
+
+	if d, _ := repo.FindByID(ctx, "d1"); len(d.(*domain.ERDiagram).Tables) != 2 {
+		t.Error("op without the editor role was applied")
+	}
+}
+
+// changeRole 은 REST 로 권한을 바꾼 것처럼 저장소의 ACL 을 고친다
+func changeRole(t *testing.T, repo persistance.DiagramRepository, change func(erd *domain.ERDiagram) error) {
+	t.Helper()
+
+	d, err := repo.FindByID(context.Background(), "d1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := change(d.(*domain.ERDiagram)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(context.Background(), d); err != nil {
+		t.Fatal(err)
+	}
+}
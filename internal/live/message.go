@@ -0,0 +1,107 @@
+package live
+
+import "diagram-server/internal/domain"
+
+// clientMessage 는 클라이언트가 보내는 메시지다.
+//   - {"type":"op","ref":"c1","op":{...}}: 편집 연산. ref 는 응답과 짝을 맞출 때 쓴다
+//   - {"type":"presence","cursor":{"x":1,"y":2},"selection":["users","users.email"]}
+type clientMessage struct {
+	Type      string   `json:"type"`
+	Ref       string   `json:"ref,omitempty"`
+	Op        *Op      `json:"op,omitempty"`
+	Cursor    *Cursor  `json:"cursor,omitempty"`
+	Selection []string `json:"selection,omitempty"`
+}
+
+type message struct {
+	clientMessage
+	from *session
+	err  error
+}
+
+// serverMessage 의 User 는 사용자 ID, Name 은 표시 이름이다. Type 은 snapshot, op, presence, saved, error 중 하나다.
+// Seq 는 지금까지 적용된 연산 수로, 클라이언트는 op 를 seq 순서대로 적용하면 서버와 같은 상태가 된다.
+type serverMessage struct {
+	Type     string     `json:"type"`
+	Seq      int64      `json:"seq"`
+	Session  string     `json:"session,omitempty"`
+	User     string     `json:"user,omitempty"`
+	Name     string     `json:"name,omitempty"`
+	Ref      string     `json:"ref,omitempty"`
+	Op       *Op        `json:"op,omitempty"`
+	Version  int64      `json:"version,omitempty"`
+	Diagram  *Document  `json:"diagram,omitempty"`
+	Presence []Presence `json:"presence,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+func errorMessage(ref string, err error) serverMessage {
+	return serverMessage{Type: "error", Ref: ref, Error: err.Error()}
+}
+
+type Cursor struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type Presence struct {
+	Session   string   `json:"session"`
+	User      string   `json:"user"`
+	Name      string   `json:"name"`
+	Cursor    *Cursor  `json:"cursor,omitempty"`
+	Selection []string `json:"selection,omitempty"`
+}
+
+// Document 는 스냅샷으로 보내는 다이어그램이다. 필드 이름은 REST 응답과 같다
+type Document struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description,omitempty"`
+	Tables      []TableDef `json:"tables"`
+}
+
+type TableDef struct {
+	Name      string        `json:"name"`
+	Columns   []ColumnDef   `json:"columns,omitempty"`
+	Relations []RelationDef `json:"relations,omitempty"`
+	Layout    *LayoutDef    `json:"layout,omitempty"`
+}
+
+type LayoutDef struct {
+	X         float64  `json:"x"`
+	Y         float64  `json:"y"`
+	Width     *float64 `json:"width,omitempty"`
+	Collapsed bool     `json:"collapsed"`
+	Color     *string  `json:"color,omitempty"`
+	ZIndex    int      `json:"z"`
+}
+
+func toDocument(erd *domain.ERDiagram) *Document {
+	tables := make([]TableDef, len(erd.Tables))
+	for i, t := range erd.Tables {
+		def := TableDef{Name: t.Name}
+		for _, c := range columnsOf(&t) {
+			def.Columns = append(def.Columns, ColumnDef{
+				Name:        c.Name,
+				Type:        c.Type,
+				PK:          c.PK,
+				Nullable:    c.Nullable,
+				Description: c.Description,
+			})
+		}
+		for _, r := range relationsOf(&t) {
+			def.Relations = append(def.Relations, RelationDef{From: r.From, To: r.To, Type: string(r.Type)})
+		}
+		if l := t.Layout; l != nil {
+			def.Layout = &LayoutDef{X: l.X, Y: l.Y, Width: l.Width, Collapsed: l.Collapsed, Color: l.Color, ZIndex: l.ZIndex}
+		}
+		tables[i] = def
+	}
+
+	return &Document{
+		ID:          erd.ID(),
+		Title:       erd.Title(),
+		Description: erd.Description(),
+		Tables:      tables,
+	}
+}
@@ -0,0 +1,404 @@
+package live
+
+import (
+	"diagram-server/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidOp = errors.New("invalid operation")
+	ErrReadOnly  = errors.New("read-only participants cannot edit")
+)
+
+type OpKind string
+
+const (
+	AddTable       OpKind = "add_table"
+	RemoveTable    OpKind = "remove_table"
+	RenameTable    OpKind = "rename_table"
+	MoveTable      OpKind = "move_table"
+	AddColumn      OpKind = "add_column"
+	EditColumn     OpKind = "edit_column"
+	RemoveColumn   OpKind = "remove_column"
+	AddRelation    OpKind = "add_relation"
+	RemoveRelation OpKind = "remove_relation"
+)
+
+// Op 는 클라이언트가 보내는 편집 연산 하나다. Kind 에 따라 쓰는 필드가 다르다.
+//   - add_table: Table, Columns, X/Y(선택)
+//   - remove_table: Table
+//   - rename_table: Table, Name
+//   - move_table: Table, X, Y
+//   - add_column: Table, Def
+//   - edit_column: Table, Column, Def
+//   - remove_column: Table, Column
+//   - add_relation, remove_relation: Table(관계를 가진 테이블), Relation
+type Op struct {
+	Kind     OpKind       `json:"kind"`
+	Table    string       `json:"table"`
+	Name     string       `json:"name,omitempty"`
+	Column   string       `json:"column,omitempty"`
+	Def      *ColumnDef   `json:"def,omitempty"`
+	Columns  []ColumnDef  `json:"columns,omitempty"`
+	Relation *RelationDef `json:"relation,omitempty"`
+	X        *float64     `json:"x,omitempty"`
+	Y        *float64     `json:"y,omitempty"`
+}
+
+type ColumnDef struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	PK          bool    `json:"pk"`
+	Nullable    bool    `json:"nullable"`
+	Description *string `json:"description,omitempty"`
+}
+
+type RelationDef struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// Apply 는 연산 하나를 다이어그램에 적용한다. 실패하면 다이어그램은 바뀌지 않는다.
+// 테이블 배치만 바꾸는 move_table 은 modifiedAt 을 갱신하지 않는다.
+func Apply(erd *domain.ERDiagram, op Op) error {
+	if op.Kind == MoveTable {
+		return move(erd, op)
+	}
+
+	tables := cloneTables(erd.Tables)
+	tables, err := apply(tables, op)
+	if err != nil {
+		return err
+	}
+	erd.UpdateTables(tables)
+	return nil
+}
+
+func move(erd *domain.ERDiagram, op Op) error {
+	if op.X == nil || op.Y == nil {
+		return invalid("move_table requires x and y")
+	}
+	t := findTable(erd.Tables, op.Table)
+	if t == nil {
+		return invalid("table %s does not exist", op.Table)
+	}
+
+	layout := domain.TableLayout{}
+	if t.Layout != nil {
+		layout = *t.Layout
+	}
+	layout.X, layout.Y = *op.X, *op.Y
+	return erd.UpdateLayout(map[string]*domain.TableLayout{t.Name: &layout})
+}
+
+func apply(tables []domain.Table, op Op) ([]domain.Table, error) {
+	if op.Kind == AddTable {
+		return addTable(tables, op)
+	}
+
+	idx := tableIndex(tables, op.Table)
+	if idx < 0 {
+		return nil, invalid("table %s does not exist", op.Table)
+	}
+	t := &tables[idx]
+
+	switch op.Kind {
+	case RemoveTable:
+		tables = append(tables[:idx], tables[idx+1:]...)
+		removeRelations(tables, func(r domain.Relation) bool {
+			return pointsTo(r.From, op.Table, "") || pointsTo(r.To, op.Table, "")
+		})
+	case RenameTable:
+		if op.Name == "" {
+			return nil, invalid("rename_table requires name")
+		}
+		if other := tableIndexFold(tables, op.Name); other >= 0 && other != idx {
+			return nil, invalid("table %s already exists", op.Name)
+		}
+		t.Name = op.Name
+		eachRelation(tables, func(r *domain.Relation) {
+			r.From = retarget(r.From, op.Table, "", op.Name, "")
+			r.To = retarget(r.To, op.Table, "", op.Name, "")
+		})
+	case AddColumn:
+		if err := validColumn(op.Def); err != nil {
+			return nil, err
+		}
+		if columnIndex(t, op.Def.Name) >= 0 {
+			return nil, invalid("column %s already exists in table %s", op.Def.Name, t.Name)
+		}
+		columns := append(columnsOf(t), toColumn(*op.Def))
+		t.Columns = &columns
+	case EditColumn:
+		if err := validColumn(op.Def); err != nil {
+			return nil, err
+		}
+		ci := columnIndex(t, op.Column)
+		if ci < 0 {
+			return nil, invalid("column %s does not exist in table %s", op.Column, t.Name)
+		}
+		if other := columnIndex(t, op.Def.Name); other >= 0 && other != ci {
+			return nil, invalid("column %s already exists in table %s", op.Def.Name, t.Name)
+		}
+		(*t.Columns)[ci] = toColumn(*op.Def)
+		eachRelation(tables, func(r *domain.Relation) {
+			r.From = retarget(r.From, t.Name, op.Column, t.Name, op.Def.Name)
+			r.To = retarget(r.To, t.Name, op.Column, t.Name, op.Def.Name)
+		})
+	case RemoveColumn:
+		ci := columnIndex(t, op.Column)
+		if ci < 0 {
+			return nil, invalid("column %s does not exist in table %s", op.Column, t.Name)
+		}
+		columns := append((*t.Columns)[:ci], (*t.Columns)[ci+1:]...)
+		t.Columns = &columns
+		removeRelations(tables, func(r domain.Relation) bool {
+			return pointsTo(r.From, t.Name, op.Column) || pointsTo(r.To, t.Name, op.Column)
+		})
+	case AddRelation:
+		r, err := toRelation(tables, op.Relation)
+		if err != nil {
+			return nil, err
+		}
+		if relationIndex(t, r.From, r.To) >= 0 {
+			return nil, invalid("relation %s -> %s already exists", r.From, r.To)
+		}
+		relations := append(relationsOf(t), r)
+		t.Relations = &relations
+	case RemoveRelation:
+		if op.Relation == nil {
+			return nil, invalid("remove_relation requires relation")
+		}
+		ri := relationIndex(t, op.Relation.From, op.Relation.To)
+		if ri < 0 {
+			return nil, invalid("relation %s -> %s does not exist", op.Relation.From, op.Relation.To)
+		}
+		relations := append((*t.Relations)[:ri], (*t.Relations)[ri+1:]...)
+		t.Relations = &relations
+		if len(relations) == 0 {
+			t.Relations = nil
+		}
+	default:
+		return nil, invalid("unknown kind %q", op.Kind)
+	}
+	return tables, nil
+}
+
+func addTable(tables []domain.Table, op Op) ([]domain.Table, error) {
+	if op.Table == "" {
+		return nil, invalid("add_table requires table")
+	}
+	if tableIndexFold(tables, op.Table) >= 0 {
+		return nil, invalid("table %s already exists", op.Table)
+	}
+
+	t := domain.Table{Name: op.Table}
+	for i := range op.Columns {
+		if err := validColumn(&op.Columns[i]); err != nil {
+			return nil, err
+		}
+		if columnIndex(&t, op.Columns[i].Name) >= 0 {
+			return nil, invalid("duplicate column %s", op.Columns[i].Name)
+		}
+		columns := append(columnsOf(&t), toColumn(op.Columns[i]))
+		t.Columns = &columns
+	}
+	if op.X != nil && op.Y != nil {
+		t.Layout = &domain.TableLayout{X: *op.X, Y: *op.Y}
+	}
+	return append(tables, t), nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOp, fmt.Sprintf(format, args...))
+}
+
+func validColumn(def *ColumnDef) error {
+	if def == nil || def.Name == "" || def.Type == "" {
+		return invalid("column requires name and type")
+	}
+	return nil
+}
+
+func toColumn(def ColumnDef) domain.Column {
+	return domain.Column{
+		Name:        def.Name,
+		Type:        def.Type,
+		PK:          def.PK,
+		Nullable:    def.Nullable,
+		Description: def.Description,
+	}
+}
+
+// toRelation 은 두 끝점이 모두 있는 테이블의 컬럼을 가리키는지 확인한다
+func toRelation(tables []domain.Table, def *RelationDef) (domain.Relation, error) {
+	if def == nil {
+		return domain.Relation{}, invalid("add_relation requires relation")
+	}
+
+	switch domain.RelationType(def.Type) {
+	case domain.OneToOne, domain.OneToMany, domain.ManyToOne, domain.ManyToMany:
+	default:
+		return domain.Relation{}, invalid("unknown relation type %q", def.Type)
+	}
+	for _, endpoint := range []string{def.From, def.To} {
+		t, column := domain.ResolveEndpoint(tables, endpoint)
+		if t == nil || columnIndex(t, column) < 0 {
+			return domain.Relation{}, invalid("relation endpoint %s does not exist", endpoint)
+		}
+	}
+	return domain.Relation{From: def.From, To: def.To, Type: domain.RelationType(def.Type)}, nil
+}
+
+func cloneTables(tables []domain.Table) []domain.Table {
+	result := make([]domain.Table, len(tables))
+	for i, t := range tables {
+		if t.Columns != nil {
+			columns := append([]domain.Column(nil), *t.Columns...)
+			t.Columns = &columns
+		}
+		if t.Relations != nil {
+			relations := append([]domain.Relation(nil), *t.Relations...)
+			t.Relations = &relations
+		}
+		if t.Layout != nil {
+			layout := *t.Layout
+			t.Layout = &layout
+		}
+		result[i] = t
+	}
+	return result
+}
+
+// 연산은 클라이언트가 보고 있는 이름 그대로 테이블을 가리킨다
+func tableIndex(tables []domain.Table, name string) int {
+	for i := range tables {
+		if tables[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func tableIndexFold(tables []domain.Table, name string) int {
+	for i := range tables {
+		if strings.EqualFold(tables[i].Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func findTable(tables []domain.Table, name string) *domain.Table {
+	if i := tableIndex(tables, name); i >= 0 {
+		return &tables[i]
+	}
+	return nil
+}
+
+func columnIndex(t *domain.Table, name string) int {
+	if t.Columns == nil {
+		return -1
+	}
+	for i, c := range *t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func relationIndex(t *domain.Table, from, to string) int {
+	if t.Relations == nil {
+		return -1
+	}
+	for i, r := range *t.Relations {
+		if strings.EqualFold(r.From, from) && strings.EqualFold(r.To, to) {
+			return i
+		}
+	}
+	return -1
+}
+
+func columnsOf(t *domain.Table) []domain.Column {
+	if t.Columns == nil {
+		return nil
+	}
+	return *t.Columns
+}
+
+func relationsOf(t *domain.Table) []domain.Relation {
+	if t.Relations == nil {
+		return nil
+	}
+	return *t.Relations
+}
+
+// pointsTo 는 끝점이 table(.column) 을 가리키는지 본다. column 이 비어 있으면 테이블만 비교한다
+func pointsTo(endpoint, table, column string) bool {
+	rest, ok := cutTable(endpoint, table)
+	if !ok {
+		return false
+	}
+	return column == "" || strings.EqualFold(rest, column)
+}
+
+// retarget 은 table(.column) 을 가리키는 끝점을 newTable(.newColumn) 으로 바꾼다
+func retarget(endpoint, table, column, newTable, newColumn string) string {
+	if !pointsTo(endpoint, table, column) {
+		return endpoint
+	}
+	rest, _ := cutTable(endpoint, table)
+	if column != "" {
+		rest = newColumn
+	}
+	if rest == "" {
+		return newTable
+	}
+	return newTable + "." + rest
+}
+
+func cutTable(endpoint, table string) (string, bool) {
+	if strings.EqualFold(endpoint, table) {
+		return "", true
+	}
+	if len(endpoint) > len(table) && endpoint[len(table)] == '.' && strings.EqualFold(endpoint[:len(table)], table) {
+		return endpoint[len(table)+1:], true
+	}
+	return "", false
+}
+
+func eachRelation(tables []domain.Table, fn func(r *domain.Relation)) {
+	for i := range tables {
+		if tables[i].Relations == nil {
+			continue
+		}
+		for j := range *tables[i].Relations {
+			fn(&(*tables[i].Relations)[j])
+		}
+	}
+}
+
+func removeRelations(tables []domain.Table, drop func(r domain.Relation) bool) {
+	for i := range tables {
+		t := &tables[i]
+		if t.Relations == nil {
+			continue
+		}
+
+		kept := make([]domain.Relation, 0, len(*t.Relations))
+		for _, r := range *t.Relations {
+			if !drop(r) {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == 0 {
+			t.Relations = nil
+			continue
+		}
+		t.Relations = &kept
+	}
+}
@@ -0,0 +1,142 @@
+package live
+
+import (
+	"diagram-server/internal/domain"
+	"errors"
+	"testing"
+)
+
+func float(v float64) *float64 { return &v }
+
+func liveDiagram() *domain.ERDiagram {
+	userColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "email", Type: "varchar(255)"},
+	}
+	orderColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "user_id", Type: "bigint"},
+	}
+	relations := []domain.Relation{{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}}
+
+	return domain.NewERDiagram("shop", nil, "owner", []domain.Table{
+		{Name: "users", Columns: &userColumns},
+		{Name: "orders", Columns: &orderColumns, Relations: &relations},
+	})
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    Op
+		check func(t *testing.T, erd *domain.ERDiagram)
+	}{
+		{
+			name: "테이블을 추가한다",
+			op:   Op{Kind: AddTable, Table: "items", Columns: []ColumnDef{{Name: "id", Type: "int", PK: true}}, X: float(10), Y: float(20)},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				items := findTable(erd.Tables, "items")
+				if items == nil || len(*items.Columns) != 1 || items.Layout == nil || items.Layout.X != 10 {
+					t.Errorf("items = %+v", items)
+				}
+			},
+		},
+		{
+			name: "테이블 이름을 바꾸면 관계 끝점도 바뀐다",
+			op:   Op{Kind: RenameTable, Table: "users", Name: "accounts"},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				if r := (*findTable(erd.Tables, "orders").Relations)[0]; r.To != "accounts.id" {
+					t.Errorf("relation.To = %s, want accounts.id", r.To)
+				}
+			},
+		},
+		{
+			name: "테이블을 지우면 그 테이블을 가리키는 관계도 지운다",
+			op:   Op{Kind: RemoveTable, Table: "users"},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				if len(erd.Tables) != 1 || erd.Tables[0].Relations != nil {
+					t.Errorf("tables = %+v", erd.Tables)
+				}
+			},
+		},
+		{
+			name: "컬럼 이름을 바꾸면 관계 끝점도 바뀐다",
+			op:   Op{Kind: EditColumn, Table: "orders", Column: "user_id", Def: &ColumnDef{Name: "account_id", Type: "bigint"}},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				if r := (*findTable(erd.Tables, "orders").Relations)[0]; r.From != "orders.account_id" {
+					t.Errorf("relation.From = %s, want orders.account_id", r.From)
+				}
+			},
+		},
+		{
+			name: "컬럼을 지우면 그 컬럼을 쓰는 관계도 지운다",
+			op:   Op{Kind: RemoveColumn, Table: "users", Column: "id"},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				if findTable(erd.Tables, "orders").Relations != nil {
+					t.Error("relation to users.id should be removed")
+				}
+			},
+		},
+		{
+			name: "관계를 추가한다",
+			op:   Op{Kind: AddRelation, Table: "users", Relation: &RelationDef{From: "users.id", To: "orders.user_id", Type: "one_to_many"}},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				if rs := findTable(erd.Tables, "users").Relations; rs == nil || len(*rs) != 1 {
+					t.Errorf("relations = %v", rs)
+				}
+			},
+		},
+		{
+			name: "테이블을 옮겨도 modifiedAt 은 그대로다",
+			op:   Op{Kind: MoveTable, Table: "users", X: float(100), Y: float(50)},
+			check: func(t *testing.T, erd *domain.ERDiagram) {
+				if l := findTable(erd.Tables, "users").Layout; l == nil || l.X != 100 || l.Y != 50 {
+					t.Errorf("layout = %+v", l)
+				}
+				if !erd.ModifiedAt().Equal(erd.CreatedAt()) {
+					t.Error("move_table should not touch modifiedAt")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erd := liveDiagram()
+			if err := Apply(erd, tt.op); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			tt.check(t, erd)
+		})
+	}
+}
+
+func TestApply_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Op
+	}{
+		{"없는 테이블", Op{Kind: RemoveTable, Table: "nope"}},
+		{"이미 있는 테이블 추가", Op{Kind: AddTable, Table: "Users"}},
+		{"이미 있는 컬럼 추가", Op{Kind: AddColumn, Table: "users", Def: &ColumnDef{Name: "email", Type: "text"}}},
+		{"타입 없는 컬럼", Op{Kind: AddColumn, Table: "users", Def: &ColumnDef{Name: "age"}}},
+		{"없는 끝점으로 관계 추가", Op{Kind: AddRelation, Table: "orders", Relation: &RelationDef{From: "orders.x", To: "users.id", Type: "many_to_one"}}},
+		{"좌표 없는 이동", Op{Kind: MoveTable, Table: "users"}},
+		{"알 수 없는 연산", Op{Kind: "explode", Table: "users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erd := liveDiagram()
+			before := toDocument(erd)
+
+			err := Apply(erd, tt.op)
+			if !errors.Is(err, ErrInvalidOp) {
+				t.Fatalf("Apply() error = %v, want ErrInvalidOp", err)
+			}
+			if after := toDocument(erd); len(after.Tables) != len(before.Tables) || len(after.Tables[0].Columns) != len(before.Tables[0].Columns) {
+				t.Error("failed operation must not change the diagram")
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package live
+
+import (
+	"context"
+	"diagram-server/internal/auth"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"diagram-server/internal/service"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+const repoTimeout = 10 * time.Second
+
+// room 은 다이어그램 하나의 편집 상태다. 상태는 run 고루틴만 건드리므로
+// 연산은 도착한 순서대로 하나씩 적용되고, 그 순서가 모든 클라이언트에 같은 seq 로 전달된다.
+// 저장 주기마다 남은 연산을 저장하고, 없으면 REST 로 바뀐 다이어그램을 다시 읽는다.
+// 세션의 역할은 저장 주기마다 저장된 권한을 한 번 읽어 모든 세션을 다시 확인하고, 연산은 그 결과로 거른다.
+type room struct {
+	hub *Hub
+	id  string
+
+	join    chan *session
+	leave   chan *session
+	inbox   chan message
+	stop    chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	// hub.mu 로 보호한다
+	refs int
+
+	diagram  *domain.ERDiagram
+	loadErr  error
+	closed   bool
+	seq      int64
+	pending  []Op
+	editor   Participant
+	sessions map[*session]bool
+}
+
+func newRoom(h *Hub, id string) *room {
+	return &room{
+		hub:      h,
+		id:       id,
+		join:     make(chan *session),
+		leave:    make(chan *session),
+		inbox:    make(chan message),
+		stop:     make(chan struct{}),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+		sessions: make(map[*session]bool),
+	}
+}
+
+func (r *room) run() {
+	defer close(r.done)
+
+	r.diagram, r.loadErr = r.hub.loadDiagram(r.id)
+
+	ticker := time.NewTicker(r.hub.saveInterval)
+	defer ticker.Stop()
+
+	closing := r.closing
+	for {
+		select {
+		case s := <-r.join:
+			r.add(s)
+		case s := <-r.leave:
+			r.remove(s)
+		case m := <-r.inbox:
+			r.handle(m)
+		case <-ticker.C:
+			r.recheck()
+			if len(r.pending) > 0 {
+				r.persist()
+			} else {
+				r.refresh()
+			}
+		case <-closing:
+			closing = nil
+			r.closed = true
+			r.persist()
+			for s := range r.sessions {
+				r.remove(s)
+			}
+		case <-r.stop:
+			r.persist()
+			return
+		}
+	}
+}
+
+func (r *room) add(s *session) {
+	if r.loadErr != nil || r.closed {
+		err := r.loadErr
+		if err == nil {
+			err = errors.New("server is shutting down")
+		}
+		// 송신 버퍼가 비어 있으므로 막히지 않는다
+		if data, merr := json.Marshal(errorMessage("", err)); merr == nil {
+			s.out <- data
+		}
+		close(s.out)
+		return
+	}
+
+	r.sessions[s] = true
+	r.send(s, r.snapshot(s))
+	r.broadcastPresence()
+}
+
+// remove 는 세션을 방에서 빼고 송신 채널을 닫는다. 이미 빠진 세션이면 아무것도 하지 않는다
+func (r *room) remove(s *session) {
+	if !r.sessions[s] {
+		return
+	}
+	delete(r.sessions, s)
+	close(s.out)
+	r.broadcastPresence()
+}
+
+func (r *room) handle(m message) {
+	if !r.sessions[m.from] {
+		return
+	}
+	if m.err != nil {
+		r.send(m.from, errorMessage("", m.err))
+		return
+	}
+
+	switch m.Type {
+	case "op":
+		if !r.canEdit(m.from, m.Ref) {
+			return
+		}
+		if m.Op == nil {
+			r.send(m.from, errorMessage(m.Ref, errors.New("op is required")))
+			return
+		}
+		if err := Apply(r.diagram, *m.Op); err != nil {
+			r.send(m.from, errorMessage(m.Ref, err))
+			return
+		}
+
+		r.seq++
+		r.pending = append(r.pending, *m.Op)
+		r.editor = m.from.user
+		r.broadcast(serverMessage{
+			Type:    "op",
+			Seq:     r.seq,
+			Session: m.from.id,
+			User:    m.from.user.UserID,
+			Name:    m.from.user.Name,
+			Ref:     m.Ref,
+			Op:      m.Op,
+		})
+	case "presence":
+		m.from.cursor = m.Cursor
+		m.from.selection = m.Selection
+		r.broadcastPresence()
+	default:
+		r.send(m.from, errorMessage(m.Ref, errors.New("unknown message type "+m.Type)))
+	}
+}
+
+// canEdit 은 마지막으로 확인한 역할로 연산을 거른다. 편집할 수 없으면 ref 를 붙인 오류를 보낸다
+func (r *room) canEdit(s *session, ref string) bool {
+	if s.user.ReadOnly {
+		r.send(s, errorMessage(ref, ErrReadOnly))
+		return false
+	}
+	return true
+}
+
+// recheck 는 저장된 권한을 한 번 읽어 모든 세션의 역할을 반영한다. 읽지 못하면 지금 상태를 유지하고 다음 주기에 다시 확인한다
+func (r *room) recheck() {
+	if len(r.sessions) == 0 {
+		return
+	}
+	users := make([]string, 0, len(r.sessions))
+	for s := range r.sessions {
+		users = append(users, s.user.UserID)
+	}
+
+	ctx, cancel := repoContext()
+	defer cancel()
+
+	roles, err := r.hub.store.RolesLive(ctx, r.id, users)
+	if err != nil {
+		if !errors.Is(err, persistance.ErrNotFound) {
+			log.Printf("[Error] live: failed to check roles on %s: %v", r.id, err)
+		}
+		return
+	}
+	for s := range r.sessions {
+		r.apply(s, roles[s.user.UserID])
+	}
+}
+
+// apply 는 편집 역할을 잃은 세션을 읽기 전용으로 바꾸고, 모든 역할을 잃은 세션은 내보낸다.
+// 편집 역할을 새로 받은 세션은 다시 편집할 수 있다.
+func (r *room) apply(s *session, role domain.Role) {
+	switch {
+	case role.Allows(domain.RoleEditor):
+		s.user.ReadOnly = false
+	case role.Allows(domain.RoleViewer):
+		if !s.user.ReadOnly {
+			s.user.ReadOnly = true
+			r.send(s, errorMessage("", ErrReadOnly))
+		}
+	default:
+		r.send(s, errorMessage("", service.ErrForbidden))
+		r.remove(s)
+	}
+}
+
+// persist 는 저장하지 않은 연산이 있으면 마지막으로 편집한 사용자로 다이어그램을 저장한다.
+// 그 사이 REST 로 다이어그램이 바뀌었으면 저장된 상태에 연산을 다시 적용한 뒤 저장한다.
+func (r *room) persist() {
+	if len(r.pending) == 0 {
+		return
+	}
+
+	ctx, cancel := repoContext()
+	defer cancel()
+	ctx = auth.WithIdentity(ctx, auth.Identity{UserID: r.editor.UserID, Name: r.editor.Name})
+
+	err := r.hub.store.SaveLive(ctx, r.diagram)
+	if errors.Is(err, persistance.ErrVersionConflict) {
+		if err = r.rebase(); err == nil {
+			err = r.hub.store.SaveLive(ctx, r.diagram)
+		}
+	}
+
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, persistance.ErrNotFound):
+		// 다이어그램이 남아 있으면 편집 권한을 잃은 사용자의 연산이므로 버리고 저장된 상태로 되돌린다
+		dropped := len(r.pending)
+		r.pending = nil
+		if err := r.rebase(); err != nil {
+			r.fail(err)
+			return
+		}
+		log.Printf("[INFO] live: dropped %d ops on %s by %s: %v", dropped, r.id, r.editor.UserID, err)
+		r.broadcast(errorMessage("", service.ErrForbidden))
+	case err != nil:
+		r.fail(err)
+	default:
+		r.pending = nil
+		r.broadcast(serverMessage{Type: "saved", Seq: r.seq, Version: r.diagram.Version()})
+	}
+}
+
+// refresh 는 저장된 버전이 방의 버전과 다르면 다시 읽은 다이어그램으로 바꾼다
+func (r *room) refresh() {
+	if len(r.sessions) == 0 {
+		return
+	}
+
+	diagram, err := r.hub.loadDiagram(r.id)
+	switch {
+	case err != nil:
+		r.fail(err)
+	case diagram.Version() != r.diagram.Version():
+		r.replace(diagram)
+	}
+}
+
+// rebase 는 저장된 다이어그램을 다시 읽고 저장하지 않은 연산을 그 위에 다시 적용한다
+func (r *room) rebase() error {
+	diagram, err := r.hub.loadDiagram(r.id)
+	if err != nil {
+		return err
+	}
+	r.replace(diagram)
+	return nil
+}
+
+// replace 는 저장하지 않은 연산을 diagram 에 순서대로 적용해 방의 상태로 삼는다.
+// 더 이상 적용되지 않는 연산은 버리고, 모든 클라이언트에 새 스냅샷을 보낸다.
+func (r *room) replace(diagram *domain.ERDiagram) {
+	kept := r.pending[:0]
+	for _, op := range r.pending {
+		if err := Apply(diagram, op); err != nil {
+			log.Printf("[INFO] live: dropped %s on %s after concurrent change: %v", op.Kind, r.id, err)
+			continue
+		}
+		kept = append(kept, op)
+	}
+	r.pending = kept
+	r.diagram = diagram
+
+	for s := range r.sessions {
+		r.send(s, r.snapshot(s))
+	}
+}
+
+// fail 은 다이어그램이 지워졌으면 모든 세션을 닫는다. 다른 오류는 기록만 하고 다음 주기에 다시 시도한다
+func (r *room) fail(err error) {
+	if !errors.Is(err, persistance.ErrNotFound) {
+		log.Printf("[Error] live: failed to sync diagram %s: %v", r.id, err)
+		return
+	}
+	r.broadcast(errorMessage("", err))
+	for s := range r.sessions {
+		r.remove(s)
+	}
+	r.pending = nil
+}
+
+func (r *room) snapshot(s *session) serverMessage {
+	return serverMessage{
+		Type:     "snapshot",
+		Seq:      r.seq,
+		Session:  s.id,
+		Version:  r.diagram.Version(),
+		Diagram:  toDocument(r.diagram),
+		Presence: r.presence(),
+	}
+}
+
+func (r *room) presence() []Presence {
+	result := make([]Presence, 0, len(r.sessions))
+	for s := range r.sessions {
+		result = append(result, Presence{Session: s.id, User: s.user.UserID, Name: s.user.Name, Cursor: s.cursor, Selection: s.selection})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Session < result[j].Session })
+	return result
+}
+
+func (r *room) broadcastPresence() {
+	r.broadcast(serverMessage{Type: "presence", Seq: r.seq, Presence: r.presence()})
+}
+
+func (r *room) broadcast(msg serverMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("[Error] live: failed to encode %s message: %v", msg.Type, err)
+		return
+	}
+	for s := range r.sessions {
+		r.deliver(s, data)
+	}
+}
+
+// send 는 방에 남아 있는 세션에만 보낸다. 이미 내보낸 세션의 송신 채널은 닫혀 있다
+func (r *room) send(s *session, msg serverMessage) {
+	if !r.sessions[s] {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("[Error] live: failed to encode %s message: %v", msg.Type, err)
+		return
+	}
+	r.deliver(s, data)
+}
+
+// deliver 는 송신 버퍼가 가득 찬 느린 클라이언트를 끊어 방 전체가 막히지 않게 한다
+func (r *room) deliver(s *session, data []byte) {
+	select {
+	case s.out <- data:
+	default:
+		delete(r.sessions, s)
+		close(s.out)
+	}
+}
+
+func repoContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), repoTimeout)
+}
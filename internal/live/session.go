@@ -0,0 +1,87 @@
+package live
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 1 << 20
+	sendBuffer     = 256
+)
+
+// session 은 웹소켓 연결 하나다. out 은 room 만 닫는다
+type session struct {
+	conn *websocket.Conn
+	id   string
+	out  chan []byte
+
+	// room 고루틴만 접근한다. 역할이 바뀌면 room 이 user.ReadOnly 를 고친다
+	user      Participant
+	cursor    *Cursor
+	selection []string
+}
+
+func newSession(conn *websocket.Conn, id string, user Participant) *session {
+	return &session{
+		conn: conn,
+		id:   id,
+		user: user,
+		out:  make(chan []byte, sendBuffer),
+	}
+}
+
+// readLoop 는 연결이 끊길 때까지 받은 메시지를 방으로 넘긴다
+func (s *session) readLoop(inbox chan<- message) {
+	s.conn.SetReadLimit(maxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		m := message{from: s}
+		if err := json.Unmarshal(data, &m.clientMessage); err != nil {
+			m.err = err
+		}
+		inbox <- m
+	}
+}
+
+// writeLoop 는 out 이 닫힐 때까지 메시지를 보내고 주기적으로 ping 한다. 끝나면 연결을 닫는다
+func (s *session) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		s.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-s.out:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
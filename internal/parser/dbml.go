@@ -0,0 +1,487 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"strings"
+)
+
+var dbmlLex = lexOptions{
+	doubleQuoteIdent: true,
+	backslashEscape:  true,
+	identEscape:      true,
+	slashComment:     true,
+	tripleQuote:      true,
+}
+
+// DBML 관계 연산자. 왼쪽 끝점이 From, 오른쪽 끝점이 To 가 된다
+var dbmlRelationTypes = map[string]domain.RelationType{
+	">":  domain.ManyToOne,
+	"<":  domain.OneToMany,
+	"-":  domain.OneToOne,
+	"<>": domain.ManyToMany,
+}
+
+type dbmlEndpoint struct {
+	table   string
+	columns []string
+}
+
+type dbmlRef struct {
+	from, to dbmlEndpoint
+	relType  domain.RelationType
+	line     int
+}
+
+type dbmlParser struct {
+	ts      *tokenStream
+	schema  *schema
+	aliases map[string]string
+	refs    []dbmlRef
+}
+
+// parseDBML 은 dbdiagram.io 의 DBML 문서를 테이블로 변환한다
+func parseDBML(src string) ([]domain.Table, error) {
+	tokens, err := tokenize(src, dbmlLex)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dbmlParser{
+		ts:      &tokenStream{src: src, tokens: tokens},
+		schema:  newSchema(nil),
+		aliases: make(map[string]string),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.schema.tables, nil
+}
+
+func (p *dbmlParser) parse() error {
+	ts := p.ts
+
+	for !ts.atEnd() {
+		switch {
+		case ts.acceptKeyword("Table"):
+			if err := p.table(); err != nil {
+				return err
+			}
+		case ts.acceptKeyword("Ref"):
+			if err := p.ref(); err != nil {
+				return err
+			}
+		case ts.acceptKeyword("Project"), ts.acceptKeyword("Enum"), ts.acceptKeyword("TableGroup"),
+			ts.acceptKeyword("TablePartial"), ts.acceptKeyword("Note"):
+			// 테이블 구조와 관계없는 블록은 건너뛴다
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		default:
+			return ts.errorf("unexpected %s", describe(ts.peek()))
+		}
+	}
+
+	for _, ref := range p.refs {
+		if err := p.addRef(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *dbmlParser) skipBlock() error {
+	ts := p.ts
+	for !ts.atEnd() && !ts.isSymbol("{") {
+		ts.next()
+	}
+	return p.skipBraces()
+}
+
+func (p *dbmlParser) skipBraces() error {
+	ts := p.ts
+	line := ts.peek().line
+	if err := ts.expectSymbol("{"); err != nil {
+		return err
+	}
+
+	depth := 1
+	for depth > 0 {
+		tok := ts.next()
+		switch {
+		case tok.kind == tokEOF:
+			return &Error{Line: line, Message: "unterminated block"}
+		case tok.kind == tokSymbol && tok.text == "{":
+			depth++
+		case tok.kind == tokSymbol && tok.text == "}":
+			depth--
+		}
+	}
+	return nil
+}
+
+// Table name [as alias] [settings] { columns, Note, indexes }
+func (p *dbmlParser) table() error {
+	ts := p.ts
+	line := ts.peek().line
+
+	name, err := ts.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.schema.index(name) >= 0 {
+		return &Error{Line: line, Message: fmt.Sprintf("table %s already exists", name)}
+	}
+	if ts.acceptKeyword("as") {
+		alias, err := ts.ident()
+		if err != nil {
+			return err
+		}
+		p.aliases[strings.ToLower(alias)] = name
+	}
+	if ts.isSymbol("[") {
+		if _, err := p.settings(); err != nil {
+			return err
+		}
+	}
+	if err := ts.expectSymbol("{"); err != nil {
+		return err
+	}
+
+	columns := []domain.Column{}
+	p.schema.tables = append(p.schema.tables, domain.Table{Name: name, Columns: &columns})
+	t := &p.schema.tables[len(p.schema.tables)-1]
+
+	for !ts.acceptSymbol("}") {
+		if ts.atEnd() {
+			return &Error{Line: line, Message: fmt.Sprintf("unterminated table %s", name)}
+		}
+
+		next := ts.peekAt(1)
+		switch {
+		case ts.isKeyword("Note") && next.kind == tokSymbol && (next.text == ":" || next.text == "{"):
+			// 테이블 노트는 다이어그램에 담을 곳이 없다
+			ts.next()
+			if ts.acceptSymbol(":") {
+				ts.next()
+			} else if err := p.skipBraces(); err != nil {
+				return err
+			}
+		case ts.isKeyword("indexes") && next.kind == tokSymbol && next.text == "{":
+			ts.next()
+			if err := p.indexes(t); err != nil {
+				return err
+			}
+		default:
+			if err := p.column(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *dbmlParser) column(t *domain.Table) error {
+	ts := p.ts
+
+	name, err := ts.ident()
+	if err != nil {
+		return err
+	}
+
+	start := ts.peek()
+	if _, err := ts.qualifiedName(); err != nil {
+		return ts.errorf("expected data type, found %s", describe(start))
+	}
+	if ts.isSymbol("(") {
+		ts.skipGroup()
+	}
+	for ts.isSymbol("[") && ts.peekAt(1).kind == tokSymbol && ts.peekAt(1).text == "]" {
+		ts.next()
+		ts.next()
+	}
+
+	dtype := start.text
+	if start.kind != tokQuotedIdent {
+		dtype = ts.text(start, ts.last())
+	}
+	col := domain.Column{Name: name, Type: dtype, Nullable: true}
+
+	if ts.isSymbol("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			switch s.key {
+			case "pk", "primary key":
+				col.PK = true
+			case "not null":
+				col.Nullable = false
+			case "null":
+				col.Nullable = true
+			case "note":
+				note := s.value.text
+				col.Description = &note
+			case "ref":
+				p.refs = append(p.refs, dbmlRef{
+					from:    dbmlEndpoint{table: t.Name, columns: []string{name}},
+					to:      s.ref,
+					relType: s.relType,
+					line:    s.value.line,
+				})
+			}
+		}
+	}
+	if col.PK {
+		col.Nullable = false
+	}
+
+	columns := append(*t.Columns, col)
+	t.Columns = &columns
+	return nil
+}
+
+type dbmlSetting struct {
+	key     string
+	value   token
+	ref     dbmlEndpoint
+	relType domain.RelationType
+}
+
+// [pk, not null, note: '...', ref: > users.id, default: `now()`]
+func (p *dbmlParser) settings() ([]dbmlSetting, error) {
+	ts := p.ts
+	line := ts.peek().line
+	if err := ts.expectSymbol("["); err != nil {
+		return nil, err
+	}
+
+	var settings []dbmlSetting
+	for !ts.acceptSymbol("]") {
+		if ts.atEnd() {
+			return nil, &Error{Line: line, Message: "unterminated settings"}
+		}
+
+		var words []string
+		for ts.peek().kind == tokIdent {
+			words = append(words, strings.ToLower(ts.next().text))
+		}
+		s := dbmlSetting{key: strings.Join(words, " ")}
+
+		if ts.acceptSymbol(":") {
+			if s.key == "ref" {
+				op, err := p.relationOperator()
+				if err != nil {
+					return nil, err
+				}
+				s.relType = op
+				s.value = ts.peek()
+				if s.ref, err = p.endpoint(); err != nil {
+					return nil, err
+				}
+			} else {
+				s.value = ts.next()
+			}
+		}
+		settings = append(settings, s)
+
+		// 색상(#3498DB) 같은 나머지 값은 건너뛴다
+		for !ts.atEnd() && !ts.isSymbol(",") && !ts.isSymbol("]") {
+			ts.next()
+		}
+		ts.acceptSymbol(",")
+	}
+	return settings, nil
+}
+
+// indexes { (a, b) [pk]  email [unique] }
+func (p *dbmlParser) indexes(t *domain.Table) error {
+	ts := p.ts
+	line := ts.peek().line
+	if err := ts.expectSymbol("{"); err != nil {
+		return err
+	}
+
+	for !ts.acceptSymbol("}") {
+		if ts.atEnd() {
+			return &Error{Line: line, Message: "unterminated indexes"}
+		}
+
+		var columns []string
+		if ts.isSymbol("(") {
+			ts.next()
+			for !ts.acceptSymbol(")") {
+				if ts.atEnd() {
+					return &Error{Line: line, Message: "unterminated index columns"}
+				}
+				tok := ts.next()
+				if tok.kind == tokIdent || tok.kind == tokQuotedIdent {
+					columns = append(columns, tok.text)
+				}
+			}
+		} else {
+			tok := ts.next()
+			columns = append(columns, tok.text)
+		}
+
+		if !ts.isSymbol("[") {
+			continue
+		}
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			if s.key == "pk" || s.key == "primary key" {
+				p.schema.setPrimaryKey(t.Name, columns)
+			}
+		}
+	}
+	return nil
+}
+
+// Ref [name]: a.b > c.d  또는  Ref [name] { a.b > c.d ... }
+func (p *dbmlParser) ref() error {
+	ts := p.ts
+	if ts.peek().kind == tokIdent || ts.peek().kind == tokQuotedIdent {
+		ts.next()
+	}
+
+	if ts.acceptSymbol(":") {
+		return p.refLine()
+	}
+
+	line := ts.peek().line
+	if err := ts.expectSymbol("{"); err != nil {
+		return err
+	}
+	for !ts.acceptSymbol("}") {
+		if ts.atEnd() {
+			return &Error{Line: line, Message: "unterminated Ref block"}
+		}
+		if err := p.refLine(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *dbmlParser) refLine() error {
+	ts := p.ts
+	line := ts.peek().line
+
+	from, err := p.endpoint()
+	if err != nil {
+		return err
+	}
+	op, err := p.relationOperator()
+	if err != nil {
+		return err
+	}
+	to, err := p.endpoint()
+	if err != nil {
+		return err
+	}
+	if ts.isSymbol("[") {
+		// delete: cascade 같은 참조 옵션
+		if _, err := p.settings(); err != nil {
+			return err
+		}
+	}
+
+	p.refs = append(p.refs, dbmlRef{from: from, to: to, relType: op, line: line})
+	return nil
+}
+
+func (p *dbmlParser) relationOperator() (domain.RelationType, error) {
+	ts := p.ts
+	tok := ts.peek()
+	if tok.kind != tokSymbol {
+		return "", ts.errorf("expected relation operator, found %s", describe(tok))
+	}
+
+	op := tok.text
+	if next := ts.peekAt(1); op == "<" && next.kind == tokSymbol && next.text == ">" && next.pos == tok.end {
+		op = "<>"
+		ts.next()
+	}
+	relType, ok := dbmlRelationTypes[op]
+	if !ok {
+		return "", ts.errorf("expected relation operator, found %s", describe(tok))
+	}
+	ts.next()
+	return relType, nil
+}
+
+// table.column, schema.table.column, table.(a, b)
+func (p *dbmlParser) endpoint() (dbmlEndpoint, error) {
+	ts := p.ts
+
+	var parts []string
+	for {
+		if ts.isSymbol("(") {
+			columns, err := ts.identList()
+			if err != nil {
+				return dbmlEndpoint{}, err
+			}
+			if len(parts) == 0 {
+				return dbmlEndpoint{}, ts.errorf("expected table name before column list")
+			}
+			return dbmlEndpoint{table: strings.Join(parts, "."), columns: columns}, nil
+		}
+
+		part, err := ts.ident()
+		if err != nil {
+			return dbmlEndpoint{}, err
+		}
+		parts = append(parts, part)
+		if !ts.acceptSymbol(".") {
+			break
+		}
+	}
+
+	if len(parts) < 2 {
+		return dbmlEndpoint{}, ts.errorf("expected table.column, found %s", parts[0])
+	}
+	return dbmlEndpoint{table: strings.Join(parts[:len(parts)-1], "."), columns: parts[len(parts)-1:]}, nil
+}
+
+func (p *dbmlParser) resolveTable(name string, line int) (*domain.Table, error) {
+	if alias, ok := p.aliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	idx := p.schema.index(name)
+	if idx < 0 {
+		return nil, &Error{Line: line, Message: fmt.Sprintf("table %s does not exist", name)}
+	}
+	return &p.schema.tables[idx], nil
+}
+
+func (p *dbmlParser) addRef(ref dbmlRef) error {
+	from, err := p.resolveTable(ref.from.table, ref.line)
+	if err != nil {
+		return err
+	}
+	to, err := p.resolveTable(ref.to.table, ref.line)
+	if err != nil {
+		return err
+	}
+	if len(ref.from.columns) != len(ref.to.columns) {
+		return &Error{Line: ref.line, Message: "relation column counts do not match"}
+	}
+
+	var relations []domain.Relation
+	if from.Relations != nil {
+		relations = *from.Relations
+	}
+	for i := range ref.from.columns {
+		relations = append(relations, domain.Relation{
+			From: from.Name + "." + ref.from.columns[i],
+			To:   to.Name + "." + ref.to.columns[i],
+			Type: ref.relType,
+		})
+	}
+	from.Relations = &relations
+	return nil
+}
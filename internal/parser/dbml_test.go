@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"testing"
+)
+
+func TestParse_DBML(t *testing.T) {
+	src := `
+Project shop {
+  database_type: 'PostgreSQL'
+  Note: 'online shop'
+}
+
+// 회원
+Table users as U [headercolor: #3498DB] {
+  id integer [pk, increment]
+  email varchar(255) [not null, unique, note: 'login email']
+  "full name" "character varying"
+  tags text[]
+  created_at timestamp [default: ` + "`now()`" + `]
+
+  Note: 'registered users'
+}
+
+Table app.orders {
+  id bigint
+  user_id integer [not null, ref: > U.id]
+  note text [note: '''
+    free text
+  ''']
+
+  indexes {
+    (id) [pk]
+    user_id [name: 'idx_user']
+  }
+}
+
+Table order_items {
+  order_id bigint [pk]
+  line int [pk]
+}
+
+Enum status {
+  active
+  inactive [note: 'soft deleted']
+}
+
+Ref: order_items.order_id > app.orders.id [delete: cascade]
+Ref one_to_one {
+  users.id - app.orders.id
+  users.id <> order_items.line
+}
+`
+
+	tables, err := Parse(DBML, src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != 3 {
+		t.Fatalf("tables length = %v, want 3", len(tables))
+	}
+
+	users := tables[0]
+	assertColumns(t, *users.Columns, []domain.Column{
+		{Name: "id", Type: "integer", PK: true, Nullable: false},
+		{Name: "email", Type: "varchar(255)", Nullable: false},
+		{Name: "full name", Type: "character varying", Nullable: true},
+		{Name: "tags", Type: "text[]", Nullable: true},
+		{Name: "created_at", Type: "timestamp", Nullable: true},
+	})
+	if desc := (*users.Columns)[1].Description; desc == nil || *desc != "login email" {
+		t.Errorf("email Description = %v, want login email", desc)
+	}
+	assertRelations(t, users.Relations, []domain.Relation{
+		{From: "users.id", To: "app.orders.id", Type: domain.OneToOne},
+		{From: "users.id", To: "order_items.line", Type: domain.ManyToMany},
+	})
+
+	orders := tables[1]
+	assertColumns(t, *orders.Columns, []domain.Column{
+		{Name: "id", Type: "bigint", PK: true, Nullable: false},
+		{Name: "user_id", Type: "integer", Nullable: false},
+		{Name: "note", Type: "text", Nullable: true},
+	})
+	assertRelations(t, orders.Relations, []domain.Relation{
+		{From: "app.orders.user_id", To: "users.id", Type: domain.ManyToOne},
+	})
+
+	items := tables[2]
+	assertRelations(t, items.Relations, []domain.Relation{
+		{From: "order_items.order_id", To: "app.orders.id", Type: domain.ManyToOne},
+	})
+}
+
+func TestParse_DBMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "존재하지 않는 테이블 참조", src: "Table a { id int }\nRef: a.id > b.id"},
+		{name: "닫히지 않은 테이블", src: "Table a {\n id int\n"},
+		{name: "잘못된 관계 연산자", src: "Table a { id int }\nRef: a.id = a.id"},
+		{name: "알 수 없는 최상위 구문", src: "View v { }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(DBML, tt.src); err == nil {
+				t.Errorf("Parse() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestParse_DBMLEscapes(t *testing.T) {
+	src := `Table "say \"hi\"" {
+  id int [note: 'it\'s \"quoted\"', default: 'a\'b']
+  body text [note: '''keeps \''' inside''']
+}`
+
+	tables, err := Parse(DBML, src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if tables[0].Name != `say "hi"` {
+		t.Errorf("Name = %q, want %q", tables[0].Name, `say "hi"`)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "id", want: `it's "quoted"`},
+		{name: "body", want: `keeps ''' inside`},
+	}
+	for i, tt := range tests {
+		desc := (*tables[0].Columns)[i].Description
+		if desc == nil || *desc != tt.want {
+			t.Errorf("%s Description = %v, want %q", tt.name, desc, tt.want)
+		}
+	}
+}
@@ -0,0 +1,701 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"strings"
+)
+
+type dialectSpec struct {
+	lex          lexOptions
+	typeSuffixes [][]string
+}
+
+// 컬럼 정의에서 DEFAULT 표현식이 끝나는 지점을 판단하는 키워드
+var columnAttributeKeywords = []string{
+	"NOT", "NULL", "DEFAULT", "PRIMARY", "KEY", "UNIQUE", "REFERENCES", "CHECK",
+	"CONSTRAINT", "COLLATE", "CHARACTER", "CHARSET", "COMMENT", "AUTO_INCREMENT",
+	"GENERATED", "ON", "AS", "VISIBLE", "INVISIBLE", "STORAGE", "COLUMN_FORMAT",
+}
+
+type schema struct {
+	tables []domain.Table
+
+	// DROP CONSTRAINT 처리를 위해 이름이 붙은 PK/FK 제약을 기억한다
+	constraints map[string]namedConstraint
+}
+
+type namedConstraint struct {
+	table   string
+	kind    constraintKind
+	columns []string
+}
+
+func newSchema(tables []domain.Table) *schema {
+	s := &schema{
+		tables:      make([]domain.Table, len(tables)),
+		constraints: make(map[string]namedConstraint),
+	}
+	for i, t := range tables {
+		s.tables[i] = cloneTable(t)
+	}
+	return s
+}
+
+func cloneTable(t domain.Table) domain.Table {
+	if t.Columns != nil {
+		columns := append([]domain.Column(nil), *t.Columns...)
+		t.Columns = &columns
+	}
+	if t.Relations != nil {
+		relations := append([]domain.Relation(nil), *t.Relations...)
+		t.Relations = &relations
+	}
+	if t.Layout != nil {
+		layout := *t.Layout
+		t.Layout = &layout
+	}
+	return t
+}
+
+func (s *schema) index(name string) int {
+	for i, t := range s.tables {
+		if strings.EqualFold(t.Name, name) {
+			return i
+		}
+	}
+
+	// 한쪽에만 스키마가 붙은 경우(users / public.users)는 테이블 이름만 비교한다
+	for i, t := range s.tables {
+		if strings.Contains(t.Name, ".") == strings.Contains(name, ".") {
+			continue
+		}
+		if strings.EqualFold(unqualified(t.Name), unqualified(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func (s *schema) apply(spec *dialectSpec, src string) error {
+	tokens, err := tokenize(src, spec.lex)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range splitStatements(tokens) {
+		p := &ddlParser{
+			spec:   spec,
+			schema: s,
+			ts:     &tokenStream{src: src, tokens: stmt},
+		}
+		if err := p.statement(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitStatements(tokens []token) [][]token {
+	var stmts [][]token
+	start := 0
+	for i, tok := range tokens {
+		if tok.kind == tokEOF || (tok.kind == tokSymbol && tok.text == ";") {
+			if i > start {
+				stmt := append([]token(nil), tokens[start:i]...)
+				stmt = append(stmt, token{kind: tokEOF, pos: tok.pos, end: tok.pos, line: tok.line})
+				stmts = append(stmts, stmt)
+			}
+			start = i + 1
+		}
+	}
+	return stmts
+}
+
+type ddlParser struct {
+	spec   *dialectSpec
+	schema *schema
+	ts     *tokenStream
+}
+
+type foreignKey struct {
+	name       string
+	columns    []string
+	refTable   string
+	refColumns []string
+}
+
+type tableBuilder struct {
+	table          domain.Table
+	columns        []domain.Column
+	primaryKeyName string
+	primaryKey     []string
+	uniques        [][]string
+	foreignKeys    []foreignKey
+}
+
+func (p *ddlParser) statement() error {
+	ts := p.ts
+	if ts.acceptKeyword("CREATE") {
+		ts.acceptKeyword("OR", "REPLACE")
+		for ts.acceptKeyword("TEMPORARY") || ts.acceptKeyword("TEMP") || ts.acceptKeyword("UNLOGGED") ||
+			ts.acceptKeyword("GLOBAL") || ts.acceptKeyword("LOCAL") {
+		}
+		if ts.acceptKeyword("TABLE") {
+			return p.createTable()
+		}
+	}
+	if ts.acceptKeyword("COMMENT", "ON", "COLUMN") {
+		return p.commentOnColumn()
+	}
+	if ts.acceptKeyword("ALTER", "TABLE") {
+		return p.alterTable()
+	}
+	if ts.acceptKeyword("DROP") {
+		ts.acceptKeyword("TEMPORARY")
+		if ts.acceptKeyword("TABLE") {
+			return p.dropTable()
+		}
+		return nil
+	}
+	if ts.acceptKeyword("RENAME", "TABLE") {
+		return p.renameTables()
+	}
+
+	// 테이블 구조와 관계없는 구문(INSERT, SET, CREATE INDEX 등)은 무시한다
+	return nil
+}
+
+func (p *ddlParser) createTable() error {
+	ts := p.ts
+	start := ts.tokens[0]
+
+	ifNotExists := ts.acceptKeyword("IF", "NOT", "EXISTS")
+	name, err := ts.qualifiedName()
+	if err != nil {
+		return err
+	}
+
+	if idx := p.schema.index(name); idx >= 0 {
+		if ifNotExists {
+			return nil
+		}
+		return &Error{Line: start.line, Message: fmt.Sprintf("table %s already exists", name)}
+	}
+
+	if ts.acceptKeyword("LIKE") {
+		return p.createTableLike(name, start)
+	}
+	if !ts.isSymbol("(") {
+		// CREATE TABLE ... AS SELECT 는 컬럼 구조를 알 수 없다
+		return nil
+	}
+	ts.next()
+
+	b := &tableBuilder{table: domain.Table{Name: name}}
+	for {
+		if err := p.tableElement(b); err != nil {
+			return err
+		}
+		ts.skipUntil(",", ")")
+		if ts.acceptSymbol(",") {
+			continue
+		}
+		if err := ts.expectSymbol(")"); err != nil {
+			return err
+		}
+		break
+	}
+
+	query := ts.text(start, ts.tokens[len(ts.tokens)-2])
+	b.table.OriginalQuery = &query
+
+	p.schema.tables = append(p.schema.tables, p.schema.build(b))
+	return nil
+}
+
+func (p *ddlParser) createTableLike(name string, start token) error {
+	ts := p.ts
+	ts.acceptSymbol("(")
+	source, err := ts.qualifiedName()
+	if err != nil {
+		return err
+	}
+
+	idx := p.schema.index(source)
+	if idx < 0 {
+		return &Error{Line: start.line, Message: fmt.Sprintf("table %s does not exist", source)}
+	}
+
+	query := ts.text(start, ts.tokens[len(ts.tokens)-2])
+	table := domain.Table{Name: name, OriginalQuery: &query}
+	if cols := p.schema.tables[idx].Columns; cols != nil {
+		copied := append([]domain.Column(nil), *cols...)
+		table.Columns = &copied
+	}
+	p.schema.tables = append(p.schema.tables, table)
+	return nil
+}
+
+// COMMENT ON COLUMN [schema.]table.column IS 'text'
+func (p *ddlParser) commentOnColumn() error {
+	ts := p.ts
+	line := ts.peek().line
+
+	path, err := ts.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if err := ts.expectKeyword("IS"); err != nil {
+		return err
+	}
+
+	var desc *string
+	if tok := ts.next(); tok.kind == tokString {
+		desc = &tok.text
+	} else if !isKeyword(tok, "NULL") {
+		return &Error{Line: tok.line, Message: fmt.Sprintf("expected string or NULL, found %s", describe(tok))}
+	}
+
+	dot := strings.LastIndex(path, ".")
+	if dot < 0 {
+		return &Error{Line: line, Message: fmt.Sprintf("column name %s must be qualified with its table", path)}
+	}
+	tableName, colName := path[:dot], path[dot+1:]
+
+	idx := p.schema.index(tableName)
+	if idx < 0 {
+		return &Error{Line: line, Message: fmt.Sprintf("table %s does not exist", tableName)}
+	}
+	col := findColumn(&p.schema.tables[idx], colName)
+	if col == nil {
+		return &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", colName, tableName)}
+	}
+	col.Description = desc
+	return nil
+}
+
+func findColumn(t *domain.Table, name string) *domain.Column {
+	if t.Columns == nil {
+		return nil
+	}
+	for i := range *t.Columns {
+		if strings.EqualFold((*t.Columns)[i].Name, name) {
+			return &(*t.Columns)[i]
+		}
+	}
+	return nil
+}
+
+func (p *ddlParser) tableElement(b *tableBuilder) error {
+	ts := p.ts
+
+	if ts.isKeyword("CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "LIKE") {
+		c, err := p.tableConstraint()
+		if err != nil {
+			return err
+		}
+		switch c.kind {
+		case constraintPrimaryKey:
+			b.primaryKeyName = c.name
+			b.primaryKey = c.columns
+		case constraintUnique:
+			b.uniques = append(b.uniques, c.columns)
+		case constraintForeignKey:
+			b.foreignKeys = append(b.foreignKeys, *c.foreignKey)
+		}
+		return nil
+	}
+
+	col, fk, unique, err := p.columnDefinition()
+	if err != nil {
+		return err
+	}
+	if col.PK {
+		b.primaryKey = append(b.primaryKey, col.Name)
+	}
+	if unique {
+		b.uniques = append(b.uniques, []string{col.Name})
+	}
+	if fk != nil {
+		b.foreignKeys = append(b.foreignKeys, *fk)
+	}
+	b.columns = append(b.columns, col)
+	return nil
+}
+
+type constraintKind int
+
+const (
+	constraintOther constraintKind = iota
+	constraintPrimaryKey
+	constraintUnique
+	constraintForeignKey
+)
+
+type constraint struct {
+	kind       constraintKind
+	name       string
+	columns    []string
+	foreignKey *foreignKey
+}
+
+func (p *ddlParser) tableConstraint() (constraint, error) {
+	ts := p.ts
+
+	var c constraint
+	if ts.acceptKeyword("CONSTRAINT") {
+		if !ts.isKeyword("PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE") {
+			name, err := ts.ident()
+			if err != nil {
+				return c, err
+			}
+			c.name = name
+		}
+	}
+
+	switch {
+	case ts.acceptKeyword("PRIMARY"):
+		if err := ts.expectKeyword("KEY"); err != nil {
+			return c, err
+		}
+		c.kind = constraintPrimaryKey
+	case ts.acceptKeyword("UNIQUE"):
+		_ = ts.acceptKeyword("KEY") || ts.acceptKeyword("INDEX")
+		c.kind = constraintUnique
+	case ts.acceptKeyword("FOREIGN"):
+		if err := ts.expectKeyword("KEY"); err != nil {
+			return c, err
+		}
+		c.kind = constraintForeignKey
+	default:
+		// CHECK, INDEX, FULLTEXT 등 나머지 제약은 다이어그램에 표현하지 않는다
+		return c, nil
+	}
+
+	p.skipIndexName()
+	cols, err := ts.identList()
+	if err != nil {
+		return c, err
+	}
+	c.columns = cols
+
+	if c.kind == constraintForeignKey {
+		if c.foreignKey, err = p.references(); err != nil {
+			return c, err
+		}
+		c.foreignKey.name = c.name
+		c.foreignKey.columns = cols
+	}
+	return c, nil
+}
+
+// PRIMARY KEY [name] [USING BTREE] (...) 형태에서 인덱스 이름과 타입을 건너뛴다
+func (p *ddlParser) skipIndexName() {
+	ts := p.ts
+	for !ts.atEnd() && !ts.isSymbol("(") {
+		ts.next()
+	}
+}
+
+func (p *ddlParser) references() (*foreignKey, error) {
+	ts := p.ts
+	if err := ts.expectKeyword("REFERENCES"); err != nil {
+		return nil, err
+	}
+
+	refTable, err := ts.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+
+	fk := &foreignKey{refTable: refTable}
+	if ts.isSymbol("(") {
+		if fk.refColumns, err = ts.identList(); err != nil {
+			return nil, err
+		}
+	}
+
+	// MATCH FULL, ON DELETE CASCADE 등 참조 옵션
+	for {
+		switch {
+		case ts.acceptKeyword("MATCH"):
+			ts.next()
+		case ts.acceptKeyword("ON"):
+			ts.next()
+			_ = ts.acceptKeyword("SET") || ts.acceptKeyword("NO")
+			ts.next()
+		default:
+			return fk, nil
+		}
+	}
+}
+
+func (p *ddlParser) columnDefinition() (domain.Column, *foreignKey, bool, error) {
+	ts := p.ts
+
+	name, err := ts.ident()
+	if err != nil {
+		return domain.Column{}, nil, false, err
+	}
+	dtype, err := p.dataType()
+	if err != nil {
+		return domain.Column{}, nil, false, err
+	}
+
+	col := domain.Column{Name: name, Type: dtype, Nullable: true}
+	if isSerialType(dtype) {
+		col.Nullable = false
+	}
+
+	var fk *foreignKey
+	unique := false
+
+	// FIRST, AFTER 는 ALTER TABLE ADD/MODIFY 의 컬럼 위치 지정이다
+	for !ts.atEnd() && !ts.isSymbol(",") && !ts.isSymbol(")") && !ts.isKeyword("FIRST", "AFTER") {
+		switch {
+		case ts.acceptKeyword("NOT", "NULL"):
+			col.Nullable = false
+		case ts.acceptKeyword("NULL"):
+			col.Nullable = true
+		case ts.acceptKeyword("DEFAULT"):
+			p.skipExpression()
+		case ts.acceptKeyword("PRIMARY", "KEY"), ts.acceptKeyword("KEY"):
+			col.PK = true
+		case ts.acceptKeyword("UNIQUE"):
+			ts.acceptKeyword("KEY")
+			unique = true
+		case ts.isKeyword("REFERENCES"):
+			if fk, err = p.references(); err != nil {
+				return domain.Column{}, nil, false, err
+			}
+			fk.columns = []string{name}
+		case ts.acceptKeyword("CHECK"):
+			ts.skipGroup()
+		case ts.acceptKeyword("CONSTRAINT"):
+			if !ts.isKeyword("PRIMARY", "UNIQUE", "REFERENCES", "CHECK", "NOT", "NULL", "DEFAULT") {
+				ts.next()
+			}
+		case ts.acceptKeyword("COLLATE"), ts.acceptKeyword("CHARSET"), ts.acceptKeyword("CHARACTER", "SET"):
+			ts.next()
+		case ts.acceptKeyword("COMMENT"):
+			tok := ts.next()
+			if tok.kind != tokString {
+				return domain.Column{}, nil, false, &Error{Line: tok.line, Message: "expected string after COMMENT"}
+			}
+			desc := tok.text
+			col.Description = &desc
+		case ts.acceptKeyword("GENERATED"):
+			if p.generated() {
+				col.Nullable = false
+			}
+		case ts.acceptKeyword("AS"):
+			ts.skipGroup()
+		case ts.acceptKeyword("ON", "UPDATE"):
+			p.skipExpression()
+		case ts.isSymbol("("):
+			ts.skipGroup()
+		default:
+			ts.next()
+		}
+	}
+
+	if col.PK {
+		col.Nullable = false
+	}
+	return col, fk, unique, nil
+}
+
+// GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY 이면 true, 계산 컬럼(AS (expr))이면 false
+func (p *ddlParser) generated() bool {
+	ts := p.ts
+	_ = ts.acceptKeyword("ALWAYS") || ts.acceptKeyword("BY", "DEFAULT")
+	ts.acceptKeyword("AS")
+	if ts.acceptKeyword("IDENTITY") {
+		ts.skipGroup()
+		return true
+	}
+	ts.skipGroup()
+	return false
+}
+
+func (p *ddlParser) dataType() (string, error) {
+	ts := p.ts
+	start := ts.peek()
+	if _, err := ts.ident(); err != nil {
+		return "", ts.errorf("expected data type, found %s", describe(start))
+	}
+
+	for {
+		switch {
+		case ts.isSymbol("("):
+			ts.skipGroup()
+		case ts.isSymbol("["):
+			ts.next()
+			ts.skipUntil("]")
+			ts.acceptSymbol("]")
+		case p.acceptTypeSuffix():
+		default:
+			return ts.text(start, ts.last()), nil
+		}
+	}
+}
+
+func (p *ddlParser) acceptTypeSuffix() bool {
+	for _, words := range p.spec.typeSuffixes {
+		if p.ts.acceptKeyword(words...) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ddlParser) skipExpression() {
+	ts := p.ts
+	if ts.isSymbol("(") {
+		ts.skipGroup()
+	} else {
+		ts.next()
+	}
+
+	for !ts.atEnd() && !ts.isSymbol(",") && !ts.isSymbol(")") && !ts.isKeyword(columnAttributeKeywords...) {
+		if ts.isSymbol("(") {
+			ts.skipGroup()
+			continue
+		}
+		ts.next()
+	}
+}
+
+func isSerialType(dtype string) bool {
+	switch strings.ToLower(dtype) {
+	case "serial", "smallserial", "bigserial", "serial2", "serial4", "serial8":
+		return true
+	}
+	return false
+}
+
+func (s *schema) build(b *tableBuilder) domain.Table {
+	table := b.table
+
+	for i := range b.columns {
+		if containsFold(b.primaryKey, b.columns[i].Name) {
+			b.columns[i].PK = true
+			b.columns[i].Nullable = false
+		}
+	}
+	columns := b.columns
+	if columns == nil {
+		columns = []domain.Column{}
+	}
+	table.Columns = &columns
+
+	if b.primaryKeyName != "" {
+		s.nameConstraint(b.primaryKeyName, table.Name, constraintPrimaryKey, b.primaryKey)
+	}
+	for _, fk := range b.foreignKeys {
+		s.addForeignKey(&table, fk, b.uniques)
+	}
+	return table
+}
+
+func (s *schema) nameConstraint(name, table string, kind constraintKind, columns []string) {
+	s.constraints[strings.ToLower(name)] = namedConstraint{table: table, kind: kind, columns: columns}
+}
+
+func (s *schema) addForeignKey(table *domain.Table, fk foreignKey, uniques [][]string) {
+	if fk.name != "" {
+		s.nameConstraint(fk.name, table.Name, constraintForeignKey, fk.columns)
+	}
+
+	refColumns := fk.refColumns
+	if len(refColumns) == 0 {
+		refColumns = s.primaryKey(fk.refTable, table)
+	}
+
+	relType := domain.ManyToOne
+	if isUniqueKey(table, fk.columns, uniques) {
+		relType = domain.OneToOne
+	}
+
+	var relations []domain.Relation
+	if table.Relations != nil {
+		relations = *table.Relations
+	}
+	for i, col := range fk.columns {
+		to := fk.refTable
+		if i < len(refColumns) {
+			to += "." + refColumns[i]
+		}
+		relations = append(relations, domain.Relation{
+			From: table.Name + "." + col,
+			To:   to,
+			Type: relType,
+		})
+	}
+	table.Relations = &relations
+}
+
+// 참조 컬럼이 생략되면 대상 테이블의 PK를 참조한다
+func (s *schema) primaryKey(name string, self *domain.Table) []string {
+	target := self
+	if !strings.EqualFold(name, self.Name) {
+		idx := s.index(name)
+		if idx < 0 {
+			return nil
+		}
+		target = &s.tables[idx]
+	}
+	return primaryKeyColumns(target)
+}
+
+func primaryKeyColumns(t *domain.Table) []string {
+	if t.Columns == nil {
+		return nil
+	}
+
+	var pk []string
+	for _, c := range *t.Columns {
+		if c.PK {
+			pk = append(pk, c.Name)
+		}
+	}
+	return pk
+}
+
+func isUniqueKey(t *domain.Table, cols []string, uniques [][]string) bool {
+	if sameColumns(primaryKeyColumns(t), cols) {
+		return true
+	}
+	for _, u := range uniques {
+		if sameColumns(u, cols) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for _, c := range a {
+		if !containsFold(b, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
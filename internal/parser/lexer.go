@@ -0,0 +1,268 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	end  int
+	line int
+}
+
+type lexOptions struct {
+	doubleQuoteIdent bool
+	backslashEscape  bool
+	// identEscape 는 큰따옴표 식별자 안에서도 역슬래시 이스케이프를 쓴다 (DBML)
+	identEscape  bool
+	dashComment  bool
+	hashComment  bool
+	slashComment bool
+	dollarQuote  bool
+	tripleQuote  bool
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	opts lexOptions
+}
+
+func tokenize(src string, opts lexOptions) ([]token, error) {
+	lx := &lexer{src: src, line: 1, opts: opts}
+
+	var tokens []token
+	for {
+		tok, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (lx *lexer) next() (token, error) {
+	if err := lx.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+
+	if lx.pos >= len(lx.src) {
+		return token{kind: tokEOF, pos: lx.pos, end: lx.pos, line: lx.line}, nil
+	}
+
+	start, line := lx.pos, lx.line
+	c := lx.src[lx.pos]
+
+	switch {
+	case c == '\'' && lx.opts.tripleQuote && strings.HasPrefix(lx.src[lx.pos:], "'''"):
+		text, err := lx.tripleQuoted()
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokString, text: text, pos: start, end: lx.pos, line: line}, nil
+	case c == '\'':
+		text, err := lx.quoted('\'', lx.opts.backslashEscape)
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokString, text: text, pos: start, end: lx.pos, line: line}, nil
+	case c == '`':
+		text, err := lx.quoted('`', false)
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokQuotedIdent, text: text, pos: start, end: lx.pos, line: line}, nil
+	case c == '"':
+		text, err := lx.quoted('"', lx.opts.backslashEscape && (!lx.opts.doubleQuoteIdent || lx.opts.identEscape))
+		if err != nil {
+			return token{}, err
+		}
+		kind := tokString
+		if lx.opts.doubleQuoteIdent {
+			kind = tokQuotedIdent
+		}
+		return token{kind: kind, text: text, pos: start, end: lx.pos, line: line}, nil
+	case c == '$' && lx.opts.dollarQuote:
+		if text, ok, err := lx.dollarQuoted(); ok || err != nil {
+			if err != nil {
+				return token{}, err
+			}
+			return token{kind: tokString, text: text, pos: start, end: lx.pos, line: line}, nil
+		}
+	case c >= '0' && c <= '9':
+		for lx.pos < len(lx.src) && (isDigit(lx.src[lx.pos]) || lx.src[lx.pos] == '.') {
+			lx.pos++
+		}
+		return token{kind: tokNumber, text: lx.src[start:lx.pos], pos: start, end: lx.pos, line: line}, nil
+	}
+
+	if r, _ := utf8.DecodeRuneInString(lx.src[lx.pos:]); isIdentStart(r) {
+		for lx.pos < len(lx.src) {
+			r, size := utf8.DecodeRuneInString(lx.src[lx.pos:])
+			if !isIdentPart(r) {
+				break
+			}
+			lx.pos += size
+		}
+		return token{kind: tokIdent, text: lx.src[start:lx.pos], pos: start, end: lx.pos, line: line}, nil
+	}
+
+	_, size := utf8.DecodeRuneInString(lx.src[lx.pos:])
+	lx.pos += size
+	return token{kind: tokSymbol, text: lx.src[start:lx.pos], pos: start, end: lx.pos, line: line}, nil
+}
+
+func (lx *lexer) skipSpaceAndComments() error {
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == '\n':
+			lx.line++
+			lx.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			lx.pos++
+		case c == '-' && lx.opts.dashComment && strings.HasPrefix(lx.src[lx.pos:], "--"),
+			c == '/' && lx.opts.slashComment && strings.HasPrefix(lx.src[lx.pos:], "//"),
+			c == '#' && lx.opts.hashComment:
+			for lx.pos < len(lx.src) && lx.src[lx.pos] != '\n' {
+				lx.pos++
+			}
+		case c == '/' && strings.HasPrefix(lx.src[lx.pos:], "/*"):
+			line := lx.line
+			end := strings.Index(lx.src[lx.pos+2:], "*/")
+			if end < 0 {
+				return &Error{Line: line, Message: "unterminated comment"}
+			}
+			comment := lx.src[lx.pos : lx.pos+2+end+2]
+			lx.line += strings.Count(comment, "\n")
+			lx.pos += len(comment)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (lx *lexer) quoted(quote byte, backslashEscape bool) (string, error) {
+	line := lx.line
+	lx.pos++
+
+	var sb strings.Builder
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == quote:
+			if lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == quote {
+				sb.WriteByte(quote)
+				lx.pos += 2
+				continue
+			}
+			lx.pos++
+			return sb.String(), nil
+		case c == '\\' && backslashEscape && lx.pos+1 < len(lx.src):
+			sb.WriteByte(unescape(lx.src[lx.pos+1]))
+			lx.pos += 2
+			continue
+		case c == '\n':
+			lx.line++
+		}
+		sb.WriteByte(c)
+		lx.pos++
+	}
+	return "", &Error{Line: line, Message: "unterminated quoted string"}
+}
+
+// tripleQuoted 는 ”'...”' 를 읽는다. 역슬래시로 이스케이프한 따옴표에서는 끝나지 않는다
+func (lx *lexer) tripleQuoted() (string, error) {
+	line := lx.line
+	lx.pos += 3
+
+	var sb strings.Builder
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == '\\' && lx.pos+1 < len(lx.src):
+			sb.WriteByte(unescape(lx.src[lx.pos+1]))
+			lx.pos += 2
+			continue
+		case strings.HasPrefix(lx.src[lx.pos:], "'''"):
+			lx.pos += 3
+			return sb.String(), nil
+		case c == '\n':
+			lx.line++
+		}
+		sb.WriteByte(c)
+		lx.pos++
+	}
+	return "", &Error{Line: line, Message: "unterminated quoted string"}
+}
+
+func (lx *lexer) dollarQuoted() (string, bool, error) {
+	rest := lx.src[lx.pos+1:]
+	end := strings.IndexByte(rest, '$')
+	if end < 0 {
+		return "", false, nil
+	}
+	for _, r := range rest[:end] {
+		if !isIdentPart(r) {
+			return "", false, nil
+		}
+	}
+
+	tag := lx.src[lx.pos : lx.pos+end+2]
+	body := lx.src[lx.pos+len(tag):]
+	closing := strings.Index(body, tag)
+	if closing < 0 {
+		return "", true, &Error{Line: lx.line, Message: "unterminated dollar-quoted string"}
+	}
+
+	text := body[:closing]
+	lx.line += strings.Count(text, "\n")
+	lx.pos += len(tag) + closing + len(tag)
+	return text, true, nil
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	}
+	return c
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	mermaidRelationLine = regexp.MustCompile(`^("[^"]*"|[^\s"]+)\s+(\|o|\|\||\}o|\}\|)(--|\.\.)(o\||\|\||o\{|\|\{)\s+("[^"]*"|[^\s"]+)\s*(?::\s*(.*))?$`)
+	mermaidEntityLine   = regexp.MustCompile(`^("[^"]*"|[^\s"{\[]+)(?:\s*\[[^\]]*\])?\s*(\{\s*\}?)?$`)
+	mermaidAttrLine     = regexp.MustCompile(`^(\S+)\s+(\S+)(?:\s+((?:PK|FK|UK)(?:\s*,\s*(?:PK|FK|UK))*))?(?:\s+"([^"]*)")?$`)
+	mermaidFence        = regexp.MustCompile("(?s)```mermaid\\s*\\n(.*?)```")
+)
+
+type mermaidRelation struct {
+	from, to    string
+	left, right string
+	label       string
+}
+
+// parseMermaid 는 Mermaid erDiagram 을 테이블로 변환한다.
+// Markdown 문서가 들어오면 첫 번째 ```mermaid 블록을 읽는다.
+func parseMermaid(src string) ([]domain.Table, error) {
+	lineOffset := 0
+	if m := mermaidFence.FindStringSubmatchIndex(src); m != nil {
+		lineOffset = strings.Count(src[:m[2]], "\n")
+		src = src[m[2]:m[3]]
+	}
+
+	s := newSchema(nil)
+	var relations []mermaidRelation
+	current := -1
+	header := false
+
+	for i, raw := range strings.Split(src, "\n") {
+		line := strings.TrimSpace(raw)
+		lineNo := i + 1 + lineOffset
+
+		if idx := strings.Index(line, "%%"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		if !header {
+			if line != "erDiagram" {
+				return nil, &Error{Line: lineNo, Message: fmt.Sprintf("expected erDiagram, found %q", line)}
+			}
+			header = true
+			continue
+		}
+
+		if current >= 0 {
+			if line == "}" {
+				current = -1
+				continue
+			}
+			if err := addMermaidAttribute(&s.tables[current], line, lineNo); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if m := mermaidRelationLine.FindStringSubmatch(line); m != nil {
+			relations = append(relations, mermaidRelation{
+				from:  unquote(m[1]),
+				left:  m[2],
+				right: m[4],
+				to:    unquote(m[5]),
+				label: unquote(strings.TrimSpace(m[6])),
+			})
+			continue
+		}
+
+		if m := mermaidEntityLine.FindStringSubmatch(line); m != nil {
+			idx := s.mermaidEntity(unquote(m[1]))
+			if block := strings.ReplaceAll(m[2], " ", ""); block == "{" {
+				current = idx
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "direction ") || strings.HasPrefix(line, "title ") {
+			continue
+		}
+		return nil, &Error{Line: lineNo, Message: fmt.Sprintf("unexpected %q", line)}
+	}
+
+	if !header {
+		return nil, &Error{Line: 1, Message: "expected erDiagram"}
+	}
+	if current >= 0 {
+		return nil, &Error{Line: strings.Count(src, "\n") + 1 + lineOffset, Message: fmt.Sprintf("unterminated entity %s", s.tables[current].Name)}
+	}
+
+	for _, r := range relations {
+		s.addMermaidRelation(r)
+	}
+	return s.tables, nil
+}
+
+func (s *schema) mermaidEntity(name string) int {
+	if idx := s.index(name); idx >= 0 {
+		return idx
+	}
+	columns := []domain.Column{}
+	s.tables = append(s.tables, domain.Table{Name: name, Columns: &columns})
+	return len(s.tables) - 1
+}
+
+func addMermaidAttribute(t *domain.Table, line string, lineNo int) error {
+	m := mermaidAttrLine.FindStringSubmatch(line)
+	if m == nil {
+		return &Error{Line: lineNo, Message: fmt.Sprintf("invalid attribute %q", line)}
+	}
+
+	col := domain.Column{
+		Name:     m[2],
+		Type:     m[1],
+		PK:       strings.Contains(m[3], "PK"),
+		Nullable: true,
+	}
+	if col.PK {
+		col.Nullable = false
+	}
+	if m[4] != "" {
+		desc := m[4]
+		col.Description = &desc
+	}
+
+	columns := append(*t.Columns, col)
+	t.Columns = &columns
+	return nil
+}
+
+func (s *schema) addMermaidRelation(r mermaidRelation) {
+	fromIdx := s.mermaidEntity(r.from)
+	toIdx := s.mermaidEntity(r.to)
+	from, to := &s.tables[fromIdx], &s.tables[toIdx]
+
+	fromMany := strings.HasPrefix(r.left, "}")
+	toMany := strings.HasSuffix(r.right, "{")
+
+	relType := domain.OneToOne
+	switch {
+	case fromMany && toMany:
+		relType = domain.ManyToMany
+	case fromMany:
+		relType = domain.ManyToOne
+	case toMany:
+		relType = domain.OneToMany
+	}
+
+	// 라벨이 "from -> to" 이거나 From 테이블의 컬럼 이름이면 컬럼 단위 관계로 본다
+	fromColumn, toColumn := "", ""
+	if parts := strings.SplitN(r.label, "->", 2); len(parts) == 2 {
+		fromColumn, toColumn = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	} else if findColumn(from, r.label) != nil {
+		fromColumn = r.label
+		if pk := primaryKeyColumns(to); len(pk) == 1 {
+			toColumn = pk[0]
+		}
+	}
+
+	var relations []domain.Relation
+	if from.Relations != nil {
+		relations = *from.Relations
+	}
+	relations = append(relations, domain.Relation{
+		From: joinEndpoint(from.Name, fromColumn),
+		To:   joinEndpoint(to.Name, toColumn),
+		Type: relType,
+	})
+	from.Relations = &relations
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"testing"
+)
+
+func TestParse_Mermaid(t *testing.T) {
+	src := "# Schema\n" +
+		"\n" +
+		"```mermaid\n" +
+		"erDiagram\n" +
+		"    %% customers place orders\n" +
+		"    CUSTOMER ||--o{ ORDER : places\n" +
+		"    CUSTOMER {\n" +
+		"        string name\n" +
+		"        int id PK \"customer id\"\n" +
+		"    }\n" +
+		"    ORDER {\n" +
+		"        int id PK\n" +
+		"        int customer_id FK\n" +
+		"    }\n" +
+		"    ORDER }|..|| \"DELIVERY ADDRESS\" : \"address_id -> id\"\n" +
+		"```\n"
+
+	tables, err := Parse(Mermaid, src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != 3 {
+		t.Fatalf("tables length = %v, want 3", len(tables))
+	}
+
+	assertColumns(t, *tables[0].Columns, []domain.Column{
+		{Name: "name", Type: "string", Nullable: true},
+		{Name: "id", Type: "int", PK: true, Nullable: false},
+	})
+	if desc := (*tables[0].Columns)[1].Description; desc == nil || *desc != "customer id" {
+		t.Errorf("id Description = %v, want customer id", desc)
+	}
+
+	// 라벨이 컬럼 이름이 아니면 테이블 단위 관계가 된다
+	assertRelations(t, tables[0].Relations, []domain.Relation{
+		{From: "CUSTOMER", To: "ORDER", Type: domain.OneToMany},
+	})
+	assertRelations(t, tables[1].Relations, []domain.Relation{
+		{From: "ORDER.address_id", To: "DELIVERY ADDRESS.id", Type: domain.ManyToOne},
+	})
+}
+
+func TestParse_MermaidErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "erDiagram 헤더가 없다", src: "flowchart LR\n A --> B\n"},
+		{name: "닫히지 않은 엔티티", src: "erDiagram\n A {\n int id\n"},
+		{name: "잘못된 속성", src: "erDiagram\n A {\n int\n }\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(Mermaid, tt.src); err == nil {
+				t.Errorf("Parse() error = nil, want error")
+			}
+		})
+	}
+}
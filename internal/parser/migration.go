@@ -0,0 +1,683 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ALTER TABLE [IF EXISTS] [ONLY] name action [, action ...]
+func (p *ddlParser) alterTable() error {
+	ts := p.ts
+	line := ts.peek().line
+
+	ifExists := ts.acceptKeyword("IF", "EXISTS")
+	ts.acceptKeyword("ONLY")
+	name, err := ts.qualifiedName()
+	if err != nil {
+		return err
+	}
+
+	if p.schema.index(name) < 0 {
+		if ifExists {
+			return nil
+		}
+		return &Error{Line: line, Message: fmt.Sprintf("table %s does not exist", name)}
+	}
+
+	for {
+		if err := p.alterAction(&name); err != nil {
+			return err
+		}
+		ts.skipUntil(",")
+		if !ts.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+func (p *ddlParser) alterAction(name *string) error {
+	ts := p.ts
+
+	switch {
+	case ts.acceptKeyword("ADD"):
+		if ts.isKeyword("CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE", "KEY", "INDEX", "FULLTEXT", "SPATIAL") {
+			c, err := p.tableConstraint()
+			if err != nil {
+				return err
+			}
+			return p.schema.addConstraint(*name, c, ts.peek().line)
+		}
+		ts.acceptKeyword("COLUMN")
+		ifNotExists := ts.acceptKeyword("IF", "NOT", "EXISTS")
+		return p.addColumn(*name, ifNotExists)
+	case ts.acceptKeyword("DROP"):
+		return p.dropFromTable(*name)
+	case ts.acceptKeyword("MODIFY"):
+		ts.acceptKeyword("COLUMN")
+		return p.changeColumn(*name, "")
+	case ts.acceptKeyword("CHANGE"):
+		ts.acceptKeyword("COLUMN")
+		old, err := ts.ident()
+		if err != nil {
+			return err
+		}
+		return p.changeColumn(*name, old)
+	case ts.acceptKeyword("ALTER"):
+		ts.acceptKeyword("COLUMN")
+		return p.alterColumn(*name)
+	case ts.acceptKeyword("RENAME"):
+		switch {
+		case ts.acceptKeyword("COLUMN"):
+			return p.renameColumn(*name)
+		case !ts.isKeyword("TO", "AS") && isKeyword(ts.peekAt(1), "TO"):
+			// PostgreSQL 은 COLUMN 키워드를 생략할 수 있다
+			return p.renameColumn(*name)
+		case ts.isKeyword("INDEX", "KEY", "CONSTRAINT"):
+			return nil
+		case ts.acceptKeyword("TO"), ts.acceptKeyword("AS"):
+		}
+		newName, err := ts.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if err := p.schema.renameTable(*name, newName, ts.peek().line); err != nil {
+			return err
+		}
+		*name = newName
+		return nil
+	}
+
+	// OWNER TO, SET ..., ENGINE= 등 구조와 무관한 동작은 무시한다
+	return nil
+}
+
+func (p *ddlParser) addColumn(table string, ifNotExists bool) error {
+	ts := p.ts
+	line := ts.peek().line
+
+	col, fk, unique, err := p.columnDefinition()
+	if err != nil {
+		return err
+	}
+
+	t := &p.schema.tables[p.schema.index(table)]
+	if findColumn(t, col.Name) != nil {
+		if ifNotExists {
+			return nil
+		}
+		return &Error{Line: line, Message: fmt.Sprintf("column %s already exists in table %s", col.Name, table)}
+	}
+
+	columns := []domain.Column{}
+	if t.Columns != nil {
+		columns = *t.Columns
+	}
+	pos, err := p.columnPosition(t, len(columns))
+	if err != nil {
+		return err
+	}
+	columns = append(columns[:pos], append([]domain.Column{col}, columns[pos:]...)...)
+	t.Columns = &columns
+
+	if fk != nil {
+		var uniques [][]string
+		if unique {
+			uniques = [][]string{{col.Name}}
+		}
+		p.schema.addForeignKey(t, *fk, uniques)
+	}
+	return nil
+}
+
+// MySQL 의 FIRST / AFTER col 위치 지정. 지정이 없으면 def 를 반환한다
+func (p *ddlParser) columnPosition(t *domain.Table, def int) (int, error) {
+	ts := p.ts
+	switch {
+	case ts.acceptKeyword("FIRST"):
+		return 0, nil
+	case ts.acceptKeyword("AFTER"):
+		line := ts.peek().line
+		after, err := ts.ident()
+		if err != nil {
+			return 0, err
+		}
+		for i, c := range *t.Columns {
+			if strings.EqualFold(c.Name, after) {
+				return i + 1, nil
+			}
+		}
+		return 0, &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", after, t.Name)}
+	}
+	return def, nil
+}
+
+func (p *ddlParser) dropFromTable(table string) error {
+	ts := p.ts
+	line := ts.peek().line
+
+	switch {
+	case ts.acceptKeyword("PRIMARY", "KEY"):
+		p.schema.setPrimaryKey(table, nil)
+		return nil
+	case ts.isKeyword("CONSTRAINT", "FOREIGN"):
+		foreignKey := ts.acceptKeyword("FOREIGN", "KEY")
+		if !foreignKey {
+			ts.next()
+		}
+		ifExists := ts.acceptKeyword("IF", "EXISTS")
+		name, err := ts.ident()
+		if err != nil {
+			return err
+		}
+		return p.schema.dropConstraint(table, name, foreignKey, ifExists, line)
+	case ts.isKeyword("INDEX", "KEY", "CHECK", "DEFAULT"):
+		return nil
+	}
+
+	ts.acceptKeyword("COLUMN")
+	ifExists := ts.acceptKeyword("IF", "EXISTS")
+	col, err := ts.ident()
+	if err != nil {
+		return err
+	}
+	return p.schema.dropColumn(table, col, ifExists, line)
+}
+
+// MODIFY col def / CHANGE old new def. old 가 비어있으면 MODIFY 이다
+func (p *ddlParser) changeColumn(table, old string) error {
+	ts := p.ts
+	line := ts.peek().line
+
+	col, fk, unique, err := p.columnDefinition()
+	if err != nil {
+		return err
+	}
+	if old == "" {
+		old = col.Name
+	}
+
+	t := &p.schema.tables[p.schema.index(table)]
+	existing := findColumn(t, old)
+	if existing == nil {
+		return &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", old, table)}
+	}
+
+	pk := existing.PK
+	if !strings.EqualFold(old, col.Name) {
+		p.schema.updateColumnReferences(t.Name, old, col.Name)
+	}
+	*existing = col
+	if pk {
+		existing.PK = true
+		existing.Nullable = false
+	}
+
+	if ts.isKeyword("FIRST", "AFTER") {
+		columns := *t.Columns
+		from := columnIndex(t, col.Name)
+		moved := columns[from]
+		columns = append(columns[:from], columns[from+1:]...)
+		t.Columns = &columns
+
+		pos, err := p.columnPosition(t, from)
+		if err != nil {
+			return err
+		}
+		columns = append(columns[:pos], append([]domain.Column{moved}, columns[pos:]...)...)
+		t.Columns = &columns
+	}
+
+	if fk != nil {
+		var uniques [][]string
+		if unique {
+			uniques = [][]string{{col.Name}}
+		}
+		p.schema.addForeignKey(t, *fk, uniques)
+	}
+	return nil
+}
+
+// ALTER [COLUMN] col {SET NOT NULL | DROP NOT NULL | [SET DATA] TYPE t | SET DEFAULT ...}
+func (p *ddlParser) alterColumn(table string) error {
+	ts := p.ts
+	line := ts.peek().line
+
+	name, err := ts.ident()
+	if err != nil {
+		return err
+	}
+
+	col := findColumn(&p.schema.tables[p.schema.index(table)], name)
+	if col == nil {
+		return &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", name, table)}
+	}
+
+	switch {
+	case ts.acceptKeyword("SET", "NOT", "NULL"):
+		col.Nullable = false
+	case ts.acceptKeyword("DROP", "NOT", "NULL"):
+		col.Nullable = true
+	case ts.acceptKeyword("SET", "DATA", "TYPE"), ts.acceptKeyword("TYPE"):
+		dtype, err := p.dataType()
+		if err != nil {
+			return err
+		}
+		col.Type = dtype
+	}
+	return nil
+}
+
+// RENAME COLUMN old TO new
+func (p *ddlParser) renameColumn(table string) error {
+	ts := p.ts
+	line := ts.peek().line
+
+	old, err := ts.ident()
+	if err != nil {
+		return err
+	}
+	if err := ts.expectKeyword("TO"); err != nil {
+		return err
+	}
+	newName, err := ts.ident()
+	if err != nil {
+		return err
+	}
+
+	col := findColumn(&p.schema.tables[p.schema.index(table)], old)
+	if col == nil {
+		return &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", old, table)}
+	}
+	col.Name = newName
+	p.schema.updateColumnReferences(p.schema.tables[p.schema.index(table)].Name, old, newName)
+	return nil
+}
+
+// DROP [TEMPORARY] TABLE [IF EXISTS] a [, b ...] [CASCADE | RESTRICT]
+func (p *ddlParser) dropTable() error {
+	ts := p.ts
+	ifExists := ts.acceptKeyword("IF", "EXISTS")
+
+	for {
+		line := ts.peek().line
+		name, err := ts.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if err := p.schema.dropTable(name, ifExists, line); err != nil {
+			return err
+		}
+		if !ts.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+// RENAME TABLE a TO b [, c TO d ...]
+func (p *ddlParser) renameTables() error {
+	ts := p.ts
+
+	for {
+		line := ts.peek().line
+		old, err := ts.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if err := ts.expectKeyword("TO"); err != nil {
+			return err
+		}
+		newName, err := ts.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if err := p.schema.renameTable(old, newName, line); err != nil {
+			return err
+		}
+		if !ts.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+func (s *schema) addConstraint(table string, c constraint, line int) error {
+	t := &s.tables[s.index(table)]
+
+	switch c.kind {
+	case constraintPrimaryKey:
+		for _, name := range c.columns {
+			if findColumn(t, name) == nil {
+				return &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", name, table)}
+			}
+		}
+		s.setPrimaryKey(table, c.columns)
+		if c.name != "" {
+			s.nameConstraint(c.name, t.Name, constraintPrimaryKey, c.columns)
+		}
+	case constraintUnique:
+		// 단일 컬럼 UNIQUE 가 추가되면 해당 컬럼의 외래키는 1:1 관계가 된다
+		if len(c.columns) == 1 && t.Relations != nil {
+			from := t.Name + "." + c.columns[0]
+			for i := range *t.Relations {
+				if strings.EqualFold((*t.Relations)[i].From, from) {
+					(*t.Relations)[i].Type = domain.OneToOne
+				}
+			}
+		}
+	case constraintForeignKey:
+		s.addForeignKey(t, *c.foreignKey, [][]string{})
+	}
+	return nil
+}
+
+func (s *schema) setPrimaryKey(table string, columns []string) {
+	t := &s.tables[s.index(table)]
+	if t.Columns == nil {
+		return
+	}
+	for i := range *t.Columns {
+		c := &(*t.Columns)[i]
+		c.PK = containsFold(columns, c.Name)
+		if c.PK {
+			c.Nullable = false
+		}
+	}
+}
+
+// dropConstraint 는 같은 입력에서 이름 붙인 제약을 먼저 찾고, 없으면 기존 다이어그램의 외래키를
+// 기본 이름 규칙과 컬럼 이름으로 찾는다. 외래키를 찾지 못하면 관계가 남지 않도록 에러를 반환한다
+func (s *schema) dropConstraint(table, name string, foreignKey, ifExists bool, line int) error {
+	t := &s.tables[s.index(table)]
+
+	key := strings.ToLower(name)
+	if c, ok := s.constraints[key]; ok && strings.EqualFold(c.table, t.Name) {
+		delete(s.constraints, key)
+		switch c.kind {
+		case constraintPrimaryKey:
+			s.setPrimaryKey(table, nil)
+		case constraintForeignKey:
+			s.dropForeignKey(t.Name, c.columns)
+		}
+		return nil
+	}
+
+	if !foreignKey && strings.EqualFold(name, unqualified(t.Name)+"_pkey") {
+		s.setPrimaryKey(table, nil)
+		return nil
+	}
+	if !foreignKey && !looksLikeForeignKey(name) {
+		// UNIQUE, CHECK 등 다이어그램에 없는 제약일 수 있으므로 무시한다
+		return nil
+	}
+	if columns := findForeignKey(t, name); len(columns) > 0 {
+		s.dropForeignKey(t.Name, columns)
+		return nil
+	}
+	if ifExists {
+		return nil
+	}
+	return &Error{Line: line, Message: fmt.Sprintf("foreign key %s does not exist in table %s", name, table)}
+}
+
+func (s *schema) dropForeignKey(table string, columns []string) {
+	s.removeRelations(func(owner string, r domain.Relation) bool {
+		if !strings.EqualFold(owner, table) {
+			return false
+		}
+		col, ok := endpointColumn(r.From, table)
+		return ok && containsFold(columns, col)
+	})
+}
+
+// findForeignKey 는 이름으로 외래키 컬럼을 찾는다. 다음 순서로 시도한다.
+//   - PostgreSQL 기본 이름: table_col1_col2_fkey
+//   - MySQL 기본 이름: table_ibfk_N. 같은 테이블을 연달아 참조하는 관계를 하나의 외래키로 센다
+//   - 이름에 _ 로 구분된 조각으로 들어있는 외래키 컬럼 (fk_orders_user_id 등)
+func findForeignKey(t *domain.Table, name string) []string {
+	if t.Relations == nil {
+		return nil
+	}
+
+	var columns []string
+	var groups [][]string
+	lastRef := ""
+	for _, r := range *t.Relations {
+		col, ok := endpointColumn(r.From, t.Name)
+		if !ok || col == "" {
+			continue
+		}
+		ref := r.To
+		if dot := strings.LastIndex(ref, "."); dot >= 0 {
+			ref = ref[:dot]
+		}
+		if len(groups) == 0 || !strings.EqualFold(ref, lastRef) {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], col)
+		columns = append(columns, col)
+		lastRef = ref
+	}
+
+	lower := strings.ToLower(name)
+	prefix := strings.ToLower(unqualified(t.Name)) + "_"
+
+	if rest, ok := strings.CutPrefix(lower, prefix); ok {
+		if mid, ok := strings.CutSuffix(rest, "_fkey"); ok {
+			for i := range columns {
+				for j := i + 1; j <= len(columns); j++ {
+					if strings.EqualFold(strings.Join(columns[i:j], "_"), mid) {
+						return columns[i:j]
+					}
+				}
+			}
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(rest, "ibfk_")); err == nil && strings.HasPrefix(rest, "ibfk_") && n >= 1 && n <= len(groups) {
+			return groups[n-1]
+		}
+	}
+
+	var matched []string
+	for _, col := range columns {
+		if hasSegment(lower, strings.ToLower(col)) {
+			matched = append(matched, col)
+		}
+	}
+	// user 와 user_id 가 모두 일치하면 더 긴 user_id 만 남긴다
+	var found []string
+	for _, col := range matched {
+		shadowed := false
+		for _, other := range matched {
+			if len(other) > len(col) && hasSegment(strings.ToLower(other), strings.ToLower(col)) {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			found = append(found, col)
+		}
+	}
+	return found
+}
+
+// hasSegment 는 s 안에 part 가 _ 또는 문자열 끝으로 둘러싸여 있는지 확인한다
+func hasSegment(s, part string) bool {
+	for i := 0; ; {
+		idx := strings.Index(s[i:], part)
+		if idx < 0 {
+			return false
+		}
+		start, end := i+idx, i+idx+len(part)
+		if (start == 0 || s[start-1] == '_') && (end == len(s) || s[end] == '_') {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func looksLikeForeignKey(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, "_fkey") || strings.HasSuffix(lower, "_fk") ||
+		strings.HasPrefix(lower, "fk_") || strings.Contains(lower, "_ibfk_")
+}
+
+func (s *schema) dropColumn(table, name string, ifExists bool, line int) error {
+	t := &s.tables[s.index(table)]
+	idx := columnIndex(t, name)
+	if idx < 0 {
+		if ifExists {
+			return nil
+		}
+		return &Error{Line: line, Message: fmt.Sprintf("column %s does not exist in table %s", name, table)}
+	}
+
+	columns := *t.Columns
+	columns = append(columns[:idx], columns[idx+1:]...)
+	t.Columns = &columns
+
+	tableName := t.Name
+	s.removeRelations(func(owner string, r domain.Relation) bool {
+		return endpointIs(r.From, tableName, name) || endpointIs(r.To, tableName, name)
+	})
+	return nil
+}
+
+func (s *schema) dropTable(name string, ifExists bool, line int) error {
+	idx := s.index(name)
+	if idx < 0 {
+		if ifExists {
+			return nil
+		}
+		return &Error{Line: line, Message: fmt.Sprintf("table %s does not exist", name)}
+	}
+
+	dropped := s.tables[idx].Name
+	s.tables = append(s.tables[:idx], s.tables[idx+1:]...)
+	s.removeRelations(func(_ string, r domain.Relation) bool {
+		_, ok := endpointColumn(r.To, dropped)
+		return ok
+	})
+	for key, c := range s.constraints {
+		if strings.EqualFold(c.table, dropped) {
+			delete(s.constraints, key)
+		}
+	}
+	return nil
+}
+
+func (s *schema) renameTable(old, newName string, line int) error {
+	idx := s.index(old)
+	if idx < 0 {
+		return &Error{Line: line, Message: fmt.Sprintf("table %s does not exist", old)}
+	}
+	if other := s.index(newName); other >= 0 && other != idx {
+		return &Error{Line: line, Message: fmt.Sprintf("table %s already exists", newName)}
+	}
+
+	old = s.tables[idx].Name
+	s.tables[idx].Name = newName
+	s.eachRelation(func(r *domain.Relation) {
+		if col, ok := endpointColumn(r.From, old); ok {
+			r.From = joinEndpoint(newName, col)
+		}
+		if col, ok := endpointColumn(r.To, old); ok {
+			r.To = joinEndpoint(newName, col)
+		}
+	})
+	for key, c := range s.constraints {
+		if strings.EqualFold(c.table, old) {
+			c.table = newName
+			s.constraints[key] = c
+		}
+	}
+	return nil
+}
+
+func (s *schema) updateColumnReferences(table, old, newName string) {
+	s.eachRelation(func(r *domain.Relation) {
+		if endpointIs(r.From, table, old) {
+			r.From = joinEndpoint(table, newName)
+		}
+		if endpointIs(r.To, table, old) {
+			r.To = joinEndpoint(table, newName)
+		}
+	})
+	for key, c := range s.constraints {
+		if !strings.EqualFold(c.table, table) {
+			continue
+		}
+		for i, col := range c.columns {
+			if strings.EqualFold(col, old) {
+				c.columns[i] = newName
+			}
+		}
+		s.constraints[key] = c
+	}
+}
+
+func (s *schema) eachRelation(fn func(r *domain.Relation)) {
+	for i := range s.tables {
+		if s.tables[i].Relations == nil {
+			continue
+		}
+		for j := range *s.tables[i].Relations {
+			fn(&(*s.tables[i].Relations)[j])
+		}
+	}
+}
+
+func (s *schema) removeRelations(drop func(owner string, r domain.Relation) bool) {
+	for i := range s.tables {
+		t := &s.tables[i]
+		if t.Relations == nil {
+			continue
+		}
+
+		kept := make([]domain.Relation, 0, len(*t.Relations))
+		for _, r := range *t.Relations {
+			if !drop(t.Name, r) {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == 0 {
+			t.Relations = nil
+			continue
+		}
+		t.Relations = &kept
+	}
+}
+
+func columnIndex(t *domain.Table, name string) int {
+	if t.Columns == nil {
+		return -1
+	}
+	for i, c := range *t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// endpoint 가 table 자체이거나 table.column 이면 column 부분과 true 를 반환한다
+func endpointColumn(endpoint, table string) (string, bool) {
+	if strings.EqualFold(endpoint, table) {
+		return "", true
+	}
+	if len(endpoint) > len(table) && endpoint[len(table)] == '.' && strings.EqualFold(endpoint[:len(table)], table) {
+		return endpoint[len(table)+1:], true
+	}
+	return "", false
+}
+
+func endpointIs(endpoint, table, column string) bool {
+	col, ok := endpointColumn(endpoint, table)
+	return ok && strings.EqualFold(col, column)
+}
+
+func joinEndpoint(table, column string) string {
+	if column == "" {
+		return table
+	}
+	return table + "." + column
+}
@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"testing"
+)
+
+func TestApply_Migrations(t *testing.T) {
+	migrations := `
+-- V1__init.sql
+CREATE TABLE users (id BIGINT PRIMARY KEY, name VARCHAR(50), legacy INT);
+CREATE TABLE posts (id BIGINT PRIMARY KEY, writer BIGINT NOT NULL, body TEXT);
+
+-- V2__relations.sql
+ALTER TABLE posts ADD CONSTRAINT fk_posts_writer FOREIGN KEY (writer) REFERENCES users (id);
+
+-- V3__columns.sql
+ALTER TABLE users ADD COLUMN email VARCHAR(255) NOT NULL AFTER id, DROP COLUMN legacy;
+ALTER TABLE users RENAME COLUMN name TO nickname;
+ALTER TABLE posts RENAME COLUMN writer TO author_id;
+ALTER TABLE posts MODIFY body MEDIUMTEXT NOT NULL;
+
+-- V4__rename.sql
+RENAME TABLE users TO members;
+CREATE TABLE tmp (id INT);
+DROP TABLE IF EXISTS tmp, missing;
+`
+
+	tables, err := Parse(MySQL, migrations)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("tables length = %v, want 2", len(tables))
+	}
+
+	members := tables[0]
+	if members.Name != "members" {
+		t.Errorf("tables[0].Name = %v, want members", members.Name)
+	}
+	assertColumns(t, *members.Columns, []domain.Column{
+		{Name: "id", Type: "BIGINT", PK: true, Nullable: false},
+		{Name: "email", Type: "VARCHAR(255)", Nullable: false},
+		{Name: "nickname", Type: "VARCHAR(50)", Nullable: true},
+	})
+
+	posts := tables[1]
+	assertColumns(t, *posts.Columns, []domain.Column{
+		{Name: "id", Type: "BIGINT", PK: true, Nullable: false},
+		{Name: "author_id", Type: "BIGINT", Nullable: false},
+		{Name: "body", Type: "MEDIUMTEXT", Nullable: false},
+	})
+	assertRelations(t, posts.Relations, []domain.Relation{
+		{From: "posts.author_id", To: "members.id", Type: domain.ManyToOne},
+	})
+}
+
+func TestApply_ExistingTables(t *testing.T) {
+	columns := []domain.Column{
+		{Name: "id", Type: "integer", PK: true},
+		{Name: "user_id", Type: "integer", Nullable: true},
+	}
+	relations := []domain.Relation{{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}}
+	userColumns := []domain.Column{{Name: "id", Type: "integer", PK: true}}
+	existing := []domain.Table{
+		{Name: "users", Columns: &userColumns},
+		{Name: "orders", Columns: &columns, Relations: &relations},
+	}
+
+	tests := []struct {
+		name      string
+		src       string
+		check     func(t *testing.T, tables []domain.Table)
+		wantError bool
+	}{
+		{
+			name: "ALTER COLUMN 으로 타입과 NULL 허용 여부를 바꾼다",
+			src: `ALTER TABLE orders ALTER COLUMN user_id SET NOT NULL;
+				ALTER TABLE orders ALTER COLUMN user_id TYPE bigint USING user_id::bigint;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				assertColumns(t, *tables[1].Columns, []domain.Column{
+					{Name: "id", Type: "integer", PK: true},
+					{Name: "user_id", Type: "bigint", Nullable: false},
+				})
+			},
+		},
+		{
+			name: "참조되는 컬럼을 삭제하면 관계도 삭제된다",
+			src:  `ALTER TABLE orders DROP COLUMN user_id;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				if tables[1].Relations != nil {
+					t.Errorf("Relations = %v, want nil", *tables[1].Relations)
+				}
+			},
+		},
+		{
+			name: "참조되는 테이블을 삭제하면 관계도 삭제된다",
+			src:  `DROP TABLE users CASCADE;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				if len(tables) != 1 || tables[0].Relations != nil {
+					t.Errorf("tables = %+v, want only orders without relations", tables)
+				}
+			},
+		},
+		{
+			name: "테이블 이름을 바꾸면 관계의 참조도 바뀐다",
+			src:  `ALTER TABLE users RENAME TO accounts;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				assertRelations(t, tables[1].Relations, []domain.Relation{
+					{From: "orders.user_id", To: "accounts.id", Type: domain.ManyToOne},
+				})
+			},
+		},
+		{
+			name: "PostgreSQL 기본 이름으로 외래키를 삭제한다",
+			src:  `ALTER TABLE orders DROP CONSTRAINT orders_user_id_fkey;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				if tables[1].Relations != nil {
+					t.Errorf("Relations = %v, want nil", *tables[1].Relations)
+				}
+			},
+		},
+		{
+			name: "MySQL 기본 이름으로 외래키를 삭제한다",
+			src:  `ALTER TABLE orders DROP FOREIGN KEY orders_ibfk_1;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				if tables[1].Relations != nil {
+					t.Errorf("Relations = %v, want nil", *tables[1].Relations)
+				}
+			},
+		},
+		{
+			name: "이름에 든 컬럼으로 외래키를 찾는다",
+			src:  `ALTER TABLE orders DROP FOREIGN KEY fk_orders_user_id;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				if tables[1].Relations != nil {
+					t.Errorf("Relations = %v, want nil", *tables[1].Relations)
+				}
+			},
+		},
+		{
+			name: "다이어그램에 없는 CHECK 제약 삭제는 무시한다",
+			src:  `ALTER TABLE orders DROP CONSTRAINT orders_user_id_check;`,
+			check: func(t *testing.T, tables []domain.Table) {
+				if tables[1].Relations == nil || len(*tables[1].Relations) != 1 {
+					t.Errorf("Relations = %v, want the user_id relation kept", tables[1].Relations)
+				}
+			},
+		},
+		{
+			name:      "찾을 수 없는 외래키를 삭제하면 에러",
+			src:       `ALTER TABLE orders DROP FOREIGN KEY fk_missing;`,
+			wantError: true,
+		},
+		{
+			name:      "존재하지 않는 테이블을 변경하면 에러",
+			src:       `ALTER TABLE missing ADD COLUMN a int;`,
+			wantError: true,
+		},
+		{
+			name:      "존재하지 않는 컬럼을 삭제하면 에러",
+			src:       `ALTER TABLE orders DROP COLUMN missing;`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := Apply(Postgres, existing, tt.src)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("Apply() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			tt.check(t, tables)
+
+			if (*existing[1].Columns)[1].Name != "user_id" || len(*existing[1].Relations) != 1 {
+				t.Errorf("Apply() must not modify the input tables")
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package parser
+
+var mysqlSpec = dialectSpec{
+	lex: lexOptions{
+		backslashEscape: true,
+		dashComment:     true,
+		hashComment:     true,
+	},
+	typeSuffixes: [][]string{
+		{"UNSIGNED"},
+		{"SIGNED"},
+		{"ZEROFILL"},
+		{"PRECISION"},
+		{"VARYING"},
+	},
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"errors"
+	"testing"
+)
+
+func TestParse_MySQL(t *testing.T) {
+	src := "" +
+		"-- users table\n" +
+		"CREATE TABLE IF NOT EXISTS `users` (\n" +
+		"  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` VARCHAR(255) NOT NULL COMMENT 'login email',\n" +
+		"  `nickname` varchar(50) DEFAULT NULL,\n" +
+		"  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_email` (`email`(100))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+		"\n" +
+		"CREATE TABLE orders (\n" +
+		"  id BIGINT PRIMARY KEY,\n" +
+		"  user_id BIGINT UNSIGNED NOT NULL,\n" +
+		"  amount DECIMAL(10, 2) NOT NULL DEFAULT '0.00' COMMENT 'it''s money',\n" +
+		"  KEY idx_user (user_id),\n" +
+		"  CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE NO ACTION\n" +
+		");\n" +
+		"INSERT INTO users VALUES (1, 'a@b.c', NULL, NOW());\n"
+
+	tables, err := Parse(MySQL, src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("tables length = %v, want 2", len(tables))
+	}
+
+	users := tables[0]
+	if users.Name != "users" {
+		t.Errorf("tables[0].Name = %v, want users", users.Name)
+	}
+	if users.OriginalQuery == nil {
+		t.Errorf("OriginalQuery should be set")
+	}
+
+	wantUsers := []domain.Column{
+		{Name: "id", Type: "BIGINT UNSIGNED", PK: true, Nullable: false},
+		{Name: "email", Type: "VARCHAR(255)", Nullable: false},
+		{Name: "nickname", Type: "varchar(50)", Nullable: true},
+		{Name: "created_at", Type: "DATETIME", Nullable: false},
+	}
+	assertColumns(t, *users.Columns, wantUsers)
+
+	if desc := (*users.Columns)[1].Description; desc == nil || *desc != "login email" {
+		t.Errorf("email Description = %v, want login email", desc)
+	}
+	if users.Relations != nil {
+		t.Errorf("users Relations = %v, want nil", *users.Relations)
+	}
+
+	orders := tables[1]
+	wantOrders := []domain.Column{
+		{Name: "id", Type: "BIGINT", PK: true, Nullable: false},
+		{Name: "user_id", Type: "BIGINT UNSIGNED", Nullable: false},
+		{Name: "amount", Type: "DECIMAL(10, 2)", Nullable: false},
+	}
+	assertColumns(t, *orders.Columns, wantOrders)
+
+	if desc := (*orders.Columns)[2].Description; desc == nil || *desc != "it's money" {
+		t.Errorf("amount Description = %v, want it's money", desc)
+	}
+
+	wantRelations := []domain.Relation{
+		{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne},
+	}
+	assertRelations(t, orders.Relations, wantRelations)
+}
+
+func TestParse_MySQLRelationType(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []domain.Relation
+	}{
+		{
+			name: "UNIQUE 외래키는 1:1 관계가 된다",
+			src: `CREATE TABLE users (id INT PRIMARY KEY);
+				CREATE TABLE profiles (
+					id INT PRIMARY KEY,
+					user_id INT NOT NULL UNIQUE,
+					FOREIGN KEY (user_id) REFERENCES users(id)
+				);`,
+			want: []domain.Relation{{From: "profiles.user_id", To: "users.id", Type: domain.OneToOne}},
+		},
+		{
+			name: "참조 컬럼이 생략되면 대상 테이블의 PK를 참조한다",
+			src: `CREATE TABLE users (id INT PRIMARY KEY);
+				CREATE TABLE posts (id INT PRIMARY KEY, author INT REFERENCES users);`,
+			want: []domain.Relation{{From: "posts.author", To: "users.id", Type: domain.ManyToOne}},
+		},
+		{
+			name: "복합 외래키는 컬럼마다 관계를 만든다",
+			src: `CREATE TABLE a (x INT, y INT, PRIMARY KEY (x, y));
+				CREATE TABLE b (ax INT, ay INT, CONSTRAINT fk FOREIGN KEY (ax, ay) REFERENCES a (x, y));`,
+			want: []domain.Relation{
+				{From: "b.ax", To: "a.x", Type: domain.ManyToOne},
+				{From: "b.ay", To: "a.y", Type: domain.ManyToOne},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := Parse(MySQL, tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			assertRelations(t, tables[len(tables)-1].Relations, tt.want)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		src     string
+	}{
+		{
+			name:    "지원하지 않는 dialect",
+			dialect: "oracle",
+			src:     "CREATE TABLE a (id INT);",
+		},
+		{
+			name:    "닫히지 않은 문자열",
+			dialect: MySQL,
+			src:     "CREATE TABLE a (id INT COMMENT 'oops);",
+		},
+		{
+			name:    "닫히지 않은 괄호",
+			dialect: MySQL,
+			src:     "CREATE TABLE a (id INT",
+		},
+		{
+			name:    "중복 테이블",
+			dialect: MySQL,
+			src:     "CREATE TABLE a (id INT); CREATE TABLE a (id INT);",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.dialect, tt.src)
+			if err == nil {
+				t.Fatalf("Parse() error = nil, want error")
+			}
+
+			var perr *Error
+			if !errors.As(err, &perr) && !errors.Is(err, ErrUnsupportedDialect) {
+				t.Errorf("Parse() error = %v, want *Error or ErrUnsupportedDialect", err)
+			}
+		})
+	}
+}
+
+func assertColumns(t *testing.T, got, want []domain.Column) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("columns length = %v, want %v", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Type != want[i].Type ||
+			got[i].PK != want[i].PK || got[i].Nullable != want[i].Nullable {
+			t.Errorf("columns[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func assertRelations(t *testing.T, got *[]domain.Relation, want []domain.Relation) {
+	t.Helper()
+
+	if got == nil {
+		t.Fatalf("Relations = nil, want %v", want)
+	}
+	if len(*got) != len(want) {
+		t.Fatalf("Relations = %v, want %v", *got, want)
+	}
+	for i := range want {
+		if (*got)[i] != want[i] {
+			t.Errorf("Relations[%d] = %+v, want %+v", i, (*got)[i], want[i])
+		}
+	}
+}
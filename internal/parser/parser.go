@@ -1 +1,253 @@
 package parser
+
+import (
+	"diagram-server/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	Mermaid  Dialect = "mermaid"
+	DBML     Dialect = "dbml"
+)
+
+var ErrUnsupportedDialect = errors.New("unsupported dialect")
+
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+func Parse(dialect Dialect, src string) ([]domain.Table, error) {
+	switch Dialect(strings.ToLower(string(dialect))) {
+	case Mermaid:
+		return parseMermaid(src)
+	case DBML:
+		return parseDBML(src)
+	}
+	return Apply(dialect, nil, src)
+}
+
+// Apply 는 CREATE/ALTER/DROP 구문을 순서대로 tables 에 반영한 결과를 반환한다.
+// 입력 tables 는 변경하지 않는다.
+func Apply(dialect Dialect, tables []domain.Table, src string) ([]domain.Table, error) {
+	spec, err := specFor(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSchema(tables)
+	if err := s.apply(spec, src); err != nil {
+		return nil, err
+	}
+	return s.tables, nil
+}
+
+func specFor(dialect Dialect) (*dialectSpec, error) {
+	switch Dialect(strings.ToLower(string(dialect))) {
+	case MySQL:
+		return &mysqlSpec, nil
+	case Postgres, "postgresql":
+		return &postgresSpec, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedDialect, dialect)
+}
+
+type tokenStream struct {
+	src    string
+	tokens []token
+	i      int
+}
+
+func (ts *tokenStream) peek() token {
+	return ts.tokens[ts.i]
+}
+
+func (ts *tokenStream) peekAt(offset int) token {
+	if ts.i+offset >= len(ts.tokens) {
+		return ts.tokens[len(ts.tokens)-1]
+	}
+	return ts.tokens[ts.i+offset]
+}
+
+func (ts *tokenStream) next() token {
+	tok := ts.tokens[ts.i]
+	if tok.kind != tokEOF {
+		ts.i++
+	}
+	return tok
+}
+
+func (ts *tokenStream) last() token {
+	if ts.i == 0 {
+		return ts.tokens[0]
+	}
+	return ts.tokens[ts.i-1]
+}
+
+func (ts *tokenStream) atEnd() bool {
+	return ts.peek().kind == tokEOF
+}
+
+func (ts *tokenStream) isKeyword(keywords ...string) bool {
+	return isKeyword(ts.peek(), keywords...)
+}
+
+func (ts *tokenStream) acceptKeyword(keywords ...string) bool {
+	for i, kw := range keywords {
+		if !isKeyword(ts.peekAt(i), kw) {
+			return false
+		}
+	}
+	ts.i += len(keywords)
+	return true
+}
+
+func (ts *tokenStream) expectKeyword(kw string) error {
+	if !ts.acceptKeyword(kw) {
+		return ts.errorf("expected %s, found %s", strings.ToUpper(kw), describe(ts.peek()))
+	}
+	return nil
+}
+
+func (ts *tokenStream) isSymbol(sym string) bool {
+	tok := ts.peek()
+	return tok.kind == tokSymbol && tok.text == sym
+}
+
+func (ts *tokenStream) acceptSymbol(sym string) bool {
+	if ts.isSymbol(sym) {
+		ts.i++
+		return true
+	}
+	return false
+}
+
+func (ts *tokenStream) expectSymbol(sym string) error {
+	if !ts.acceptSymbol(sym) {
+		return ts.errorf("expected %q, found %s", sym, describe(ts.peek()))
+	}
+	return nil
+}
+
+func (ts *tokenStream) ident() (string, error) {
+	tok := ts.peek()
+	if tok.kind != tokIdent && tok.kind != tokQuotedIdent {
+		return "", ts.errorf("expected identifier, found %s", describe(tok))
+	}
+	ts.i++
+	return tok.text, nil
+}
+
+// schema.table 처럼 점으로 구분된 이름을 읽는다
+func (ts *tokenStream) qualifiedName() (string, error) {
+	name, err := ts.ident()
+	if err != nil {
+		return "", err
+	}
+	for ts.isSymbol(".") {
+		ts.i++
+		part, err := ts.ident()
+		if err != nil {
+			return "", err
+		}
+		name += "." + part
+	}
+	return name, nil
+}
+
+func (ts *tokenStream) identList() ([]string, error) {
+	if err := ts.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for {
+		name, err := ts.ident()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+
+		// 인덱스 길이(prefix)나 ASC/DESC 같은 key part 옵션은 건너뛴다
+		ts.skipUntil(",", ")")
+
+		if ts.acceptSymbol(")") {
+			return names, nil
+		}
+		if err := ts.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// 괄호 깊이 0에서 stop 심볼 중 하나를 만날 때까지 토큰을 건너뛴다
+func (ts *tokenStream) skipUntil(stops ...string) {
+	depth := 0
+	for !ts.atEnd() {
+		tok := ts.peek()
+		if tok.kind == tokSymbol {
+			if depth == 0 {
+				for _, s := range stops {
+					if tok.text == s {
+						return
+					}
+				}
+			}
+			switch tok.text {
+			case "(":
+				depth++
+			case ")":
+				if depth == 0 {
+					return
+				}
+				depth--
+			}
+		}
+		ts.i++
+	}
+}
+
+func (ts *tokenStream) skipGroup() {
+	if !ts.acceptSymbol("(") {
+		return
+	}
+	ts.skipUntil(")")
+	ts.acceptSymbol(")")
+}
+
+func (ts *tokenStream) text(from, to token) string {
+	return strings.Join(strings.Fields(ts.src[from.pos:to.end]), " ")
+}
+
+func (ts *tokenStream) errorf(format string, args ...any) error {
+	return &Error{Line: ts.peek().line, Message: fmt.Sprintf(format, args...)}
+}
+
+func isKeyword(tok token, keywords ...string) bool {
+	if tok.kind != tokIdent {
+		return false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(tok.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(tok token) string {
+	if tok.kind == tokEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", tok.text)
+}
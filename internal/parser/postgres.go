@@ -0,0 +1,15 @@
+package parser
+
+var postgresSpec = dialectSpec{
+	lex: lexOptions{
+		doubleQuoteIdent: true,
+		dashComment:      true,
+		dollarQuote:      true,
+	},
+	typeSuffixes: [][]string{
+		{"VARYING"},
+		{"PRECISION"},
+		{"WITH", "TIME", "ZONE"},
+		{"WITHOUT", "TIME", "ZONE"},
+	},
+}
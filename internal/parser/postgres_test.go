@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"diagram-server/internal/domain"
+	"testing"
+)
+
+func TestParse_Postgres(t *testing.T) {
+	src := `
+CREATE SCHEMA app;
+
+CREATE TABLE app.users (
+    id SERIAL PRIMARY KEY,
+    email character varying(255) NOT NULL,
+    "displayName" text,
+    created_at timestamp(3) with time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS app.memberships (
+    user_id integer NOT NULL REFERENCES app.users(id) ON DELETE CASCADE,
+    team_id bigint GENERATED BY DEFAULT AS IDENTITY (START WITH 1),
+    role text DEFAULT 'member'::text,
+    tags text[],
+    CONSTRAINT memberships_pkey PRIMARY KEY (user_id, team_id)
+);
+
+CREATE OR REPLACE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+    NEW.created_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON COLUMN app.users.email IS 'login email';
+COMMENT ON COLUMN app.memberships.role IS 'member''s role';
+`
+
+	tables, err := Parse(Postgres, src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("tables length = %v, want 2", len(tables))
+	}
+
+	users := tables[0]
+	if users.Name != "app.users" {
+		t.Errorf("tables[0].Name = %v, want app.users", users.Name)
+	}
+	assertColumns(t, *users.Columns, []domain.Column{
+		{Name: "id", Type: "SERIAL", PK: true, Nullable: false},
+		{Name: "email", Type: "character varying(255)", Nullable: false},
+		{Name: "displayName", Type: "text", Nullable: true},
+		{Name: "created_at", Type: "timestamp(3) with time zone", Nullable: false},
+	})
+	if desc := (*users.Columns)[1].Description; desc == nil || *desc != "login email" {
+		t.Errorf("email Description = %v, want login email", desc)
+	}
+
+	memberships := tables[1]
+	assertColumns(t, *memberships.Columns, []domain.Column{
+		{Name: "user_id", Type: "integer", PK: true, Nullable: false},
+		{Name: "team_id", Type: "bigint", PK: true, Nullable: false},
+		{Name: "role", Type: "text", Nullable: true},
+		{Name: "tags", Type: "text[]", Nullable: true},
+	})
+	if desc := (*memberships.Columns)[2].Description; desc == nil || *desc != "member's role" {
+		t.Errorf("role Description = %v, want member's role", desc)
+	}
+	assertRelations(t, memberships.Relations, []domain.Relation{
+		{From: "app.memberships.user_id", To: "app.users.id", Type: domain.ManyToOne},
+	})
+}
+
+func TestParse_PostgresCommentOnUnknownColumn(t *testing.T) {
+	src := `CREATE TABLE users (id integer);
+		COMMENT ON COLUMN users.missing IS 'x';`
+
+	if _, err := Parse(Postgres, src); err == nil {
+		t.Errorf("Parse() error = nil, want error")
+	}
+}
@@ -0,0 +1,99 @@
+package persistance
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexes 는 컬렉션별로 필요한 인덱스다. 이미 있으면 CreateMany 는 아무것도 하지 않는다.
+// 목록 조회는 볼 수 있는 조건(owner, acl.userId, workspace)마다 정렬 키까지 담은 인덱스를 쓴다.
+var indexes = map[string][]mongo.IndexModel{
+	"diagrams": {
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "dtype", Value: 1}},
+			Options: options.Index().SetName("owner_dtype"),
+		},
+		{
+			Keys:    bson.D{{Key: "acl.userId", Value: 1}, {Key: "dtype", Value: 1}},
+			Options: options.Index().SetName("acl_user_dtype"),
+		},
+		{
+			Keys:    bson.D{{Key: "workspace", Value: 1}, {Key: "dtype", Value: 1}},
+			Options: options.Index().SetName("workspace_dtype"),
+		},
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "modifiedAt", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("owner_modified"),
+		},
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "title", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("owner_title"),
+		},
+		{
+			Keys:    bson.D{{Key: "acl.userId", Value: 1}, {Key: "modifiedAt", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("acl_user_modified"),
+		},
+		{
+			Keys:    bson.D{{Key: "acl.userId", Value: 1}, {Key: "title", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("acl_user_title"),
+		},
+		{
+			Keys:    bson.D{{Key: "workspace", Value: 1}, {Key: "modifiedAt", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("workspace_modified"),
+		},
+		{
+			Keys:    bson.D{{Key: "workspace", Value: 1}, {Key: "title", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("workspace_title"),
+		},
+		// 컬렉션마다 text 인덱스는 하나뿐이다. customer_id 같은 식별자가 어간 추출로 바뀌지 않도록 언어는 none 이다.
+		{
+			Keys: bson.D{
+				{Key: "title", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "tables.name", Value: "text"},
+				{Key: "tables.columns.name", Value: "text"},
+				{Key: "tables.columns.description", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("search_text").
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{
+					{Key: "title", Value: 10},
+					{Key: "tables.name", Value: 5},
+					{Key: "tables.columns.name", Value: 3},
+				}),
+		},
+	},
+	// 다음 번호는 가장 큰 number 로 정하고, 목록과 단건 조회도 diagramId 와 number 로 찾는다
+	"diagram_revisions": {
+		{
+			Keys:    bson.D{{Key: "diagramId", Value: 1}, {Key: "number", Value: -1}},
+			Options: options.Index().SetName("diagram_number"),
+		},
+	},
+	"workspaces": {
+		{
+			Keys:    bson.D{{Key: "members.userId", Value: 1}},
+			Options: options.Index().SetName("members_user"),
+		},
+	},
+	"users": {
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName("email_unique").SetUnique(true),
+		},
+	},
+}
+
+// EnsureIndexes 는 애플리케이션 시작 시 인덱스를 만든다
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	for coll, models := range indexes {
+		if _, err := db.Collection(coll).Indexes().CreateMany(ctx, models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
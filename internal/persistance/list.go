@@ -0,0 +1,169 @@
+package persistance
+
+import (
+	"diagram-server/internal/domain"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidQuery  = errors.New("invalid list query")
+	ErrInvalidCursor = errors.New("invalid page cursor")
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type SortField string
+
+const (
+	SortByModifiedAt SortField = "modifiedAt"
+	SortByTitle      SortField = "title"
+)
+
+// ListQuery 는 다이어그램 목록 조회 조건이다. 비어 있는 조건은 적용하지 않는다.
+// 시간 범위는 After 이상, Before 미만이다.
+type ListQuery struct {
+	Viewer         Viewer
+	Type           domain.DiagramType
+	Owner          string
+	TitlePrefix    string
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+
+	Sort       SortField
+	Descending bool
+	// Cursor 는 이전 페이지의 Page.Next 다
+	Cursor string
+	Limit  int
+}
+
+// SearchQuery 의 Text 는 저장소의 전문 검색 문법을 따른다. 낱말 중 하나라도 있으면 일치한다
+type SearchQuery struct {
+	Viewer Viewer
+	Text   string
+	Limit  int
+}
+
+// SearchResult 는 점수가 높은 순서로 반환된다
+type SearchResult struct {
+	Diagram domain.Diagram
+	Score   float64
+}
+
+func (q *SearchQuery) normalize() error {
+	if strings.TrimSpace(q.Text) == "" {
+		return fmt.Errorf("%w: search text is required", ErrInvalidQuery)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+	return nil
+}
+
+// Page 의 Next 는 다음 페이지가 없으면 빈 문자열이다
+type Page struct {
+	Diagrams []domain.Diagram
+	Next     string
+}
+
+// pageCursor 는 마지막 항목의 정렬 키와 ID 다. 정렬이 바뀐 요청에 재사용하지 못하도록 정렬 조건도 담는다
+type pageCursor struct {
+	Sort       SortField `json:"s"`
+	Descending bool      `json:"d"`
+	Key        string    `json:"k"`
+	ID         string    `json:"id"`
+}
+
+// normalize 는 기본값을 채우고 정렬과 페이지 크기를 검사한다
+func (q *ListQuery) normalize() error {
+	switch q.Sort {
+	case "":
+		q.Sort = SortByModifiedAt
+		q.Descending = true
+	case SortByModifiedAt, SortByTitle:
+	default:
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, q.Sort)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+	return nil
+}
+
+// sortKey 는 정렬 키를 커서에 담을 문자열로 바꾼다
+func (q ListQuery) sortKey(d domain.Diagram) string {
+	if q.Sort == SortByTitle {
+		return diagramTitle(d)
+	}
+	return diagramModifiedAt(d).UTC().Format(time.RFC3339Nano)
+}
+
+func (q ListQuery) encodeCursor(last domain.Diagram) string {
+	data, _ := json.Marshal(pageCursor{
+		Sort:       q.Sort,
+		Descending: q.Descending,
+		Key:        q.sortKey(last),
+		ID:         last.ID(),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 는 커서가 없으면 nil 을 반환한다
+func (q ListQuery) decodeCursor() (*pageCursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != q.Sort || c.Descending != q.Descending {
+		return nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidCursor)
+	}
+	if c.Sort == SortByModifiedAt {
+		if _, err := time.Parse(time.RFC3339Nano, c.Key); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return &c, nil
+}
+
+type titled interface {
+	Title() string
+	ModifiedAt() time.Time
+}
+
+func diagramTitle(d domain.Diagram) string {
+	if t, ok := d.(titled); ok {
+		return t.Title()
+	}
+	return ""
+}
+
+func diagramModifiedAt(d domain.Diagram) time.Time {
+	if t, ok := d.(titled); ok {
+		return t.ModifiedAt()
+	}
+	return d.CreatedAt()
+}
@@ -0,0 +1,100 @@
+package persistance
+
+import (
+	"diagram-server/internal/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestListQuery_Cursor(t *testing.T) {
+	erd := domain.NewERDiagram("Orders", nil, "alice", nil)
+	erd.SetID("d1")
+
+	issued := ListQuery{Sort: SortByTitle}
+	token := issued.encodeCursor(erd)
+
+	tests := []struct {
+		name    string
+		query   ListQuery
+		want    *pageCursor
+		wantErr error
+	}{
+		{
+			name:  "같은 정렬이면 커서를 되살린다",
+			query: ListQuery{Sort: SortByTitle, Cursor: token},
+			want:  &pageCursor{Sort: SortByTitle, Key: "Orders", ID: "d1"},
+		},
+		{
+			name:  "커서가 없으면 첫 페이지다",
+			query: ListQuery{Sort: SortByTitle},
+		},
+		{
+			name:    "정렬이 바뀌면 거부한다",
+			query:   ListQuery{Sort: SortByTitle, Descending: true, Cursor: token},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "형식이 잘못된 커서는 거부한다",
+			query:   ListQuery{Sort: SortByTitle, Cursor: "not-a-cursor"},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.decodeCursor()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decodeCursor() error = %v, want %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("decodeCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListQuery_Normalize(t *testing.T) {
+	q := ListQuery{Limit: MaxPageSize + 1}
+	if err := q.normalize(); err != nil {
+		t.Fatalf("normalize() error = %v", err)
+	}
+	if q.Sort != SortByModifiedAt || !q.Descending || q.Limit != MaxPageSize {
+		t.Errorf("normalize() = %+v, want newest first with limit %d", q, MaxPageSize)
+	}
+
+	bad := ListQuery{Sort: "owner"}
+	if err := bad.normalize(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("normalize() error = %v, want %v", err, ErrInvalidQuery)
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := ListQuery{
+		Viewer:        Viewer{UserID: "alice", Workspaces: []string{"w1"}},
+		Type:          domain.TypeERD,
+		TitlePrefix:   "a.b",
+		ModifiedAfter: from,
+		Sort:          SortByTitle,
+		Descending:    true,
+	}
+
+	conditions := listFilter(q, &pageCursor{Sort: SortByTitle, Descending: true, Key: "Orders", ID: "d1"})["$and"].(bson.A)
+
+	if len(conditions) != 5 {
+		t.Fatalf("conditions = %v, want visibility, type, title, modifiedAt and cursor", conditions)
+	}
+	if got := conditions[2].(bson.M)["title"].(bson.M)["$regex"]; got != `^a\.b` {
+		t.Errorf("title regex = %v, want escaped prefix", got)
+	}
+	if got := conditions[3].(bson.M)["modifiedAt"].(bson.M)["$gte"]; got != from {
+		t.Errorf("modifiedAt = %v, want $gte %v", got, from)
+	}
+	after := conditions[4].(bson.M)["$or"].(bson.A)
+	if got := after[0].(bson.M)["title"].(bson.M)["$lt"]; got != "Orders" {
+		t.Errorf("cursor condition = %v, want title < Orders for descending order", after)
+	}
+}
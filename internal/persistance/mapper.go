@@ -9,6 +9,8 @@ func ToModel(d domain.Diagram) *DiagramModel {
 	switch v := d.(type) {
 	case *domain.ERDiagram:
 		return toERDiagramModel(v)
+	case *domain.FlowChart:
+		return toFlowChartModel(v)
 	}
 	return nil
 }
@@ -20,12 +22,56 @@ func toERDiagramModel(d *domain.ERDiagram) *DiagramModel {
 		Title:       d.Title(),
 		Description: d.Description(),
 		Owner:       d.Owner(),
+		Workspace:   d.Workspace(),
 		CreatedAt:   d.CreatedAt(),
 		ModifiedAt:  d.ModifiedAt(),
+		Version:     d.Version(),
+		ACL:         toGrantModels(d.ACL()),
 		Tables:      toTableModels(d.Tables),
 	}
 }
 
+func toFlowChartModel(f *domain.FlowChart) *DiagramModel {
+	return &DiagramModel{
+		ID:          f.ID(),
+		Dtype:       string(f.Type()),
+		Title:       f.Title(),
+		Description: f.Description(),
+		Owner:       f.Owner(),
+		Workspace:   f.Workspace(),
+		CreatedAt:   f.CreatedAt(),
+		ModifiedAt:  f.ModifiedAt(),
+		Version:     f.Version(),
+		ACL:         toGrantModels(f.ACL()),
+		Nodes:       toFlowNodeModels(f.Nodes),
+		Edges:       toFlowEdgeModels(f.Edges),
+	}
+}
+
+func toGrantModels(acl []domain.Grant) []GrantModel {
+	if acl == nil {
+		return nil
+	}
+
+	result := make([]GrantModel, len(acl))
+	for i, g := range acl {
+		result[i] = GrantModel{UserID: g.UserID, Role: string(g.Role)}
+	}
+	return result
+}
+
+func toGrantDomains(acl []GrantModel) []domain.Grant {
+	if acl == nil {
+		return nil
+	}
+
+	result := make([]domain.Grant, len(acl))
+	for i, g := range acl {
+		result[i] = domain.Grant{UserID: g.UserID, Role: domain.Role(g.Role)}
+	}
+	return result
+}
+
 func toTableModels(tables []domain.Table) []TableModel {
 	if tables == nil {
 		return nil
@@ -38,6 +84,7 @@ func toTableModels(tables []domain.Table) []TableModel {
 			OriginalQuery: t.OriginalQuery,
 			Columns:       toColumModels(t.Columns),
 			Relations:     toRelationModels(t.Relations),
+			Layout:        toLayoutModel(t.Layout),
 		}
 	}
 
@@ -62,6 +109,53 @@ func toColumModels(columns *[]domain.Column) []ColumnModel {
 	return result
 }
 
+func toLayoutModel(l *domain.TableLayout) *LayoutModel {
+	if l == nil {
+		return nil
+	}
+
+	return &LayoutModel{
+		X:         l.X,
+		Y:         l.Y,
+		Width:     l.Width,
+		Collapsed: l.Collapsed,
+		Color:     l.Color,
+		ZIndex:    l.ZIndex,
+	}
+}
+
+func toFlowNodeModels(nodes []domain.FlowNode) []FlowNodeModel {
+	if nodes == nil {
+		return nil
+	}
+
+	result := make([]FlowNodeModel, len(nodes))
+	for i, n := range nodes {
+		result[i] = FlowNodeModel{
+			ID:    n.ID,
+			Type:  string(n.Type),
+			Label: n.Label,
+		}
+	}
+	return result
+}
+
+func toFlowEdgeModels(edges []domain.FlowEdge) []FlowEdgeModel {
+	if edges == nil {
+		return nil
+	}
+
+	result := make([]FlowEdgeModel, len(edges))
+	for i, e := range edges {
+		result[i] = FlowEdgeModel{
+			From:  e.From,
+			To:    e.To,
+			Label: e.Label,
+		}
+	}
+	return result
+}
+
 func toRelationModels(relations *[]domain.Relation) []RelationModel {
 	if relations == nil {
 		return nil
@@ -83,8 +177,7 @@ func (m DiagramModel) ToEntity() (domain.Diagram, error) {
 	case domain.TypeERD:
 		return m.toERDiagram(), nil
 	case domain.TypeFlowChart:
-		// TODO
-		return nil, fmt.Errorf("flowchart not implemented")
+		return m.toFlowChart(), nil
 	}
 	return nil, fmt.Errorf("unknown dtype: %s", m.Dtype)
 }
@@ -99,6 +192,9 @@ func (m *DiagramModel) toERDiagram() *domain.ERDiagram {
 		m.CreatedAt,
 		m.ModifiedAt,
 	)
+	base.SetVersion(m.Version)
+	base.SetACL(toGrantDomains(m.ACL))
+	base.SetWorkspace(m.Workspace)
 
 	return &domain.ERDiagram{
 		BaseDiagram: base,
@@ -106,6 +202,27 @@ func (m *DiagramModel) toERDiagram() *domain.ERDiagram {
 	}
 }
 
+func (m *DiagramModel) toFlowChart() *domain.FlowChart {
+	base := domain.RestoreBaseDiagram(
+		m.ID,
+		m.Title,
+		m.Description,
+		domain.TypeFlowChart,
+		m.Owner,
+		m.CreatedAt,
+		m.ModifiedAt,
+	)
+	base.SetVersion(m.Version)
+	base.SetACL(toGrantDomains(m.ACL))
+	base.SetWorkspace(m.Workspace)
+
+	return &domain.FlowChart{
+		BaseDiagram: base,
+		Nodes:       toFlowNodeDomains(m.Nodes),
+		Edges:       toFlowEdgeDomains(m.Edges),
+	}
+}
+
 func toTableDomains(tables []TableModel) []domain.Table {
 	if tables == nil {
 		return nil
@@ -118,6 +235,7 @@ func toTableDomains(tables []TableModel) []domain.Table {
 			OriginalQuery: t.OriginalQuery,
 			Columns:       toColumnDomains(t.Columns),
 			Relations:     toRelationDomains(t.Relations),
+			Layout:        toLayoutDomain(t.Layout),
 		}
 	}
 	return result
@@ -156,3 +274,115 @@ func toRelationDomains(relations []RelationModel) *[]domain.Relation {
 	}
 	return &result
 }
+
+func toLayoutDomain(l *LayoutModel) *domain.TableLayout {
+	if l == nil {
+		return nil
+	}
+
+	return &domain.TableLayout{
+		X:         l.X,
+		Y:         l.Y,
+		Width:     l.Width,
+		Collapsed: l.Collapsed,
+		Color:     l.Color,
+		ZIndex:    l.ZIndex,
+	}
+}
+
+func toFlowNodeDomains(nodes []FlowNodeModel) []domain.FlowNode {
+	if nodes == nil {
+		return nil
+	}
+
+	result := make([]domain.FlowNode, len(nodes))
+	for i, n := range nodes {
+		result[i] = domain.FlowNode{
+			ID:    n.ID,
+			Type:  domain.NodeType(n.Type),
+			Label: n.Label,
+		}
+	}
+	return result
+}
+
+func toFlowEdgeDomains(edges []FlowEdgeModel) []domain.FlowEdge {
+	if edges == nil {
+		return nil
+	}
+
+	result := make([]domain.FlowEdge, len(edges))
+	for i, e := range edges {
+		result[i] = domain.FlowEdge{
+			From:  e.From,
+			To:    e.To,
+			Label: e.Label,
+		}
+	}
+	return result
+}
+
+func toRevisionModel(r *domain.Revision) *RevisionModel {
+	return &RevisionModel{
+		ID:        fmt.Sprintf("%s:%d", r.DiagramID, r.Number),
+		DiagramID: r.DiagramID,
+		Number:    r.Number,
+		Author:    r.Author,
+		Summary:   r.Summary,
+		CreatedAt: r.CreatedAt,
+		Snapshot:  *ToModel(r.Diagram),
+	}
+}
+
+func (m RevisionModel) ToEntity() (*domain.Revision, error) {
+	diagram, err := m.Snapshot.ToEntity()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Revision{
+		DiagramID: m.DiagramID,
+		Number:    m.Number,
+		Author:    m.Author,
+		Summary:   m.Summary,
+		CreatedAt: m.CreatedAt,
+		Diagram:   diagram,
+	}, nil
+}
+
+func toUserModel(u *domain.User) *UserModel {
+	return &UserModel{
+		ID:        u.ID(),
+		Email:     u.Email(),
+		Password:  u.Password(),
+		Name:      u.Name(),
+		CreatedAt: u.CreatedAt(),
+	}
+}
+
+func (m UserModel) ToEntity() *domain.User {
+	return domain.RestoreUser(m.ID, m.Email, m.Password, m.Name, m.CreatedAt)
+}
+
+func toWorkspaceModel(w *domain.Workspace) *WorkspaceModel {
+	members := make([]MemberModel, len(w.Members()))
+	for i, m := range w.Members() {
+		members[i] = MemberModel{UserID: m.UserID, Role: string(m.Role)}
+	}
+
+	return &WorkspaceModel{
+		ID:        w.ID(),
+		Name:      w.Name(),
+		Members:   members,
+		CreatedAt: w.CreatedAt(),
+		Version:   w.Version(),
+	}
+}
+
+func (m WorkspaceModel) ToEntity() *domain.Workspace {
+	members := make([]domain.Member, len(m.Members))
+	for i, mm := range m.Members {
+		members[i] = domain.Member{UserID: mm.UserID, Role: domain.Role(mm.Role)}
+	}
+	return domain.RestoreWorkspace(m.ID, m.Name, members, m.CreatedAt, m.Version)
+}
@@ -0,0 +1,573 @@
+package persistance
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 메모리 저장소는 DB 없이 서버를 띄우거나 테스트할 때 쓴다. 프로세스가 끝나면 내용이 사라진다.
+// 도메인 객체 대신 모델을 저장해 저장 후 객체를 바꿔도 저장된 값에 영향이 없고,
+// 시각은 MongoDB 처럼 밀리초 단위 UTC 로 맞춘다.
+
+type memoryDiagramRepository struct {
+	mu       sync.RWMutex
+	diagrams map[string]*DiagramModel
+	// order 는 저장 순서다. 정렬 조건이 없는 조회는 이 순서를 따른다
+	order []string
+}
+
+func NewMemoryDiagramRepository() DiagramRepository {
+	return &memoryDiagramRepository{diagrams: make(map[string]*DiagramModel)}
+}
+
+func (r *memoryDiagramRepository) Save(ctx context.Context, d domain.Diagram) (string, error) {
+	model := ToModel(d)
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+	storeTimes(model)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.diagrams[model.ID]; ok {
+		return "", ErrDuplicateID
+	}
+	r.order = append(r.order, model.ID)
+	r.diagrams[model.ID] = model
+	return model.ID, nil
+}
+
+func (r *memoryDiagramRepository) FindByID(ctx context.Context, id string) (domain.Diagram, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model, ok := r.diagrams[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return model.ToEntity()
+}
+
+func (r *memoryDiagramRepository) FindByType(ctx context.Context, dtype domain.DiagramType, viewer Viewer) ([]domain.Diagram, error) {
+	return r.find(func(m *DiagramModel) bool {
+		return m.Dtype == string(dtype) && viewer.canSee(m)
+	})
+}
+
+func (r *memoryDiagramRepository) FindByWorkspace(ctx context.Context, workspace string) ([]domain.Diagram, error) {
+	return r.find(func(m *DiagramModel) bool {
+		return m.Workspace == workspace
+	})
+}
+
+func (r *memoryDiagramRepository) List(ctx context.Context, q ListQuery) (*Page, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	after, err := q.decodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	var models []*DiagramModel
+	for _, id := range r.order {
+		if m := r.diagrams[id]; q.matches(m) {
+			models = append(models, m)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(models, func(i, j int) bool {
+		return q.less(models[i], models[j])
+	})
+
+	page := &Page{}
+	for _, m := range models {
+		if after != nil && !q.isAfter(m, after) {
+			continue
+		}
+		if len(page.Diagrams) == q.Limit {
+			page.Next = q.encodeCursor(page.Diagrams[q.Limit-1])
+			break
+		}
+
+		entity, err := m.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		page.Diagrams = append(page.Diagrams, entity)
+	}
+	return page, nil
+}
+
+// Search 는 MongoDB 의 text 인덱스처럼 낱말 단위로 대소문자 없이 찾는다. 필드 가중치도 같다
+func (r *memoryDiagramRepository) Search(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	include, exclude := searchTerms(q.Text)
+
+	r.mu.RLock()
+	type scored struct {
+		model *DiagramModel
+		score float64
+	}
+	var hits []scored
+	for _, id := range r.order {
+		m := r.diagrams[id]
+		if !q.Viewer.canSee(m) {
+			continue
+		}
+		if score := textScore(m, include, exclude); score > 0 {
+			hits = append(hits, scored{model: m, score: score})
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].model.ID < hits[j].model.ID
+	})
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+
+	results := make([]SearchResult, len(hits))
+	for i, h := range hits {
+		entity, err := h.model.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		results[i] = SearchResult{Diagram: entity, Score: h.score}
+	}
+	return results, nil
+}
+
+func (r *memoryDiagramRepository) Update(ctx context.Context, d domain.Diagram) error {
+	model := ToModel(d)
+	model.Version = d.Version() + 1
+	storeTimes(model)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.diagrams[model.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != d.Version() {
+		return ErrVersionConflict
+	}
+
+	r.diagrams[model.ID] = model
+	d.SetVersion(model.Version)
+	return nil
+}
+
+// UpdateLayout 은 이름이 같은 테이블의 layout 만 바꾼다. 없는 테이블 이름은 무시한다
+func (r *memoryDiagramRepository) UpdateLayout(ctx context.Context, id string, version int64, layouts map[string]*domain.TableLayout) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.diagrams[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	if version != AnyVersion && stored.Version != version {
+		return 0, ErrVersionConflict
+	}
+
+	// 이전에 반환한 값과 공유하지 않도록 테이블 목록을 복사해서 바꾼다
+	updated := *stored
+	updated.Tables = append([]TableModel(nil), stored.Tables...)
+	for i := range updated.Tables {
+		if l, ok := layouts[updated.Tables[i].Name]; ok {
+			updated.Tables[i].Layout = toLayoutModel(l)
+		}
+	}
+	updated.Version++
+
+	r.diagrams[id] = &updated
+	return updated.Version, nil
+}
+
+func (r *memoryDiagramRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.diagrams[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.diagrams, id)
+	for i, v := range r.order {
+		if v == id {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *memoryDiagramRepository) find(match func(m *DiagramModel) bool) ([]domain.Diagram, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []domain.Diagram
+	for _, id := range r.order {
+		m := r.diagrams[id]
+		if !match(m) {
+			continue
+		}
+		entity, err := m.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, entity)
+	}
+	return results, nil
+}
+
+// storeTimes 는 MongoDB 에 저장했다 읽은 것과 같은 정밀도로 시각을 맞춘다
+func storeTimes(m *DiagramModel) {
+	m.CreatedAt = m.CreatedAt.Truncate(time.Millisecond).UTC()
+	m.ModifiedAt = m.ModifiedAt.Truncate(time.Millisecond).UTC()
+}
+
+// canSee 는 visibleTo 와 같은 조건이다
+func (v Viewer) canSee(m *DiagramModel) bool {
+	if m.Owner == v.UserID {
+		return true
+	}
+	for _, g := range m.ACL {
+		if g.UserID == v.UserID {
+			return true
+		}
+	}
+	for _, w := range v.Workspaces {
+		if m.Workspace != "" && m.Workspace == w {
+			return true
+		}
+	}
+	return false
+}
+
+// matches 는 listFilter 와 같은 조건이다
+func (q ListQuery) matches(m *DiagramModel) bool {
+	switch {
+	case !q.Viewer.canSee(m),
+		q.Type != "" && m.Dtype != string(q.Type),
+		q.Owner != "" && m.Owner != q.Owner,
+		q.TitlePrefix != "" && !strings.HasPrefix(m.Title, q.TitlePrefix),
+		!inRange(m.CreatedAt, q.CreatedAfter, q.CreatedBefore),
+		!inRange(m.ModifiedAt, q.ModifiedAfter, q.ModifiedBefore):
+		return false
+	}
+	return true
+}
+
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// less 는 정렬 키, 같으면 ID 순서다
+func (q ListQuery) less(a, b *DiagramModel) bool {
+	c := q.compare(a, b.ID, q.modelKey(b))
+	if q.Descending {
+		return c > 0
+	}
+	return c < 0
+}
+
+// isAfter 는 m 이 커서가 가리키는 항목보다 뒤에 오는지 본다
+func (q ListQuery) isAfter(m *DiagramModel, after *pageCursor) bool {
+	c := q.compare(m, after.ID, after.Key)
+	if q.Descending {
+		return c < 0
+	}
+	return c > 0
+}
+
+func (q ListQuery) compare(m *DiagramModel, id, key string) int {
+	if c := q.compareKey(q.modelKey(m), key); c != 0 {
+		return c
+	}
+	return strings.Compare(m.ID, id)
+}
+
+func (q ListQuery) modelKey(m *DiagramModel) string {
+	if q.Sort == SortByTitle {
+		return m.Title
+	}
+	return m.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// compareKey 는 RFC3339Nano 문자열이 길이가 달라 사전순으로 비교할 수 없어 시각으로 바꿔 비교한다
+func (q ListQuery) compareKey(a, b string) int {
+	if q.Sort == SortByTitle {
+		return strings.Compare(a, b)
+	}
+	ta, _ := time.Parse(time.RFC3339Nano, a)
+	tb, _ := time.Parse(time.RFC3339Nano, b)
+	return ta.Compare(tb)
+}
+
+// searchTerms 는 - 로 시작하는 낱말을 제외어로 나눈다
+func searchTerms(text string) (include, exclude []string) {
+	for _, word := range strings.Fields(text) {
+		negate := strings.HasPrefix(word, "-")
+		for _, token := range tokenize(strings.TrimPrefix(word, "-")) {
+			if negate {
+				exclude = append(exclude, token)
+			} else {
+				include = append(include, token)
+			}
+		}
+	}
+	return include, exclude
+}
+
+// tokenize 는 text 인덱스처럼 공백과 문장 부호로 낱말을 나눈다. _ 는 낱말에 포함한다
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// textScore 는 search_text 인덱스의 가중치로 일치한 낱말 수를 센다. 제외어가 있으면 0 이다
+func textScore(m *DiagramModel, include, exclude []string) float64 {
+	type field struct {
+		text   string
+		weight float64
+	}
+	fields := []field{{m.Title, 10}}
+	if m.Description != nil {
+		fields = append(fields, field{*m.Description, 1})
+	}
+	for _, t := range m.Tables {
+		fields = append(fields, field{t.Name, 5})
+		for _, c := range t.Columns {
+			fields = append(fields, field{c.Name, 3})
+			if c.Description != nil {
+				fields = append(fields, field{*c.Description, 1})
+			}
+		}
+	}
+
+	var score float64
+	for _, f := range fields {
+		for _, token := range tokenize(f.text) {
+			for _, term := range exclude {
+				if token == term {
+					return 0
+				}
+			}
+			for _, term := range include {
+				if token == term {
+					score += f.weight
+				}
+			}
+		}
+	}
+	return score
+}
+
+type memoryRevisionRepository struct {
+	mu        sync.RWMutex
+	revisions map[string][]*RevisionModel
+}
+
+func NewMemoryRevisionRepository() RevisionRepository {
+	return &memoryRevisionRepository{revisions: make(map[string][]*RevisionModel)}
+}
+
+func (r *memoryRevisionRepository) Save(ctx context.Context, rev *domain.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := r.revisions[rev.DiagramID]
+	rev.Number = 1
+	if n := len(existing); n > 0 {
+		rev.Number = existing[n-1].Number + 1
+	}
+
+	model := toRevisionModel(rev)
+	model.CreatedAt = model.CreatedAt.Truncate(time.Millisecond).UTC()
+	storeTimes(&model.Snapshot)
+	r.revisions[rev.DiagramID] = append(existing, model)
+	return nil
+}
+
+func (r *memoryRevisionRepository) FindByDiagram(ctx context.Context, diagramID string) ([]*domain.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*domain.Revision
+	for _, model := range r.revisions[diagramID] {
+		rev, err := model.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rev)
+	}
+	return results, nil
+}
+
+func (r *memoryRevisionRepository) FindByNumber(ctx context.Context, diagramID string, number int) (*domain.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, model := range r.revisions[diagramID] {
+		if model.Number == number {
+			return model.ToEntity()
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
+
+func (r *memoryRevisionRepository) DeleteByDiagram(ctx context.Context, diagramID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.revisions, diagramID)
+	return nil
+}
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*UserModel
+}
+
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[string]*UserModel)}
+}
+
+// Save 는 email_unique 인덱스처럼 정규화된 이메일이 겹치면 ErrEmailTaken 을 반환한다
+func (r *memoryUserRepository) Save(ctx context.Context, u *domain.User) (string, error) {
+	model := toUserModel(u)
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+	model.CreatedAt = model.CreatedAt.Truncate(time.Millisecond).UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == model.Email {
+			return "", ErrEmailTaken
+		}
+	}
+	r.users[model.ID] = model
+	return model.ID, nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return model.ToEntity(), nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	email = domain.NormalizeEmail(email)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, model := range r.users {
+		if model.Email == email {
+			return model.ToEntity(), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+type memoryWorkspaceRepository struct {
+	mu         sync.RWMutex
+	workspaces map[string]*WorkspaceModel
+	order      []string
+}
+
+func NewMemoryWorkspaceRepository() WorkspaceRepository {
+	return &memoryWorkspaceRepository{workspaces: make(map[string]*WorkspaceModel)}
+}
+
+func (r *memoryWorkspaceRepository) Save(ctx context.Context, w *domain.Workspace) (string, error) {
+	model := toWorkspaceModel(w)
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+	model.CreatedAt = model.CreatedAt.Truncate(time.Millisecond).UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workspaces[model.ID]; !ok {
+		r.order = append(r.order, model.ID)
+	}
+	r.workspaces[model.ID] = model
+	return model.ID, nil
+}
+
+func (r *memoryWorkspaceRepository) FindByID(ctx context.Context, id string) (*domain.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model, ok := r.workspaces[id]
+	if !ok {
+		return nil, ErrWorkspaceNotFound
+	}
+	return model.ToEntity(), nil
+}
+
+func (r *memoryWorkspaceRepository) FindByMember(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*domain.Workspace
+	for _, id := range r.order {
+		model := r.workspaces[id]
+		for _, m := range model.Members {
+			if m.UserID == userID {
+				results = append(results, model.ToEntity())
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+func (r *memoryWorkspaceRepository) Update(ctx context.Context, w *domain.Workspace) error {
+	model := toWorkspaceModel(w)
+	model.CreatedAt = model.CreatedAt.Truncate(time.Millisecond).UTC()
+	model.Version = w.Version() + 1
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.workspaces[model.ID]
+	if !ok {
+		return ErrWorkspaceNotFound
+	}
+	if stored.Version != w.Version() {
+		return ErrWorkspaceConflict
+	}
+	r.workspaces[model.ID] = model
+	w.SetVersion(model.Version)
+	return nil
+}
@@ -0,0 +1,250 @@
+package persistance
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func saveERD(t *testing.T, repo DiagramRepository, title, owner string, tables ...domain.Table) string {
+	t.Helper()
+
+	id, err := repo.Save(context.Background(), domain.NewERDiagram(title, nil, owner, tables))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return id
+}
+
+func TestMemoryDiagramRepository(t *testing.T) {
+	testDiagramRepository(t, NewMemoryDiagramRepository())
+}
+
+func TestMemoryDiagramRepository_List(t *testing.T) {
+	testDiagramRepositoryList(t, NewMemoryDiagramRepository())
+}
+
+func TestMemoryDiagramRepository_Search(t *testing.T) {
+	testDiagramRepositorySearch(t, NewMemoryDiagramRepository())
+}
+
+func TestMemoryUserRepository_EmailTaken(t *testing.T) {
+	testUserRepositoryEmailTaken(t, NewMemoryUserRepository())
+}
+
+func TestMemoryWorkspaceRepository(t *testing.T) {
+	testWorkspaceRepository(t, NewMemoryWorkspaceRepository())
+}
+
+// 아래 테스트는 저장소 구현마다 같은 동작을 하는지 확인한다
+
+func testDiagramRepository(t *testing.T, repo DiagramRepository) {
+	ctx := context.Background()
+
+	id := saveERD(t, repo, "Orders", "alice")
+	if id == "" {
+		t.Fatal("Save() 가 ID 를 만들지 않음")
+	}
+	flow := domain.NewFlowChart("Flow", nil, "alice", nil, nil)
+	if _, err := repo.Save(ctx, flow); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("이미 있는 ID 로 저장하면 ErrDuplicateID 다", func(t *testing.T) {
+		other := domain.NewERDiagram("Other", nil, "bob", nil)
+		other.SetID(id)
+		if _, err := repo.Save(ctx, other); !errors.Is(err, ErrDuplicateID) {
+			t.Errorf("Save() error = %v, want %v", err, ErrDuplicateID)
+		}
+		if got, _ := repo.FindByID(ctx, id); got.Owner() != "alice" {
+			t.Errorf("Owner() = %q, want the first diagram kept", got.Owner())
+		}
+	})
+
+	t.Run("없는 ID 는 ErrNotFound 다", func(t *testing.T) {
+		if _, err := repo.FindByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByID() error = %v, want %v", err, ErrNotFound)
+		}
+		if err := repo.Delete(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete() error = %v, want %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("타입과 볼 수 있는 사용자로 거른다", func(t *testing.T) {
+		got, _ := repo.FindByType(ctx, domain.TypeERD, Viewer{UserID: "alice"})
+		if len(got) != 1 || got[0].ID() != id {
+			t.Errorf("FindByType(alice) = %v, want [%s]", got, id)
+		}
+		if got, _ := repo.FindByType(ctx, domain.TypeERD, Viewer{UserID: "bob"}); len(got) != 0 {
+			t.Errorf("FindByType(bob) = %v, want empty", got)
+		}
+	})
+
+	t.Run("읽은 값을 바꿔도 저장된 값은 그대로다", func(t *testing.T) {
+		d, _ := repo.FindByID(ctx, id)
+		d.(*domain.ERDiagram).UpdateTitle("changed")
+
+		again, _ := repo.FindByID(ctx, id)
+		if title := again.(*domain.ERDiagram).Title(); title != "Orders" {
+			t.Errorf("Title() = %q, want %q", title, "Orders")
+		}
+	})
+
+	t.Run("버전이 다르면 ErrVersionConflict 다", func(t *testing.T) {
+		first, _ := repo.FindByID(ctx, id)
+		second, _ := repo.FindByID(ctx, id)
+
+		if err := repo.Update(ctx, first); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if first.Version() != 2 {
+			t.Errorf("Version() = %d, want 2", first.Version())
+		}
+		if err := repo.Update(ctx, second); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("Update() error = %v, want %v", err, ErrVersionConflict)
+		}
+	})
+}
+
+func testDiagramRepositoryList(t *testing.T, repo DiagramRepository) {
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		saveERD(t, repo, fmt.Sprintf("Diagram %d", i), "alice")
+	}
+	saveERD(t, repo, "Other", "bob")
+
+	var titles []string
+	q := ListQuery{Viewer: Viewer{UserID: "alice"}, Sort: SortByTitle, Descending: true, Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("페이지가 끝나지 않음")
+		}
+		page, err := repo.List(ctx, q)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, d := range page.Diagrams {
+			titles = append(titles, d.(*domain.ERDiagram).Title())
+		}
+		if page.Next == "" {
+			break
+		}
+		q.Cursor = page.Next
+	}
+
+	want := []string{"Diagram 5", "Diagram 4", "Diagram 3", "Diagram 2", "Diagram 1"}
+	if fmt.Sprint(titles) != fmt.Sprint(want) {
+		t.Errorf("titles = %v, want %v", titles, want)
+	}
+}
+
+func testDiagramRepositorySearch(t *testing.T, repo DiagramRepository) {
+	ctx := context.Background()
+
+	columns := []domain.Column{{Name: "customer_id", Type: "bigint"}}
+	withColumn := saveERD(t, repo, "Orders", "alice", domain.Table{Name: "orders", Columns: &columns})
+	saveERD(t, repo, "Customer_ID notes", "alice")
+	saveERD(t, repo, "Hidden", "bob", domain.Table{Name: "orders", Columns: &columns})
+
+	tests := []struct {
+		name  string
+		text  string
+		wantN int
+		first string
+	}{
+		{name: "낱말이 일치하는 다이어그램을 찾는다", text: "customer_id", wantN: 2},
+		{name: "낱말 일부는 일치하지 않는다", text: "customer", wantN: 0},
+		{name: "제외어가 있는 다이어그램은 뺀다", text: "customer_id -notes", wantN: 1, first: withColumn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Search(ctx, SearchQuery{Viewer: Viewer{UserID: "alice"}, Text: tt.text})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(got) != tt.wantN {
+				t.Fatalf("Search() = %d results, want %d", len(got), tt.wantN)
+			}
+			if tt.first != "" && got[0].Diagram.ID() != tt.first {
+				t.Errorf("first = %s, want %s", got[0].Diagram.ID(), tt.first)
+			}
+		})
+	}
+}
+
+func testUserRepositoryEmailTaken(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+
+	alice, _ := domain.NewUser("alice@example.com", "password1", "Alice")
+	if _, err := repo.Save(ctx, alice); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	again, _ := domain.NewUser("ALICE@example.com", "password2", "Alice")
+	if _, err := repo.Save(ctx, again); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Save() error = %v, want %v", err, ErrEmailTaken)
+	}
+	if _, err := repo.FindByEmail(ctx, " Alice@Example.com "); err != nil {
+		t.Errorf("FindByEmail() error = %v", err)
+	}
+}
+
+func testWorkspaceRepository(t *testing.T, repo WorkspaceRepository) {
+	ctx := context.Background()
+
+	team, _ := domain.NewWorkspace("team", "alice")
+	id, err := repo.Save(ctx, team)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	other, _ := domain.NewWorkspace("other", "bob")
+	if _, err := repo.Save(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("동시에 구성원을 바꾸면 늦게 저장한 쪽이 ErrWorkspaceConflict 다", func(t *testing.T) {
+		first, _ := repo.FindByID(ctx, id)
+		second, _ := repo.FindByID(ctx, id)
+
+		_ = first.AddMember("bob", domain.RoleEditor)
+		if err := repo.Update(ctx, first); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if first.Version() != 2 {
+			t.Errorf("Version() = %d, want 2", first.Version())
+		}
+
+		_ = second.AddMember("carol", domain.RoleViewer)
+		if err := repo.Update(ctx, second); !errors.Is(err, ErrWorkspaceConflict) {
+			t.Errorf("Update() error = %v, want %v", err, ErrWorkspaceConflict)
+		}
+
+		stored, _ := repo.FindByID(ctx, id)
+		if stored.RoleOf("bob") != domain.RoleEditor || stored.RoleOf("carol") != "" || stored.Version() != 2 {
+			t.Errorf("stored = %+v, want bob only at version 2", stored.Members())
+		}
+	})
+
+	t.Run("없는 워크스페이스는 ErrWorkspaceNotFound 다", func(t *testing.T) {
+		missing := domain.RestoreWorkspace("missing", "missing", nil, team.CreatedAt(), 1)
+		if err := repo.Update(ctx, missing); !errors.Is(err, ErrWorkspaceNotFound) {
+			t.Errorf("Update() error = %v, want %v", err, ErrWorkspaceNotFound)
+		}
+	})
+
+	t.Run("구성원인 워크스페이스만 찾는다", func(t *testing.T) {
+		got, err := repo.FindByMember(ctx, "bob")
+		if err != nil {
+			t.Fatalf("FindByMember() error = %v", err)
+		}
+		if len(got) != 2 || got[0].ID() != id || len(got[0].Members()) != 2 || got[0].Version() != 2 {
+			t.Errorf("FindByMember(bob) = %+v, want team with both members first, then other", got)
+		}
+		if got, _ := repo.FindByMember(ctx, "carol"); len(got) != 0 {
+			t.Errorf("FindByMember(carol) = %v, want empty", got)
+		}
+	})
+}
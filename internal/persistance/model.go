@@ -5,35 +5,100 @@ import (
 )
 
 type DiagramModel struct {
-	ID          string    `bson:"_id,omitempty"`
-	Dtype       string    `bson:"dtype"`
-	Title       string    `bson:"title"`
-	Owner       string    `bson:"owner"`
-	Description *string   `bson:"description,omitempty"`
-	CreatedAt   time.Time `bson:"createdAt"`
-	ModifiedAt  time.Time `bson:"modifiedAt"`
+	ID          string       `bson:"_id,omitempty" json:"id,omitempty"`
+	Dtype       string       `bson:"dtype" json:"dtype"`
+	Title       string       `bson:"title" json:"title"`
+	Owner       string       `bson:"owner" json:"owner"`
+	Workspace   string       `bson:"workspace,omitempty" json:"workspace,omitempty"`
+	Description *string      `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt   time.Time    `bson:"createdAt" json:"createdAt"`
+	ModifiedAt  time.Time    `bson:"modifiedAt" json:"modifiedAt"`
+	Version     int64        `bson:"version" json:"version"`
+	ACL         []GrantModel `bson:"acl,omitempty" json:"acl,omitempty"`
 
 	// Dtype == ERDiagram
-	Tables []TableModel `bson:"tables,omitempty"`
+	Tables []TableModel `bson:"tables,omitempty" json:"tables,omitempty"`
+
+	// Dtype == FlowChart
+	Nodes []FlowNodeModel `bson:"nodes,omitempty" json:"nodes,omitempty"`
+	Edges []FlowEdgeModel `bson:"edges,omitempty" json:"edges,omitempty"`
+}
+
+type GrantModel struct {
+	UserID string `bson:"userId" json:"userId"`
+	Role   string `bson:"role" json:"role"`
 }
 
 type TableModel struct {
-	Name          string          `bson:"name"`
-	OriginalQuery *string         `bson:"original_query,omitempty"`
-	Columns       []ColumnModel   `bson:"columns"`
-	Relations     []RelationModel `bson:"relations"`
+	Name          string          `bson:"name" json:"name"`
+	OriginalQuery *string         `bson:"original_query,omitempty" json:"original_query,omitempty"`
+	Columns       []ColumnModel   `bson:"columns" json:"columns"`
+	Relations     []RelationModel `bson:"relations" json:"relations"`
+	Layout        *LayoutModel    `bson:"layout,omitempty" json:"layout,omitempty"`
+}
+
+type LayoutModel struct {
+	X         float64  `bson:"x" json:"x"`
+	Y         float64  `bson:"y" json:"y"`
+	Width     *float64 `bson:"width,omitempty" json:"width,omitempty"`
+	Collapsed bool     `bson:"collapsed" json:"collapsed"`
+	Color     *string  `bson:"color,omitempty" json:"color,omitempty"`
+	ZIndex    int      `bson:"z" json:"z"`
 }
 
 type ColumnModel struct {
-	Name        string  `bson:"name"`
-	Type        string  `bson:"type"`
-	PK          bool    `bson:"pk"`
-	Nullable    bool    `bson:"nullable"`
-	Description *string `bson:"description,omitempty"`
+	Name        string  `bson:"name" json:"name"`
+	Type        string  `bson:"type" json:"type"`
+	PK          bool    `bson:"pk" json:"pk"`
+	Nullable    bool    `bson:"nullable" json:"nullable"`
+	Description *string `bson:"description,omitempty" json:"description,omitempty"`
 }
 
 type RelationModel struct {
-	From string `bson:"from"`
-	To   string `bson:"to"`
-	Type string `bson:"type"`
+	From string `bson:"from" json:"from"`
+	To   string `bson:"to" json:"to"`
+	Type string `bson:"type" json:"type"`
+}
+
+type FlowNodeModel struct {
+	ID    string `bson:"id" json:"id"`
+	Type  string `bson:"type" json:"type"`
+	Label string `bson:"label" json:"label"`
+}
+
+type FlowEdgeModel struct {
+	From  string  `bson:"from" json:"from"`
+	To    string  `bson:"to" json:"to"`
+	Label *string `bson:"label,omitempty" json:"label,omitempty"`
+}
+
+type RevisionModel struct {
+	ID        string       `bson:"_id" json:"id"`
+	DiagramID string       `bson:"diagramId" json:"diagramId"`
+	Number    int          `bson:"number" json:"number"`
+	Author    string       `bson:"author" json:"author"`
+	Summary   string       `bson:"summary" json:"summary"`
+	CreatedAt time.Time    `bson:"createdAt" json:"createdAt"`
+	Snapshot  DiagramModel `bson:"snapshot" json:"snapshot"`
+}
+
+type UserModel struct {
+	ID        string    `bson:"_id" json:"id"`
+	Email     string    `bson:"email" json:"email"`
+	Password  string    `bson:"password" json:"password"`
+	Name      string    `bson:"name" json:"name"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+type WorkspaceModel struct {
+	ID        string        `bson:"_id" json:"id"`
+	Name      string        `bson:"name" json:"name"`
+	Members   []MemberModel `bson:"members" json:"members"`
+	CreatedAt time.Time     `bson:"createdAt" json:"createdAt"`
+	Version   int64         `bson:"version" json:"version"`
+}
+
+type MemberModel struct {
+	UserID string `bson:"userId" json:"userId"`
+	Role   string `bson:"role" json:"role"`
 }
@@ -4,22 +4,52 @@ import (
 	"context"
 	"diagram-server/internal/domain"
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var ErrNotFound = errors.New("diagram not found")
+var (
+	ErrNotFound        = errors.New("diagram not found")
+	ErrVersionConflict = errors.New("diagram was modified by someone else")
+	ErrDuplicateID     = errors.New("diagram already exists")
+)
+
+// AnyVersion 은 저장된 버전을 확인하지 않는다는 뜻이다. 버전 0 은 version 필드가 생기기 전의 문서다
+const AnyVersion int64 = -1
 
 type DiagramRepository interface {
+	// Save 는 새 다이어그램을 추가한다. 같은 ID 가 이미 있으면 ErrDuplicateID 를 반환한다
 	Save(ctx context.Context, d domain.Diagram) (string, error)
 	FindByID(ctx context.Context, id string) (domain.Diagram, error)
-	FindByType(ctx context.Context, dtype domain.DiagramType) ([]domain.Diagram, error)
+	// FindByType 은 viewer 가 볼 수 있는 다이어그램만 반환한다
+	FindByType(ctx context.Context, dtype domain.DiagramType, viewer Viewer) ([]domain.Diagram, error)
+	FindByWorkspace(ctx context.Context, workspace string) ([]domain.Diagram, error)
+	// List 는 q.Viewer 가 볼 수 있는 다이어그램을 한 페이지씩 반환한다
+	List(ctx context.Context, q ListQuery) (*Page, error)
+	// Search 는 제목, 설명, 테이블 이름, 컬럼 이름과 설명에서 q.Text 를 찾는다
+	Search(ctx context.Context, q SearchQuery) ([]SearchResult, error)
+	// Update 는 저장된 버전이 d.Version() 과 같을 때만 덮어쓰고 d 의 버전을 올린다.
+	// 그 사이에 다른 저장이 있었으면 ErrVersionConflict 를 반환한다.
 	Update(ctx context.Context, d domain.Diagram) error
+	// UpdateLayout 은 버전을 올리고 새 버전을 반환한다.
+	// version 이 AnyVersion 이 아니면 저장된 버전과 같을 때만 바꾸고, 다르면 ErrVersionConflict 를 반환한다.
+	UpdateLayout(ctx context.Context, id string, version int64, layouts map[string]*domain.TableLayout) (int64, error)
 	Delete(ctx context.Context, id string) error
 }
 
+// Viewer 는 목록을 요청한 사용자와 그가 속한 워크스페이스다
+type Viewer struct {
+	UserID     string
+	Workspaces []string
+}
+
 type mongoDiagramRepository struct {
 	coll *mongo.Collection
 }
@@ -38,6 +68,9 @@ func (r *mongoDiagramRepository) Save(ctx context.Context, d domain.Diagram) (st
 	}
 
 	_, err := r.coll.InsertOne(ctx, model)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", ErrDuplicateID
+	}
 	return model.ID, err
 }
 
@@ -56,8 +89,23 @@ func (r *mongoDiagramRepository) FindByID(ctx context.Context, id string) (domai
 	return model.ToEntity()
 }
 
-func (r *mongoDiagramRepository) FindByType(ctx context.Context, dtype domain.DiagramType) ([]domain.Diagram, error) {
-	cursor, err := r.coll.Find(ctx, bson.M{"dtype": string(dtype)})
+func (r *mongoDiagramRepository) FindByType(ctx context.Context, dtype domain.DiagramType, viewer Viewer) ([]domain.Diagram, error) {
+	filter := bson.M{
+		"dtype": string(dtype),
+		"$or":   visibleTo(viewer),
+	}
+
+	cursor, err := r.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	return r.decodeCursor(ctx, cursor)
+}
+
+func (r *mongoDiagramRepository) FindByWorkspace(ctx context.Context, workspace string) ([]domain.Diagram, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{"workspace": workspace})
 	if err != nil {
 		return nil, err
 	}
@@ -66,19 +114,239 @@ func (r *mongoDiagramRepository) FindByType(ctx context.Context, dtype domain.Di
 	return r.decodeCursor(ctx, cursor)
 }
 
+func (r *mongoDiagramRepository) List(ctx context.Context, q ListQuery) (*Page, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	after, err := q.decodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := 1
+	if q.Descending {
+		dir = -1
+	}
+	key := string(q.Sort)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: key, Value: dir}, {Key: "_id", Value: dir}}).
+		SetLimit(int64(q.Limit) + 1)
+
+	cursor, err := r.coll.Find(ctx, listFilter(q, after), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	diagrams, err := r.decodeCursor(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Diagrams: diagrams}
+	if len(diagrams) > q.Limit {
+		page.Diagrams = diagrams[:q.Limit]
+		page.Next = q.encodeCursor(page.Diagrams[q.Limit-1])
+	}
+	return page, nil
+}
+
+func (r *mongoDiagramRepository) Search(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"$text": bson.M{"$search": q.Text},
+		"$or":   visibleTo(q.Viewer),
+	}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(q.Limit))
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []SearchResult
+	for cursor.Next(ctx) {
+		var model struct {
+			DiagramModel `bson:",inline"`
+			Score        float64 `bson:"score"`
+		}
+		if err := cursor.Decode(&model); err != nil {
+			return nil, err
+		}
+
+		entity, err := model.DiagramModel.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResult{Diagram: entity, Score: model.Score})
+	}
+	return results, cursor.Err()
+}
+
+// listFilter 는 조회 조건과 커서 이후라는 조건을 모두 만족하는 필터다
+func listFilter(q ListQuery, after *pageCursor) bson.M {
+	conditions := bson.A{bson.M{"$or": visibleTo(q.Viewer)}}
+
+	if q.Type != "" {
+		conditions = append(conditions, bson.M{"dtype": string(q.Type)})
+	}
+	if q.Owner != "" {
+		conditions = append(conditions, bson.M{"owner": q.Owner})
+	}
+	if q.TitlePrefix != "" {
+		// ^ 로 고정한 정규식은 title 인덱스의 범위 검색으로 처리된다
+		conditions = append(conditions, bson.M{"title": bson.M{"$regex": "^" + regexp.QuoteMeta(q.TitlePrefix)}})
+	}
+	if r := timeRange(q.CreatedAfter, q.CreatedBefore); r != nil {
+		conditions = append(conditions, bson.M{"createdAt": r})
+	}
+	if r := timeRange(q.ModifiedAfter, q.ModifiedBefore); r != nil {
+		conditions = append(conditions, bson.M{"modifiedAt": r})
+	}
+
+	if after != nil {
+		key := string(q.Sort)
+		var value interface{} = after.Key
+		if q.Sort == SortByModifiedAt {
+			value, _ = time.Parse(time.RFC3339Nano, after.Key)
+		}
+
+		op := "$gt"
+		if q.Descending {
+			op = "$lt"
+		}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{key: bson.M{op: value}},
+			bson.M{key: value, "_id": bson.M{op: after.ID}},
+		}})
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+func timeRange(from, to time.Time) bson.M {
+	if from.IsZero() && to.IsZero() {
+		return nil
+	}
+
+	r := bson.M{}
+	if !from.IsZero() {
+		r["$gte"] = from
+	}
+	if !to.IsZero() {
+		r["$lt"] = to
+	}
+	return r
+}
+
 func (r *mongoDiagramRepository) Update(ctx context.Context, d domain.Diagram) error {
 	model := ToModel(d)
-	result, err := r.coll.ReplaceOne(ctx, bson.M{"_id": model.ID}, model)
+	model.Version = d.Version() + 1
 
+	// 버전 비교와 교체가 한 연산이어야 동시에 저장한 쪽 중 하나만 성공한다
+	result, err := r.coll.ReplaceOne(ctx, versionFilter(model.ID, d.Version()), model)
 	if err != nil {
 		return err
 	}
 	if result.MatchedCount == 0 {
-		return ErrNotFound
+		return r.missOrConflict(ctx, model.ID)
 	}
+
+	d.SetVersion(model.Version)
 	return nil
 }
 
+// visibleTo 는 사용자가 소유했거나, 공유받았거나, 속한 워크스페이스의 다이어그램 조건이다
+func visibleTo(v Viewer) bson.A {
+	conditions := bson.A{
+		bson.M{"owner": v.UserID},
+		bson.M{"acl.userId": v.UserID},
+	}
+	if len(v.Workspaces) > 0 {
+		conditions = append(conditions, bson.M{"workspace": bson.M{"$in": v.Workspaces}})
+	}
+	return conditions
+}
+
+// versionFilter 는 version 필드가 생기기 전에 저장된 문서를 버전 0 으로 본다
+func versionFilter(id string, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$in": bson.A{0, nil}}}
+	}
+	return bson.M{"_id": id, "version": version}
+}
+
+func (r *mongoDiagramRepository) missOrConflict(ctx context.Context, id string) error {
+	count, err := r.coll.CountDocuments(ctx, bson.M{"_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+// UpdateLayout 은 테이블의 layout 필드만 바꾼다. 스키마나 modifiedAt 은 건드리지 않는다
+func (r *mongoDiagramRepository) UpdateLayout(ctx context.Context, id string, version int64, layouts map[string]*domain.TableLayout) (int64, error) {
+	names := make([]string, 0, len(layouts))
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set, unset := bson.M{}, bson.M{}
+	filters := make([]interface{}, len(names))
+	for i, name := range names {
+		ident := fmt.Sprintf("t%d", i)
+		field := "tables.$[" + ident + "].layout"
+		if layout := toLayoutModel(layouts[name]); layout != nil {
+			set[field] = layout
+		} else {
+			unset[field] = ""
+		}
+		filters[i] = bson.M{ident + ".name": name}
+	}
+
+	update := bson.M{"$inc": bson.M{"version": 1}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var model struct {
+		Version int64 `bson:"version"`
+	}
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"version": 1}).
+		SetReturnDocument(options.After)
+	if len(filters) > 0 {
+		opts.SetArrayFilters(options.ArrayFilters{Filters: filters})
+	}
+
+	filter := bson.M{"_id": id}
+	if version != AnyVersion {
+		filter = versionFilter(id, version)
+	}
+
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&model)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, r.missOrConflict(ctx, id)
+	}
+	return model.Version, err
+}
+
 func (r *mongoDiagramRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil {
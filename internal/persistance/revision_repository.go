@@ -0,0 +1,108 @@
+package persistance
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// 동시에 저장되어 같은 번호를 잡으면 다시 시도한다
+const maxRevisionRetries = 5
+
+type RevisionRepository interface {
+	// Save 는 다음 번호를 매겨 리비전을 추가하고 그 번호를 rev.Number 에 기록한다
+	Save(ctx context.Context, rev *domain.Revision) error
+	FindByDiagram(ctx context.Context, diagramID string) ([]*domain.Revision, error)
+	FindByNumber(ctx context.Context, diagramID string, number int) (*domain.Revision, error)
+	DeleteByDiagram(ctx context.Context, diagramID string) error
+}
+
+type mongoRevisionRepository struct {
+	coll *mongo.Collection
+}
+
+func NewRevisionRepository(db *mongo.Database) RevisionRepository {
+	return &mongoRevisionRepository{
+		coll: db.Collection("diagram_revisions"),
+	}
+}
+
+// _id 를 "diagramId:number" 로 두어 번호가 겹치면 InsertOne 이 중복 키로 실패하게 한다
+func (r *mongoRevisionRepository) Save(ctx context.Context, rev *domain.Revision) error {
+	for attempt := 0; ; attempt++ {
+		latest, err := r.latestNumber(ctx, rev.DiagramID)
+		if err != nil {
+			return err
+		}
+		rev.Number = latest + 1
+
+		_, err = r.coll.InsertOne(ctx, toRevisionModel(rev))
+		if err == nil || !mongo.IsDuplicateKeyError(err) || attempt == maxRevisionRetries {
+			return err
+		}
+	}
+}
+
+func (r *mongoRevisionRepository) latestNumber(ctx context.Context, diagramID string) (int, error) {
+	var model struct {
+		Number int `bson:"number"`
+	}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "number", Value: -1}}).
+		SetProjection(bson.M{"number": 1})
+
+	err := r.coll.FindOne(ctx, bson.M{"diagramId": diagramID}, opts).Decode(&model)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return model.Number, err
+}
+
+func (r *mongoRevisionRepository) FindByDiagram(ctx context.Context, diagramID string) ([]*domain.Revision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: 1}})
+	cursor, err := r.coll.Find(ctx, bson.M{"diagramId": diagramID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []*domain.Revision
+	for cursor.Next(ctx) {
+		var model RevisionModel
+		if err := cursor.Decode(&model); err != nil {
+			return nil, err
+		}
+
+		rev, err := model.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rev)
+	}
+	return results, cursor.Err()
+}
+
+func (r *mongoRevisionRepository) FindByNumber(ctx context.Context, diagramID string, number int) (*domain.Revision, error) {
+	var model RevisionModel
+	err := r.coll.FindOne(ctx, bson.M{"diagramId": diagramID, "number": number}).Decode(&model)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToEntity()
+}
+
+func (r *mongoRevisionRepository) DeleteByDiagram(ctx context.Context, diagramID string) error {
+	_, err := r.coll.DeleteMany(ctx, bson.M{"diagramId": diagramID})
+	return err
+}
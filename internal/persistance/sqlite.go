@@ -0,0 +1,690 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"diagram-server/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLite 저장소는 MongoDB 저장소와 같은 동작을 한다. 테이블 구조는 sqliteMigrations 에 있다.
+// 시각은 밀리초 단위 정수로 저장해 MongoDB 와 같은 정밀도를 갖는다.
+
+type sqliteDiagramRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteDiagramRepository(db *sql.DB) DiagramRepository {
+	return &sqliteDiagramRepository{db: db}
+}
+
+func (r *sqliteDiagramRepository) Save(ctx context.Context, d domain.Diagram) (string, error) {
+	model := ToModel(d)
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+	storeTimes(model)
+
+	doc, err := json.Marshal(model)
+	if err != nil {
+		return "", err
+	}
+
+	err = withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO diagrams
+			(id, dtype, title, owner, workspace, created_at, modified_at, version, document)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			model.ID, model.Dtype, model.Title, model.Owner, model.Workspace,
+			model.CreatedAt.UnixMilli(), model.ModifiedAt.UnixMilli(), model.Version, string(doc))
+		if err != nil {
+			return err
+		}
+		return writeIndexes(ctx, tx, model)
+	})
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+		return "", ErrDuplicateID
+	}
+	if err != nil {
+		return "", err
+	}
+	return model.ID, nil
+}
+
+func (r *sqliteDiagramRepository) FindByID(ctx context.Context, id string) (domain.Diagram, error) {
+	var doc string
+	err := r.db.QueryRowContext(ctx, `SELECT document FROM diagrams WHERE id = ?`, id).Scan(&doc)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeDocument(doc)
+}
+
+func (r *sqliteDiagramRepository) FindByType(ctx context.Context, dtype domain.DiagramType, viewer Viewer) ([]domain.Diagram, error) {
+	visible, args := visibleSQL(viewer)
+	return r.query(ctx, `SELECT d.document FROM diagrams d WHERE d.dtype = ? AND `+visible+` ORDER BY d.rowid`,
+		append([]any{string(dtype)}, args...)...)
+}
+
+func (r *sqliteDiagramRepository) FindByWorkspace(ctx context.Context, workspace string) ([]domain.Diagram, error) {
+	return r.query(ctx, `SELECT document FROM diagrams WHERE workspace = ? AND workspace != '' ORDER BY rowid`, workspace)
+}
+
+func (r *sqliteDiagramRepository) List(ctx context.Context, q ListQuery) (*Page, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	after, err := q.decodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	visible, args := visibleSQL(q.Viewer)
+	where := []string{visible}
+
+	if q.Type != "" {
+		where = append(where, "d.dtype = ?")
+		args = append(args, string(q.Type))
+	}
+	if q.Owner != "" {
+		where = append(where, "d.owner = ?")
+		args = append(args, q.Owner)
+	}
+	if q.TitlePrefix != "" {
+		// 앞의 비교는 title 인덱스의 범위 검색에 쓰이고, 뒤의 비교가 접두사인지 확인한다
+		where = append(where, "d.title >= ? AND substr(d.title, 1, ?) = ?")
+		args = append(args, q.TitlePrefix, utf8.RuneCountInString(q.TitlePrefix), q.TitlePrefix)
+	}
+	where, args = appendRange(where, args, "d.created_at", q.CreatedAfter, q.CreatedBefore)
+	where, args = appendRange(where, args, "d.modified_at", q.ModifiedAfter, q.ModifiedBefore)
+
+	key := "d.modified_at"
+	if q.Sort == SortByTitle {
+		key = "d.title"
+	}
+	dir, op := "ASC", ">"
+	if q.Descending {
+		dir, op = "DESC", "<"
+	}
+
+	if after != nil {
+		var value any = after.Key
+		if q.Sort == SortByModifiedAt {
+			t, _ := time.Parse(time.RFC3339Nano, after.Key)
+			value = t.UnixMilli()
+		}
+		where = append(where, fmt.Sprintf("(%s %s ? OR (%s = ? AND d.id %s ?))", key, op, key, op))
+		args = append(args, value, value, after.ID)
+	}
+
+	query := fmt.Sprintf(`SELECT d.document FROM diagrams d WHERE %s ORDER BY %s %s, d.id %s LIMIT ?`,
+		strings.Join(where, " AND "), key, dir, dir)
+	diagrams, err := r.query(ctx, query, append(args, q.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Diagrams: diagrams}
+	if len(diagrams) > q.Limit {
+		page.Diagrams = diagrams[:q.Limit]
+		page.Next = q.encodeCursor(page.Diagrams[q.Limit-1])
+	}
+	return page, nil
+}
+
+func appendRange(where []string, args []any, column string, from, to time.Time) ([]string, []any) {
+	if !from.IsZero() {
+		where = append(where, column+" >= ?")
+		args = append(args, from.UnixMilli())
+	}
+	if !to.IsZero() {
+		where = append(where, column+" < ?")
+		args = append(args, to.UnixMilli())
+	}
+	return where, args
+}
+
+// Search 는 diagram_search 에서 찾는다. bm25 의 가중치는 search_text 인덱스와 같다
+func (r *sqliteDiagramRepository) Search(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	match := ftsQuery(q.Text)
+	if match == "" {
+		return nil, nil
+	}
+
+	visible, args := visibleSQL(q.Viewer)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.document, -bm25(diagram_search, 0.0, 10.0, 1.0, 5.0, 3.0, 1.0) AS score
+		FROM diagram_search JOIN diagrams d ON d.id = diagram_search.diagram_id
+		WHERE diagram_search MATCH ? AND `+visible+`
+		ORDER BY score DESC, d.id
+		LIMIT ?`,
+		append(append([]any{match}, args...), q.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var doc string
+		var score float64
+		if err := rows.Scan(&doc, &score); err != nil {
+			return nil, err
+		}
+		entity, err := decodeDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResult{Diagram: entity, Score: score})
+	}
+	return results, rows.Err()
+}
+
+// ftsQuery 는 MongoDB 의 $search 문법을 FTS5 질의로 바꾼다. 낱말 중 하나라도 있으면 일치하고 제외어가 있으면 뺀다
+func ftsQuery(text string) string {
+	include, exclude := searchTerms(text)
+	if len(include) == 0 {
+		return ""
+	}
+
+	quote := func(terms []string) string {
+		quoted := make([]string, len(terms))
+		for i, t := range terms {
+			quoted[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+		}
+		return "(" + strings.Join(quoted, " OR ") + ")"
+	}
+
+	q := quote(include)
+	if len(exclude) > 0 {
+		q += " NOT " + quote(exclude)
+	}
+	return q
+}
+
+func (r *sqliteDiagramRepository) Update(ctx context.Context, d domain.Diagram) error {
+	model := ToModel(d)
+	model.Version = d.Version() + 1
+	storeTimes(model)
+
+	doc, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	err = withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// 버전 비교와 교체가 한 문장이어야 동시에 저장한 쪽 중 하나만 성공한다
+		result, err := tx.ExecContext(ctx, `UPDATE diagrams
+			SET dtype = ?, title = ?, owner = ?, workspace = ?, created_at = ?, modified_at = ?, version = ?, document = ?
+			WHERE id = ? AND version = ?`,
+			model.Dtype, model.Title, model.Owner, model.Workspace,
+			model.CreatedAt.UnixMilli(), model.ModifiedAt.UnixMilli(), model.Version, string(doc),
+			model.ID, d.Version())
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			if err != nil {
+				return err
+			}
+			return missOrConflict(ctx, tx, model.ID)
+		}
+		return writeIndexes(ctx, tx, model)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetVersion(model.Version)
+	return nil
+}
+
+func missOrConflict(ctx context.Context, tx *sql.Tx, id string) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM diagrams WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+// UpdateLayout 은 테이블의 layout 만 바꾼다. 스키마나 modifiedAt 은 건드리지 않는다
+func (r *sqliteDiagramRepository) UpdateLayout(ctx context.Context, id string, version int64, layouts map[string]*domain.TableLayout) (int64, error) {
+	var next int64
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var doc string
+		err := tx.QueryRowContext(ctx, `SELECT document FROM diagrams WHERE id = ?`, id).Scan(&doc)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var model DiagramModel
+		if err := json.Unmarshal([]byte(doc), &model); err != nil {
+			return err
+		}
+		if version != AnyVersion && model.Version != version {
+			return ErrVersionConflict
+		}
+		for i := range model.Tables {
+			if l, ok := layouts[model.Tables[i].Name]; ok {
+				model.Tables[i].Layout = toLayoutModel(l)
+			}
+		}
+		model.Version++
+
+		updated, err := json.Marshal(model)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE diagrams SET version = ?, document = ? WHERE id = ?`, model.Version, string(updated), id)
+		next = model.Version
+		return err
+	})
+	return next, err
+}
+
+func (r *sqliteDiagramRepository) Delete(ctx context.Context, id string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM diagrams WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			if err != nil {
+				return err
+			}
+			return ErrNotFound
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM diagram_search WHERE diagram_id = ?`, id)
+		return err
+	})
+}
+
+func (r *sqliteDiagramRepository) query(ctx context.Context, query string, args ...any) ([]domain.Diagram, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Diagram
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		entity, err := decodeDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, entity)
+	}
+	return results, rows.Err()
+}
+
+func decodeDocument(doc string) (domain.Diagram, error) {
+	var model DiagramModel
+	if err := json.Unmarshal([]byte(doc), &model); err != nil {
+		return nil, err
+	}
+	return model.ToEntity()
+}
+
+// visibleSQL 은 visibleTo 와 같은 조건이다. 다이어그램 테이블의 별칭은 d 여야 한다
+func visibleSQL(v Viewer) (string, []any) {
+	cond := "(d.owner = ? OR EXISTS (SELECT 1 FROM diagram_acl a WHERE a.diagram_id = d.id AND a.user_id = ?)"
+	args := []any{v.UserID, v.UserID}
+	if len(v.Workspaces) > 0 {
+		cond += " OR d.workspace IN (?" + strings.Repeat(", ?", len(v.Workspaces)-1) + ")"
+		for _, w := range v.Workspaces {
+			args = append(args, w)
+		}
+	}
+	return cond + ")", args
+}
+
+// writeIndexes 는 권한과 검색용 행을 문서와 맞춘다
+func writeIndexes(ctx context.Context, tx *sql.Tx, m *DiagramModel) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM diagram_acl WHERE diagram_id = ?`, m.ID); err != nil {
+		return err
+	}
+	for _, g := range m.ACL {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO diagram_acl (diagram_id, user_id, role) VALUES (?, ?, ?)`, m.ID, g.UserID, g.Role); err != nil {
+			return err
+		}
+	}
+
+	var description string
+	if m.Description != nil {
+		description = *m.Description
+	}
+	var tables, columns, columnDescriptions []string
+	for _, t := range m.Tables {
+		tables = append(tables, t.Name)
+		for _, c := range t.Columns {
+			columns = append(columns, c.Name)
+			if c.Description != nil {
+				columnDescriptions = append(columnDescriptions, *c.Description)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM diagram_search WHERE diagram_id = ?`, m.ID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO diagram_search
+		(diagram_id, title, description, tables, columns, column_descriptions)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		m.ID, m.Title, description,
+		strings.Join(tables, " "), strings.Join(columns, " "), strings.Join(columnDescriptions, "\n"))
+	return err
+}
+
+type sqliteRevisionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRevisionRepository(db *sql.DB) RevisionRepository {
+	return &sqliteRevisionRepository{db: db}
+}
+
+// Save 는 쓰기 잠금을 잡은 트랜잭션 안에서 번호를 매기므로 번호가 겹치지 않는다
+func (r *sqliteRevisionRepository) Save(ctx context.Context, rev *domain.Revision) error {
+	snapshot := ToModel(rev.Diagram)
+	storeTimes(snapshot)
+	doc, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var number int
+		err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(number), 0) + 1 FROM diagram_revisions WHERE diagram_id = ?`, rev.DiagramID).Scan(&number)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO diagram_revisions
+			(diagram_id, number, author, summary, created_at, snapshot)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			rev.DiagramID, number, rev.Author, rev.Summary, rev.CreatedAt.UnixMilli(), string(doc))
+		if err != nil {
+			return err
+		}
+		rev.Number = number
+		return nil
+	})
+}
+
+func (r *sqliteRevisionRepository) FindByDiagram(ctx context.Context, diagramID string) ([]*domain.Revision, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT number, author, summary, created_at, snapshot
+		FROM diagram_revisions WHERE diagram_id = ? ORDER BY number`, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domain.Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows, diagramID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rev)
+	}
+	return results, rows.Err()
+}
+
+func (r *sqliteRevisionRepository) FindByNumber(ctx context.Context, diagramID string, number int) (*domain.Revision, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT number, author, summary, created_at, snapshot
+		FROM diagram_revisions WHERE diagram_id = ? AND number = ?`, diagramID, number)
+
+	rev, err := scanRevision(row, diagramID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+func (r *sqliteRevisionRepository) DeleteByDiagram(ctx context.Context, diagramID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM diagram_revisions WHERE diagram_id = ?`, diagramID)
+	return err
+}
+
+func scanRevision(row interface{ Scan(...any) error }, diagramID string) (*domain.Revision, error) {
+	model := RevisionModel{DiagramID: diagramID}
+	var createdAt int64
+	var snapshot string
+	if err := row.Scan(&model.Number, &model.Author, &model.Summary, &createdAt, &snapshot); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(snapshot), &model.Snapshot); err != nil {
+		return nil, err
+	}
+
+	model.ID = fmt.Sprintf("%s:%d", diagramID, model.Number)
+	model.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return model.ToEntity()
+}
+
+type sqliteUserRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUserRepository(db *sql.DB) UserRepository {
+	return &sqliteUserRepository{db: db}
+}
+
+// 이메일 유일성은 users 테이블의 UNIQUE 제약이 보장한다
+func (r *sqliteUserRepository) Save(ctx context.Context, u *domain.User) (string, error) {
+	model := toUserModel(u)
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO users (id, email, password, name, created_at) VALUES (?, ?, ?, ?, ?)`,
+		model.ID, model.Email, model.Password, model.Name, model.CreatedAt.UnixMilli())
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return "", ErrEmailTaken
+	}
+	if err != nil {
+		return "", err
+	}
+	return model.ID, nil
+}
+
+func (r *sqliteUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(ctx, `id = ?`, id)
+}
+
+func (r *sqliteUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(ctx, `email = ?`, domain.NormalizeEmail(email))
+}
+
+func (r *sqliteUserRepository) findOne(ctx context.Context, where string, arg any) (*domain.User, error) {
+	var model UserModel
+	var createdAt int64
+	err := r.db.QueryRowContext(ctx, `SELECT id, email, password, name, created_at FROM users WHERE `+where, arg).
+		Scan(&model.ID, &model.Email, &model.Password, &model.Name, &createdAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	model.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return model.ToEntity(), nil
+}
+
+type sqliteWorkspaceRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWorkspaceRepository(db *sql.DB) WorkspaceRepository {
+	return &sqliteWorkspaceRepository{db: db}
+}
+
+func (r *sqliteWorkspaceRepository) Save(ctx context.Context, w *domain.Workspace) (string, error) {
+	model := toWorkspaceModel(w)
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO workspaces (id, name, created_at, version) VALUES (?, ?, ?, ?)`,
+			model.ID, model.Name, model.CreatedAt.UnixMilli(), model.Version)
+		if err != nil {
+			return err
+		}
+		return writeMembers(ctx, tx, model)
+	})
+	return model.ID, err
+}
+
+func (r *sqliteWorkspaceRepository) FindByID(ctx context.Context, id string) (*domain.Workspace, error) {
+	var model WorkspaceModel
+	var createdAt int64
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, created_at, version FROM workspaces WHERE id = ?`, id).
+		Scan(&model.ID, &model.Name, &createdAt, &model.Version)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	model.CreatedAt = time.UnixMilli(createdAt).UTC()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, role FROM workspace_members WHERE workspace_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m MemberModel
+		if err := rows.Scan(&m.UserID, &m.Role); err != nil {
+			return nil, err
+		}
+		model.Members = append(model.Members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return model.ToEntity(), nil
+}
+
+// FindByMember 는 사용자가 속한 워크스페이스와 그 구성원을 한 번에 읽는다
+func (r *sqliteWorkspaceRepository) FindByMember(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT w.id, w.name, w.created_at, w.version, m.user_id, m.role
+		FROM workspace_members me
+		JOIN workspaces w ON w.id = me.workspace_id
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE me.user_id = ? ORDER BY w.rowid, m.position`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var models []*WorkspaceModel
+	for rows.Next() {
+		var model WorkspaceModel
+		var createdAt int64
+		var member MemberModel
+		if err := rows.Scan(&model.ID, &model.Name, &createdAt, &model.Version, &member.UserID, &member.Role); err != nil {
+			return nil, err
+		}
+		// 같은 워크스페이스의 구성원은 연달아 나온다
+		if n := len(models); n == 0 || models[n-1].ID != model.ID {
+			model.CreatedAt = time.UnixMilli(createdAt).UTC()
+			models = append(models, &model)
+		}
+		last := models[len(models)-1]
+		last.Members = append(last.Members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]*domain.Workspace, len(models))
+	for i, model := range models {
+		workspaces[i] = model.ToEntity()
+	}
+	return workspaces, nil
+}
+
+func (r *sqliteWorkspaceRepository) Update(ctx context.Context, w *domain.Workspace) error {
+	model := toWorkspaceModel(w)
+	model.Version = w.Version() + 1
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// 동시에 구성원을 바꾼 쪽 중 하나만 성공해야 다른 쪽의 변경을 덮어쓰지 않는다
+		result, err := tx.ExecContext(ctx, `UPDATE workspaces SET name = ?, version = ? WHERE id = ? AND version = ?`,
+			model.Name, model.Version, model.ID, w.Version())
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			if err != nil {
+				return err
+			}
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM workspaces WHERE id = ?)`, model.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				return ErrWorkspaceNotFound
+			}
+			return ErrWorkspaceConflict
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace_id = ?`, model.ID); err != nil {
+			return err
+		}
+		return writeMembers(ctx, tx, model)
+	})
+	if err != nil {
+		return err
+	}
+
+	w.SetVersion(model.Version)
+	return nil
+}
+
+func writeMembers(ctx context.Context, tx *sql.Tx, m *WorkspaceModel) error {
+	for i, member := range m.Members {
+		_, err := tx.ExecContext(ctx, `INSERT INTO workspace_members (workspace_id, position, user_id, role) VALUES (?, ?, ?, ?)`,
+			m.ID, i, member.UserID, member.Role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sqliteMigrations 는 순서대로 한 번씩 적용된다. 이미 배포된 항목은 고치지 말고 새 항목을 덧붙인다.
+// 다이어그램은 document 에 DiagramModel 의 JSON 을 그대로 두고, 조회 조건에 쓰는 값만 열로 꺼내 둔다.
+var sqliteMigrations = []string{
+	// 1: 다이어그램, 권한, 리비전
+	`CREATE TABLE diagrams (
+		id          TEXT PRIMARY KEY,
+		dtype       TEXT NOT NULL,
+		title       TEXT NOT NULL,
+		owner       TEXT NOT NULL,
+		workspace   TEXT NOT NULL DEFAULT '',
+		created_at  INTEGER NOT NULL,
+		modified_at INTEGER NOT NULL,
+		version     INTEGER NOT NULL,
+		document    TEXT NOT NULL
+	);
+	CREATE TABLE diagram_acl (
+		diagram_id TEXT NOT NULL REFERENCES diagrams(id) ON DELETE CASCADE,
+		user_id    TEXT NOT NULL,
+		role       TEXT NOT NULL,
+		PRIMARY KEY (diagram_id, user_id)
+	);
+	CREATE TABLE diagram_revisions (
+		diagram_id TEXT NOT NULL,
+		number     INTEGER NOT NULL,
+		author     TEXT NOT NULL,
+		summary    TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		snapshot   TEXT NOT NULL,
+		PRIMARY KEY (diagram_id, number)
+	);
+	CREATE INDEX diagrams_owner_dtype ON diagrams (owner, dtype);
+	CREATE INDEX diagrams_workspace_dtype ON diagrams (workspace, dtype);
+	CREATE INDEX diagrams_owner_modified ON diagrams (owner, modified_at, id);
+	CREATE INDEX diagrams_owner_title ON diagrams (owner, title, id);
+	CREATE INDEX diagrams_workspace_modified ON diagrams (workspace, modified_at, id);
+	CREATE INDEX diagrams_workspace_title ON diagrams (workspace, title, id);
+	CREATE INDEX diagram_acl_user ON diagram_acl (user_id, diagram_id);`,
+
+	// 2: 사용자와 워크스페이스
+	`CREATE TABLE users (
+		id         TEXT PRIMARY KEY,
+		email      TEXT NOT NULL UNIQUE,
+		password   TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE TABLE workspaces (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE TABLE workspace_members (
+		workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+		position     INTEGER NOT NULL,
+		user_id      TEXT NOT NULL,
+		role         TEXT NOT NULL,
+		PRIMARY KEY (workspace_id, user_id)
+	);
+	CREATE INDEX workspace_members_user ON workspace_members (user_id, workspace_id);`,
+
+	// 3: 전문 검색. search_text 인덱스와 같은 필드와 가중치를 쓰고, customer_id 처럼 _ 가 든 식별자는 한 낱말로 본다
+	`CREATE VIRTUAL TABLE diagram_search USING fts5(
+		diagram_id UNINDEXED,
+		title,
+		description,
+		tables,
+		columns,
+		column_descriptions,
+		tokenize = "unicode61 tokenchars '_'"
+	);`,
+
+	// 4: 워크스페이스 버전. 이전에 만든 워크스페이스는 Mongo 의 version 없는 문서처럼 0 에서 시작한다
+	`ALTER TABLE workspaces ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// MigrateSQLite 는 애플리케이션 시작 시 적용되지 않은 마이그레이션을 하나의 트랜잭션씩 적용한다.
+// 여러 프로세스가 함께 시작해도 쓰기 잠금을 잡은 뒤에 버전을 읽으므로 같은 마이그레이션을 두 번 적용하지 않는다.
+func MigrateSQLite(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	// BEGIN IMMEDIATE 를 직접 보내므로 DSN 의 _txlock 과 관계없이 같은 연결에서 잠그고 적용한다
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for {
+		applied, err := migrateNext(ctx, conn)
+		if err != nil || !applied {
+			return err
+		}
+	}
+}
+
+// migrateNext 는 쓰기 잠금을 잡고 현재 버전을 읽어 다음 마이그레이션 하나를 적용한다. 적용할 것이 없으면 false 다
+func migrateNext(ctx context.Context, conn *sql.Conn) (applied bool, err error) {
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_, _ = conn.ExecContext(context.Background(), `ROLLBACK`)
+		}
+	}()
+
+	var current int
+	if err := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return false, err
+	}
+	if current >= len(sqliteMigrations) {
+		_, err := conn.ExecContext(ctx, `COMMIT`)
+		return false, err
+	}
+
+	version := current + 1
+	if _, err := conn.ExecContext(ctx, sqliteMigrations[current]); err != nil {
+		return false, fmt.Errorf("migration %d: %w", version, err)
+	}
+	if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UnixMilli()); err != nil {
+		return false, fmt.Errorf("migration %d: %w", version, err)
+	}
+	if _, err := conn.ExecContext(ctx, `COMMIT`); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// withTx 는 fn 이 오류를 반환하면 롤백한다
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
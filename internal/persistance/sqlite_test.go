@@ -0,0 +1,132 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"diagram-server/internal/domain"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := MigrateSQLite(context.Background(), db); err != nil {
+		t.Fatalf("MigrateSQLite() error = %v", err)
+	}
+	return db
+}
+
+func TestSQLiteDiagramRepository(t *testing.T) {
+	testDiagramRepository(t, NewSQLiteDiagramRepository(openSQLite(t)))
+}
+
+func TestSQLiteDiagramRepository_List(t *testing.T) {
+	testDiagramRepositoryList(t, NewSQLiteDiagramRepository(openSQLite(t)))
+}
+
+func TestSQLiteDiagramRepository_Search(t *testing.T) {
+	testDiagramRepositorySearch(t, NewSQLiteDiagramRepository(openSQLite(t)))
+}
+
+func TestSQLiteUserRepository_EmailTaken(t *testing.T) {
+	testUserRepositoryEmailTaken(t, NewSQLiteUserRepository(openSQLite(t)))
+}
+
+func TestSQLiteWorkspaceRepository(t *testing.T) {
+	testWorkspaceRepository(t, NewSQLiteWorkspaceRepository(openSQLite(t)))
+}
+
+func TestMigrateSQLite_Idempotent(t *testing.T) {
+	db := openSQLite(t)
+
+	if err := MigrateSQLite(context.Background(), db); err != nil {
+		t.Fatalf("MigrateSQLite() again error = %v", err)
+	}
+
+	var version int
+	db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if version != len(sqliteMigrations) {
+		t.Errorf("version = %d, want %d", version, len(sqliteMigrations))
+	}
+}
+
+func TestMigrateSQLite_Concurrent(t *testing.T) {
+	// 같은 파일을 연 여러 프로세스가 함께 시작하는 상황
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := sql.Open("sqlite", dsn)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer db.Close()
+			errs <- MigrateSQLite(context.Background(), db)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("MigrateSQLite() error = %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var applied int
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied)
+	if applied != len(sqliteMigrations) {
+		t.Errorf("applied = %d, want %d", applied, len(sqliteMigrations))
+	}
+}
+
+func TestSQLiteRevisionRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	diagrams := NewSQLiteDiagramRepository(db)
+	revisions := NewSQLiteRevisionRepository(db)
+
+	id := saveERD(t, diagrams, "Orders", "alice")
+	d, _ := diagrams.FindByID(ctx, id)
+
+	for i := 1; i <= 2; i++ {
+		rev := domain.NewRevision(d, "alice", "저장")
+		if err := revisions.Save(ctx, rev); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if rev.Number != i {
+			t.Errorf("Number = %d, want %d", rev.Number, i)
+		}
+	}
+
+	got, err := revisions.FindByNumber(ctx, id, 2)
+	if err != nil {
+		t.Fatalf("FindByNumber() error = %v", err)
+	}
+	if got.Diagram.(*domain.ERDiagram).Title() != "Orders" {
+		t.Errorf("snapshot title = %q, want %q", got.Diagram.(*domain.ERDiagram).Title(), "Orders")
+	}
+	if _, err := revisions.FindByNumber(ctx, id, 3); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("FindByNumber() error = %v, want %v", err, ErrRevisionNotFound)
+	}
+}
@@ -0,0 +1,70 @@
+package persistance
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailTaken   = errors.New("email is already registered")
+)
+
+type UserRepository interface {
+	// Save 는 새 사용자를 추가한다. 같은 이메일이 있으면 ErrEmailTaken 을 반환한다
+	Save(ctx context.Context, u *domain.User) (string, error)
+	FindByID(ctx context.Context, id string) (*domain.User, error)
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+}
+
+type mongoUserRepository struct {
+	coll *mongo.Collection
+}
+
+func NewUserRepository(db *mongo.Database) UserRepository {
+	return &mongoUserRepository{
+		coll: db.Collection("users"),
+	}
+}
+
+// 이메일 유일성은 users 컬렉션의 유니크 인덱스가 보장한다 (EnsureIndexes)
+func (r *mongoUserRepository) Save(ctx context.Context, u *domain.User) (string, error) {
+	model := toUserModel(u)
+
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+
+	_, err := r.coll.InsertOne(ctx, model)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", ErrEmailTaken
+	}
+	return model.ID, err
+}
+
+func (r *mongoUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"email": domain.NormalizeEmail(email)})
+}
+
+func (r *mongoUserRepository) findOne(ctx context.Context, filter bson.M) (*domain.User, error) {
+	var model UserModel
+	err := r.coll.FindOne(ctx, filter).Decode(&model)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToEntity(), nil
+}
@@ -0,0 +1,103 @@
+package persistance
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceConflict = errors.New("workspace was modified by someone else")
+)
+
+type WorkspaceRepository interface {
+	Save(ctx context.Context, w *domain.Workspace) (string, error)
+	FindByID(ctx context.Context, id string) (*domain.Workspace, error)
+	// FindByMember 는 사용자가 구성원인 워크스페이스를 반환한다
+	FindByMember(ctx context.Context, userID string) ([]*domain.Workspace, error)
+	// Update 는 저장된 버전이 w 의 버전과 같을 때만 저장하고 버전을 올린다. 다르면 ErrWorkspaceConflict 다
+	Update(ctx context.Context, w *domain.Workspace) error
+}
+
+type mongoWorkspaceRepository struct {
+	coll *mongo.Collection
+}
+
+func NewWorkspaceRepository(db *mongo.Database) WorkspaceRepository {
+	return &mongoWorkspaceRepository{
+		coll: db.Collection("workspaces"),
+	}
+}
+
+func (r *mongoWorkspaceRepository) Save(ctx context.Context, w *domain.Workspace) (string, error) {
+	model := toWorkspaceModel(w)
+
+	if model.ID == "" {
+		model.ID = primitive.NewObjectID().Hex()
+	}
+
+	_, err := r.coll.InsertOne(ctx, model)
+	return model.ID, err
+}
+
+func (r *mongoWorkspaceRepository) FindByID(ctx context.Context, id string) (*domain.Workspace, error) {
+	var model WorkspaceModel
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&model)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToEntity(), nil
+}
+
+func (r *mongoWorkspaceRepository) FindByMember(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{"members.userId": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var workspaces []*domain.Workspace
+	for cursor.Next(ctx) {
+		var model WorkspaceModel
+		if err := cursor.Decode(&model); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, model.ToEntity())
+	}
+	return workspaces, cursor.Err()
+}
+
+func (r *mongoWorkspaceRepository) Update(ctx context.Context, w *domain.Workspace) error {
+	model := toWorkspaceModel(w)
+	model.Version = w.Version() + 1
+
+	// 동시에 구성원을 바꾼 쪽 중 하나만 성공해야 다른 쪽의 변경을 덮어쓰지 않는다
+	result, err := r.coll.ReplaceOne(ctx, versionFilter(model.ID, w.Version()), model)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := r.coll.CountDocuments(ctx, bson.M{"_id": model.ID}, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrWorkspaceNotFound
+		}
+		return ErrWorkspaceConflict
+	}
+
+	w.SetVersion(model.Version)
+	return nil
+}
@@ -0,0 +1,290 @@
+package render
+
+import (
+	"diagram-server/internal/domain"
+	"diagram-server/internal/layout"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	fontSize     = 12
+	charWidth    = 7.2
+	headerHeight = 28.0
+	rowHeight    = 22.0
+	padding      = 10.0
+	iconWidth    = 18.0
+	minWidth     = 160.0
+	detour       = 40.0
+	glyphSize    = 10.0
+)
+
+type box struct {
+	table *domain.Table
+	x, y  float64
+	w, h  float64
+}
+
+func (b *box) right() float64  { return b.x + b.w }
+func (b *box) bottom() float64 { return b.y + b.h }
+
+func (b *box) collapsed() bool {
+	return b.table.Layout != nil && b.table.Layout.Collapsed
+}
+
+// rowY 는 컬럼 행의 세로 중심 좌표를 반환한다. 컬럼이 없거나 접힌 테이블이면 헤더 중심이다
+func (b *box) rowY(column string) float64 {
+	if b.table.Columns != nil && !b.collapsed() {
+		for i, c := range *b.table.Columns {
+			if strings.EqualFold(c.Name, column) {
+				return b.y + headerHeight + rowHeight*float64(i) + rowHeight/2
+			}
+		}
+	}
+	return b.y + headerHeight/2
+}
+
+// Layout 은 SVG 에 그려질 크기로 테이블 배치를 계산한다
+func Layout(erd *domain.ERDiagram) *layout.Result {
+	_, result := arrange(erd.Tables)
+	return result
+}
+
+// SVG 는 ERDiagram 을 독립 실행 가능한 SVG 문서로 그린다
+func SVG(erd *domain.ERDiagram) string {
+	boxes, result := arrange(erd.Tables)
+	width, height := result.Width, result.Height
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="monospace" font-size="%d">`+"\n",
+		num(width), num(height), num(width), num(height), fontSize)
+	fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(erd.Title()))
+	sb.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>` + "\n")
+
+	for _, t := range erd.Tables {
+		if t.Relations == nil {
+			continue
+		}
+		for _, r := range *t.Relations {
+			writeRelation(&sb, erd.Tables, boxes, r)
+		}
+	}
+
+	// z 값이 큰 테이블이 위에 그려진다
+	ordered := append([]*box(nil), boxes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return zIndex(ordered[i].table) < zIndex(ordered[j].table)
+	})
+	for _, b := range ordered {
+		writeTable(&sb, b, foreignKeyColumns(erd.Tables, b.table))
+	}
+
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func arrange(tables []domain.Table) ([]*box, *layout.Result) {
+	boxes := measure(tables)
+	sizes := make([]layout.Size, len(boxes))
+	for i, b := range boxes {
+		sizes[i] = layout.Size{Width: b.w, Height: b.h}
+	}
+
+	result := layout.Compute(tables, sizes)
+	for i, b := range boxes {
+		b.x, b.y = result.Boxes[i].X, result.Boxes[i].Y
+	}
+	return boxes, result
+}
+
+func measure(tables []domain.Table) []*box {
+	boxes := make([]*box, len(tables))
+	for i := range tables {
+		t := &tables[i]
+		w := textWidth(t.Name) + 2*padding
+		rows := 0
+		if t.Columns != nil {
+			rows = len(*t.Columns)
+			for _, c := range *t.Columns {
+				w = math.Max(w, iconWidth+textWidth(c.Name)+2*padding+textWidth(columnType(c))+2*padding)
+			}
+		}
+		w = math.Max(minWidth, math.Ceil(w))
+
+		if t.Layout != nil {
+			if t.Layout.Width != nil && *t.Layout.Width > 0 {
+				w = *t.Layout.Width
+			}
+			if t.Layout.Collapsed {
+				rows = 0
+			}
+		}
+		boxes[i] = &box{
+			table: t,
+			w:     w,
+			h:     headerHeight + rowHeight*float64(rows),
+		}
+	}
+	return boxes
+}
+
+func zIndex(t *domain.Table) int {
+	if t.Layout == nil {
+		return 0
+	}
+	return t.Layout.ZIndex
+}
+
+func headerColor(t *domain.Table) string {
+	if t.Layout != nil && t.Layout.Color != nil && *t.Layout.Color != "" {
+		return *t.Layout.Color
+	}
+	return "#334155"
+}
+
+func writeTable(sb *strings.Builder, b *box, fkColumns map[string]bool) {
+	t := b.table
+	color := html.EscapeString(headerColor(t))
+
+	fmt.Fprintf(sb, `<g class="table" data-table="%s">`+"\n", html.EscapeString(t.Name))
+	fmt.Fprintf(sb, `<rect x="%s" y="%s" width="%s" height="%s" rx="4" fill="#ffffff" stroke="#334155"/>`+"\n",
+		num(b.x), num(b.y), num(b.w), num(b.h))
+	fmt.Fprintf(sb, `<path d="M%s,%s h%s v%s h%s z" fill="%s"/>`+"\n",
+		num(b.x), num(b.y+headerHeight), num(b.w), num(-headerHeight+4), num(-b.w), color)
+	fmt.Fprintf(sb, `<rect x="%s" y="%s" width="%s" height="8" rx="4" fill="%s"/>`+"\n",
+		num(b.x), num(b.y), num(b.w), color)
+	fmt.Fprintf(sb, `<text x="%s" y="%s" fill="#ffffff" font-weight="bold">%s</text>`+"\n",
+		num(b.x+padding), num(b.y+headerHeight/2+fontSize/3), html.EscapeString(t.Name))
+
+	if t.Columns != nil && !b.collapsed() {
+		for i, c := range *t.Columns {
+			rowTop := b.y + headerHeight + rowHeight*float64(i)
+			baseline := rowTop + rowHeight/2 + fontSize/3
+
+			if i > 0 {
+				fmt.Fprintf(sb, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#e2e8f0"/>`+"\n",
+					num(b.x), num(rowTop), num(b.right()), num(rowTop))
+			}
+			switch {
+			case c.PK:
+				writeKeyIcon(sb, b.x+padding, rowTop+rowHeight/2, "#eab308")
+			case fkColumns[strings.ToLower(c.Name)]:
+				writeKeyIcon(sb, b.x+padding, rowTop+rowHeight/2, "#94a3b8")
+			}
+
+			weight := ""
+			if c.PK {
+				weight = ` font-weight="bold"`
+			}
+			fmt.Fprintf(sb, `<text x="%s" y="%s" fill="#0f172a"%s>%s</text>`+"\n",
+				num(b.x+padding+iconWidth), num(baseline), weight, html.EscapeString(c.Name))
+			fmt.Fprintf(sb, `<text x="%s" y="%s" fill="#64748b" text-anchor="end">%s</text>`+"\n",
+				num(b.right()-padding), num(baseline), html.EscapeString(columnType(c)))
+		}
+	}
+	sb.WriteString("</g>\n")
+}
+
+// 열쇠 모양 아이콘: 고리(원)와 몸통(선), 톱니 하나
+func writeKeyIcon(sb *strings.Builder, x, cy float64, color string) {
+	fmt.Fprintf(sb, `<g class="key" stroke="%s" stroke-width="1.5" fill="none">`, color)
+	fmt.Fprintf(sb, `<circle cx="%s" cy="%s" r="3"/>`, num(x+3), num(cy))
+	fmt.Fprintf(sb, `<path d="M%s,%s h8 M%s,%s v3"/>`, num(x+6), num(cy), num(x+12), num(cy))
+	sb.WriteString("</g>\n")
+}
+
+// 타입 뒤의 ? 는 NULL 허용 컬럼을 뜻한다
+func columnType(c domain.Column) string {
+	if c.Nullable && !c.PK {
+		return c.Type + "?"
+	}
+	return c.Type
+}
+
+func foreignKeyColumns(tables []domain.Table, t *domain.Table) map[string]bool {
+	columns := make(map[string]bool)
+	if t.Relations == nil {
+		return columns
+	}
+	for _, r := range *t.Relations {
+		if r.Type == domain.OneToMany {
+			continue
+		}
+		if owner, col := domain.ResolveEndpoint(tables, r.From); owner == t && col != "" {
+			columns[strings.ToLower(col)] = true
+		}
+	}
+	return columns
+}
+
+func writeRelation(sb *strings.Builder, tables []domain.Table, boxes []*box, r domain.Relation) {
+	fromTable, fromColumn := domain.ResolveEndpoint(tables, r.From)
+	toTable, toColumn := domain.ResolveEndpoint(tables, r.To)
+	from, to := findBox(boxes, fromTable), findBox(boxes, toTable)
+	if from == nil || to == nil {
+		return
+	}
+
+	y1, y2 := from.rowY(fromColumn), to.rowY(toColumn)
+
+	// 가로로 겹치지 않으면 마주보는 변끼리, 겹치면 오른쪽으로 돌아서 잇는다
+	var x1, x2, mid float64
+	var dir1, dir2 float64
+	switch {
+	case from.right()+detour <= to.x:
+		x1, x2, dir1, dir2 = from.right(), to.x, 1, -1
+		mid = (x1 + x2) / 2
+	case to.right()+detour <= from.x:
+		x1, x2, dir1, dir2 = from.x, to.right(), -1, 1
+		mid = (x1 + x2) / 2
+	default:
+		x1, x2, dir1, dir2 = from.right(), to.right(), 1, 1
+		mid = math.Max(x1, x2) + detour
+	}
+
+	fmt.Fprintf(sb, `<g class="relation" data-from="%s" data-to="%s" stroke="#475569" fill="none">`+"\n",
+		html.EscapeString(r.From), html.EscapeString(r.To))
+	fmt.Fprintf(sb, `<path d="M%s,%s H%s V%s H%s"/>`+"\n", num(x1), num(y1), num(mid), num(y2), num(x2))
+
+	fromMany := r.Type == domain.ManyToOne || r.Type == domain.ManyToMany
+	toMany := r.Type == domain.OneToMany || r.Type == domain.ManyToMany
+	writeCardinality(sb, x1, y1, dir1, fromMany)
+	writeCardinality(sb, x2, y2, dir2, toMany)
+	sb.WriteString("</g>\n")
+}
+
+// writeCardinality 는 (x, y) 에서 dir 방향으로 뻗는 선 끝에 crow's foot(다) 또는 막대(일)를 그린다
+func writeCardinality(sb *strings.Builder, x, y, dir float64, many bool) {
+	bar := x + dir*glyphSize*1.2
+	fmt.Fprintf(sb, `<path d="M%s,%s v%s"/>`, num(bar), num(y-glyphSize/2), num(glyphSize))
+
+	if many {
+		tip := x + dir*glyphSize
+		fmt.Fprintf(sb, `<path d="M%s,%s L%s,%s L%s,%s"/>`,
+			num(x), num(y-glyphSize/2), num(tip), num(y), num(x), num(y+glyphSize/2))
+	} else {
+		second := x + dir*glyphSize*0.7
+		fmt.Fprintf(sb, `<path d="M%s,%s v%s"/>`, num(second), num(y-glyphSize/2), num(glyphSize))
+	}
+	sb.WriteString("\n")
+}
+
+func findBox(boxes []*box, t *domain.Table) *box {
+	for _, b := range boxes {
+		if b.table == t {
+			return b
+		}
+	}
+	return nil
+}
+
+func textWidth(s string) float64 {
+	return float64(len([]rune(s))) * charWidth
+}
+
+func num(f float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", f), "0"), ".")
+}
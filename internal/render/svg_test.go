@@ -0,0 +1,61 @@
+package render
+
+import (
+	"diagram-server/internal/domain"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestDiagram() *domain.ERDiagram {
+	orderColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "user_id", Type: "bigint"},
+		{Name: "memo", Type: "text", Nullable: true},
+	}
+	orderRelations := []domain.Relation{{From: "orders.user_id", To: "users.id", Type: domain.ManyToOne}}
+	userColumns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "email", Type: "varchar(255)"},
+	}
+
+	return domain.NewERDiagram("shop <v1>", nil, "owner", []domain.Table{
+		{Name: "orders", Columns: &orderColumns, Relations: &orderRelations},
+		{Name: "users", Columns: &userColumns},
+	})
+}
+
+func TestSVG(t *testing.T) {
+	got := SVG(newTestDiagram())
+
+	dec := xml.NewDecoder(strings.NewReader(got))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("올바른 XML 이 아님: %v\n%s", err, got)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"루트 요소", `<svg xmlns="http://www.w3.org/2000/svg"`},
+		{"제목 이스케이프", "<title>shop &lt;v1&gt;</title>"},
+		{"테이블 박스", `data-table="orders"`},
+		{"PK 아이콘", `<g class="key" stroke="#eab308"`},
+		{"FK 아이콘", `<g class="key" stroke="#94a3b8"`},
+		{"NULL 허용 표시", ">text?</text>"},
+		{"관계선", `data-from="orders.user_id" data-to="users.id"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("%q 가 없음\n%s", tt.want, got)
+			}
+		})
+	}
+}
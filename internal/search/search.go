@@ -0,0 +1,131 @@
+// Package search 는 검색어가 다이어그램의 어디에 나타나는지 찾는다.
+// 후보를 고르는 일은 저장소의 전문 검색 인덱스가 하고, 여기서는 응답에 보여줄 위치만 계산한다.
+package search
+
+import (
+	"diagram-server/internal/domain"
+	"strings"
+	"unicode"
+)
+
+type Field string
+
+const (
+	FieldTitle             Field = "title"
+	FieldDescription       Field = "description"
+	FieldTable             Field = "table"
+	FieldColumn            Field = "column"
+	FieldColumnDescription Field = "columnDescription"
+)
+
+// Range 는 Text 안에서 일치한 부분이다. 오프셋은 바이트가 아니라 문자(rune) 단위다
+type Range struct {
+	Start int
+	End   int
+}
+
+// Hit 은 검색어가 나타난 곳이다. Path 는 테이블이면 "users", 컬럼이면 "users.customer_id" 다
+type Hit struct {
+	Path   string
+	Field  Field
+	Text   string
+	Ranges []Range
+}
+
+// Terms 는 검색어를 낱말로 나눈다. 따옴표는 벗기고 - 로 시작하는 제외어는 버린다
+func Terms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(query) {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		word = strings.ToLower(strings.Trim(word, `"`))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// Find 는 제목, 설명, 테이블 이름, 컬럼 이름, 컬럼 설명에서 검색어가 나타난 곳을 찾는다.
+// 대소문자는 구분하지 않는다.
+func Find(d domain.Diagram, query string) []Hit {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var hits []Hit
+	add := func(path string, field Field, text string) {
+		if ranges := match(text, terms); len(ranges) > 0 {
+			hits = append(hits, Hit{Path: path, Field: field, Text: text, Ranges: ranges})
+		}
+	}
+
+	if t, ok := d.(interface {
+		Title() string
+		Description() *string
+	}); ok {
+		add("", FieldTitle, t.Title())
+		if desc := t.Description(); desc != nil {
+			add("", FieldDescription, *desc)
+		}
+	}
+
+	erd, ok := d.(*domain.ERDiagram)
+	if !ok {
+		return hits
+	}
+	for _, table := range erd.Tables {
+		add(table.Name, FieldTable, table.Name)
+		if table.Columns == nil {
+			continue
+		}
+		for _, c := range *table.Columns {
+			path := table.Name + "." + c.Name
+			add(path, FieldColumn, c.Name)
+			if c.Description != nil {
+				add(path, FieldColumnDescription, *c.Description)
+			}
+		}
+	}
+	return hits
+}
+
+// match 는 겹치지 않는 일치 구간을 앞에서부터 찾는다
+func match(text string, terms []string) []Range {
+	runes := []rune(text)
+	var ranges []Range
+
+	for i := 0; i < len(runes); {
+		longest := 0
+		for _, term := range terms {
+			tr := []rune(term)
+			if len(tr) > longest && hasPrefixAt(runes, tr, i) {
+				longest = len(tr)
+			}
+		}
+		if longest == 0 {
+			i++
+			continue
+		}
+		ranges = append(ranges, Range{Start: i, End: i + longest})
+		i += longest
+	}
+	return ranges
+}
+
+func hasPrefixAt(runes, prefix []rune, at int) bool {
+	if at+len(prefix) > len(runes) {
+		return false
+	}
+	for j, r := range prefix {
+		if unicode.ToLower(runes[at+j]) != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"diagram-server/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "공백으로 나누고 소문자로 바꾼다", query: "Customer_ID  orders", want: []string{"customer_id", "orders"}},
+		{name: "따옴표는 벗기고 중복은 없앤다", query: `"orders" ORDERS`, want: []string{"orders"}},
+		{name: "제외어는 버린다", query: "orders -legacy", want: []string{"orders"}},
+		{name: "빈 검색어는 낱말이 없다", query: "   ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Terms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	note := "references the Customer_ID of users"
+	columns := []domain.Column{
+		{Name: "id", Type: "bigint", PK: true},
+		{Name: "customer_id", Type: "bigint", Description: &note},
+	}
+	erd := domain.NewERDiagram("Customer orders", nil, "alice", []domain.Table{
+		{Name: "orders", Columns: &columns},
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []Hit
+	}{
+		{
+			name:  "컬럼 이름과 설명의 위치를 찾는다",
+			query: "customer_id",
+			want: []Hit{
+				{Path: "orders.customer_id", Field: FieldColumn, Text: "customer_id", Ranges: []Range{{0, 11}}},
+				{Path: "orders.customer_id", Field: FieldColumnDescription, Text: note, Ranges: []Range{{15, 26}}},
+			},
+		},
+		{
+			name:  "제목과 테이블 이름을 찾는다",
+			query: "orders",
+			want: []Hit{
+				{Path: "", Field: FieldTitle, Text: "Customer orders", Ranges: []Range{{9, 15}}},
+				{Path: "orders", Field: FieldTable, Text: "orders", Ranges: []Range{{0, 6}}},
+			},
+		},
+		{
+			name:  "일치하는 곳이 없으면 비어 있다",
+			query: "invoice",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Find(erd, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
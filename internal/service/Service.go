@@ -2,81 +2,643 @@ package service
 
 import (
 	"context"
+	"diagram-server/internal/auth"
+	"diagram-server/internal/diff"
 	"diagram-server/internal/domain"
+	"diagram-server/internal/exporter"
+	"diagram-server/internal/parser"
 	"diagram-server/internal/persistance"
+	"diagram-server/internal/search"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
+var ErrInvalidDiagramType = errors.New("invalid diagram type")
+
 type DiagramService interface {
-	Create(ctx context.Context, req CreateDiagramRequest) (*domain.ERDiagram, error)
+	Create(ctx context.Context, req CreateDiagramRequest) (domain.Diagram, error)
+	// CreateAll 은 모든 요청을 검사한 뒤에 저장한다. 저장 중 실패하면 앞서 저장한 다이어그램을 지운다
+	CreateAll(ctx context.Context, reqs []CreateDiagramRequest) ([]domain.Diagram, error)
 	GetByID(ctx context.Context, id string) (domain.Diagram, error)
 	GetAllByType(ctx context.Context, dtype domain.DiagramType) ([]domain.Diagram, error)
-	Update(ctx context.Context, id string, req UpdateDiagramRequest) error
+	List(ctx context.Context, req ListDiagramsRequest) (*persistance.Page, error)
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	Update(ctx context.Context, id string, req UpdateDiagramRequest) (domain.Diagram, error)
+	Replace(ctx context.Context, id string, req ReplaceDiagramRequest) (domain.Diagram, error)
+	ApplyMigration(ctx context.Context, id string, req MigrationRequest) (*domain.ERDiagram, error)
+	UpdateLayout(ctx context.Context, id string, version int64, layouts map[string]*domain.TableLayout) (*domain.ERDiagram, error)
 	Delete(ctx context.Context, id string) error
+
+	// LoadLive 와 SaveLive 는 실시간 편집 방이 쓴다. 세션 권한은 연결할 때 확인하므로 LoadLive 는 권한을 보지 않는다
+	LoadLive(ctx context.Context, id string) (*domain.ERDiagram, error)
+	// SaveLive 는 ctx 의 사용자가 편집자 이상인지 확인하고 저장한 뒤 리비전을 남긴다
+	SaveLive(ctx context.Context, erd *domain.ERDiagram) error
+	// RolesLive 는 저장된 다이어그램과 워크스페이스를 한 번씩만 읽어 여러 사용자의 역할을 다시 계산한다.
+	// 방이 저장 주기마다 연결 뒤 바뀐 권한을 세션에 반영할 때 쓴다
+	RolesLive(ctx context.Context, id string, userIDs []string) (map[string]domain.Role, error)
+
+	GetRevisions(ctx context.Context, id string) ([]*domain.Revision, error)
+	GetRevision(ctx context.Context, id string, number int) (*domain.Revision, error)
+	RestoreRevision(ctx context.Context, id string, number int) (domain.Diagram, error)
+
+	Diff(ctx context.Context, from, to DiagramRef) (*diff.Changeset, error)
+	Migration(ctx context.Context, from, to DiagramRef, dialect exporter.Dialect) (*exporter.Migration, error)
+
+	// RoleOf 는 요청한 사용자의 실제 역할이다. 워크스페이스에서 받은 역할도 반영한다
+	RoleOf(ctx context.Context, d domain.Diagram) (domain.Role, error)
+	GetACL(ctx context.Context, id string) (domain.Diagram, error)
+	Grant(ctx context.Context, id string, req GrantRequest) (domain.Diagram, error)
+	Revoke(ctx context.Context, id string, user string) (domain.Diagram, error)
 }
 
 type diagramService struct {
-	repo persistance.DiagramRepository
+	repo       persistance.DiagramRepository
+	revisions  persistance.RevisionRepository
+	users      persistance.UserRepository
+	workspaces persistance.WorkspaceRepository
 }
 
-func NewDiagramService(repo persistance.DiagramRepository) DiagramService {
-	return &diagramService{repo: repo}
+func NewDiagramService(
+	repo persistance.DiagramRepository,
+	revisions persistance.RevisionRepository,
+	users persistance.UserRepository,
+	workspaces persistance.WorkspaceRepository,
+) DiagramService {
+	return &diagramService{repo: repo, revisions: revisions, users: users, workspaces: workspaces}
 }
 
+// CreateDiagramRequest 의 Type 이 비어 있으면 ERD 로 만든다. 소유자는 인증된 사용자다.
+// Workspace 가 주어지면 그 워크스페이스의 editor 이상이어야 한다.
 type CreateDiagramRequest struct {
+	Type        domain.DiagramType
+	Workspace   string
 	Title       string
-	Owner       string
 	Description *string
-	Tables      []domain.Table
+
+	// Type == TypeERD
+	Tables []domain.Table
+
+	// Type == TypeFlowChart
+	Nodes []domain.FlowNode
+	Edges []domain.FlowEdge
 }
 
+// UpdateDiagramRequest 는 부분 수정이다. nil 인 필드는 바꾸지 않는다.
+// Version 이 persistance.AnyVersion 이 아니면 저장된 버전과 같을 때만 수정한다.
 type UpdateDiagramRequest struct {
+	Version     int64
 	Title       *string
 	Description *string
 	Tables      []domain.Table
+	Nodes       []domain.FlowNode
+	Edges       []domain.FlowEdge
 }
 
-func (s *diagramService) Create(ctx context.Context, req CreateDiagramRequest) (*domain.ERDiagram, error) {
-	diagram := domain.NewERDiagram(
-		req.Title,
-		req.Description,
-		req.Owner,
-		req.Tables,
-	)
+// ReplaceDiagramRequest 는 전체 교체다. Type 이 주어지면 기존 다이어그램의 타입과 같아야 한다.
+// Version 은 UpdateDiagramRequest 와 같다.
+type ReplaceDiagramRequest struct {
+	Version     int64
+	Type        domain.DiagramType
+	Title       string
+	Description *string
+	Tables      []domain.Table
+	Nodes       []domain.FlowNode
+	Edges       []domain.FlowEdge
+}
 
-	id, err := s.repo.Save(ctx, diagram)
+// ListDiagramsRequest 는 빈 조건을 적용하지 않는다. Sort 가 비어 있으면 최근 수정 순이다
+type ListDiagramsRequest struct {
+	Type           domain.DiagramType
+	Owner          string
+	TitlePrefix    string
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	Sort           persistance.SortField
+	Descending     bool
+	Cursor         string
+	Limit          int
+}
+
+// SearchResult 의 Hits 는 검색어가 나타난 테이블/컬럼 위치다
+type SearchResult struct {
+	Diagram domain.Diagram
+	Score   float64
+	Hits    []search.Hit
+}
+
+// DiagramRef 는 다이어그램의 현재 상태(Revision == 0) 또는 특정 리비전을 가리킨다
+type DiagramRef struct {
+	ID       string
+	Revision int
+}
+
+type MigrationRequest struct {
+	Version int64
+	Dialect parser.Dialect
+	Script  string
+}
+
+type newDiagram interface {
+	domain.Diagram
+	SetID(id string)
+	SetWorkspace(workspace string)
+}
+
+func (s *diagramService) Create(ctx context.Context, req CreateDiagramRequest) (domain.Diagram, error) {
+	diagram, err := s.prepare(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.store(ctx, diagram); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
 
-	diagram.SetID(id)
+func (s *diagramService) CreateAll(ctx context.Context, reqs []CreateDiagramRequest) ([]domain.Diagram, error) {
+	diagrams := make([]newDiagram, len(reqs))
+	for i, req := range reqs {
+		diagram, err := s.prepare(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("diagram %d: %w", i, err)
+		}
+		diagrams[i] = diagram
+	}
+
+	created := make([]domain.Diagram, 0, len(diagrams))
+	for i, diagram := range diagrams {
+		if err := s.store(ctx, diagram); err != nil {
+			s.discard(ctx, created)
+			return nil, fmt.Errorf("diagram %d: %w", i, err)
+		}
+		created = append(created, diagram)
+	}
+	return created, nil
+}
+
+// prepare 는 요청으로 저장할 다이어그램을 만들고, 저장 전에 확인할 수 있는 것을 모두 확인한다
+func (s *diagramService) prepare(ctx context.Context, req CreateDiagramRequest) (newDiagram, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	var diagram newDiagram
+
+	switch req.Type {
+	case "", domain.TypeERD:
+		diagram = domain.NewERDiagram(
+			req.Title,
+			req.Description,
+			identity.UserID,
+			req.Tables,
+		)
+	case domain.TypeFlowChart:
+		flow := domain.NewFlowChart(
+			req.Title,
+			req.Description,
+			identity.UserID,
+			req.Nodes,
+			req.Edges,
+		)
+		if err := flow.Validate(); err != nil {
+			return nil, err
+		}
+		diagram = flow
+	default:
+		return nil, ErrInvalidDiagramType
+	}
+
+	if req.Workspace != "" {
+		workspace, err := s.workspaces.FindByID(ctx, req.Workspace)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizeMember(workspace, identity.UserID, domain.RoleEditor); err != nil {
+			return nil, err
+		}
+		diagram.SetWorkspace(workspace.ID())
+	}
 	return diagram, nil
 }
 
+func (s *diagramService) store(ctx context.Context, diagram newDiagram) error {
+	id, err := s.repo.Save(ctx, diagram)
+	if err != nil {
+		return err
+	}
+
+	diagram.SetID(id)
+	return s.record(ctx, diagram, "created")
+}
+
+// discard 는 일괄 생성이 중간에 실패했을 때 이미 저장한 다이어그램을 지운다.
+// 원래 오류를 돌려주는 것이 중요하므로 여기서 생긴 오류는 무시한다.
+func (s *diagramService) discard(ctx context.Context, diagrams []domain.Diagram) {
+	for _, d := range diagrams {
+		_ = s.repo.Delete(ctx, d.ID())
+		_ = s.revisions.DeleteByDiagram(ctx, d.ID())
+	}
+}
+
 func (s *diagramService) GetByID(ctx context.Context, id string) (domain.Diagram, error) {
-	return s.repo.FindByID(ctx, id)
+	return s.load(ctx, id, domain.RoleViewer)
 }
 
+// GetAllByType 은 요청한 사용자가 볼 수 있는 다이어그램만 반환한다
 func (s *diagramService) GetAllByType(ctx context.Context, dtype domain.DiagramType) ([]domain.Diagram, error) {
-	return s.repo.FindByType(ctx, dtype)
+	viewer, err := s.viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindByType(ctx, dtype, viewer)
+}
+
+// List 는 요청한 사용자가 볼 수 있는 다이어그램을 한 페이지씩 반환한다
+func (s *diagramService) List(ctx context.Context, req ListDiagramsRequest) (*persistance.Page, error) {
+	viewer, err := s.viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx, persistance.ListQuery{
+		Viewer:         viewer,
+		Type:           req.Type,
+		Owner:          req.Owner,
+		TitlePrefix:    req.TitlePrefix,
+		CreatedAfter:   req.CreatedAfter,
+		CreatedBefore:  req.CreatedBefore,
+		ModifiedAfter:  req.ModifiedAfter,
+		ModifiedBefore: req.ModifiedBefore,
+		Sort:           req.Sort,
+		Descending:     req.Descending,
+		Cursor:         req.Cursor,
+		Limit:          req.Limit,
+	})
 }
 
-func (s *diagramService) Update(ctx context.Context, id string, req UpdateDiagramRequest) error {
+// Search 는 요청한 사용자가 볼 수 있는 다이어그램에서 검색하고, 각 결과에 일치한 위치를 붙인다
+func (s *diagramService) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	viewer, err := s.viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.Search(ctx, persistance.SearchQuery{Viewer: viewer, Text: query, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, len(found))
+	for i, f := range found {
+		results[i] = SearchResult{Diagram: f.Diagram, Score: f.Score, Hits: search.Find(f.Diagram, query)}
+	}
+	return results, nil
+}
+
+// viewer 는 요청한 사용자와 그가 속한 워크스페이스다
+func (s *diagramService) viewer(ctx context.Context) (persistance.Viewer, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return persistance.Viewer{}, auth.ErrUnauthenticated
+	}
+
+	workspaces, err := s.workspaces.FindByMember(ctx, identity.UserID)
+	if err != nil {
+		return persistance.Viewer{}, err
+	}
+
+	viewer := persistance.Viewer{UserID: identity.UserID}
+	for _, w := range workspaces {
+		viewer.Workspaces = append(viewer.Workspaces, w.ID())
+	}
+	return viewer, nil
+}
+
+func (s *diagramService) Update(ctx context.Context, id string, req UpdateDiagramRequest) (domain.Diagram, error) {
+	diagram, err := s.find(ctx, id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	switch d := diagram.(type) {
+	case *domain.ERDiagram:
+		if req.Nodes != nil || req.Edges != nil {
+			return nil, ErrInvalidDiagramType
+		}
+		d.Update(req.Title, req.Description, req.Tables)
+	case *domain.FlowChart:
+		if req.Tables != nil {
+			return nil, ErrInvalidDiagramType
+		}
+		d.Update(req.Title, req.Description, req.Nodes, req.Edges)
+		if err := d.Validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidDiagramType
+	}
+
+	if err := s.save(ctx, diagram, updateSummary(req)); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
+
+func updateSummary(req UpdateDiagramRequest) string {
+	var fields []string
+	if req.Title != nil {
+		fields = append(fields, "title")
+	}
+	if req.Description != nil {
+		fields = append(fields, "description")
+	}
+	if req.Tables != nil {
+		fields = append(fields, "tables")
+	}
+	if req.Nodes != nil {
+		fields = append(fields, "nodes")
+	}
+	if req.Edges != nil {
+		fields = append(fields, "edges")
+	}
+	if len(fields) == 0 {
+		return "updated"
+	}
+	return "updated " + strings.Join(fields, ", ")
+}
+
+func (s *diagramService) Replace(ctx context.Context, id string, req ReplaceDiagramRequest) (domain.Diagram, error) {
+	diagram, err := s.find(ctx, id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Type != "" && req.Type != diagram.Type() {
+		return nil, ErrInvalidDiagramType
+	}
+
+	if err := replace(diagram, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.save(ctx, diagram, "replaced"); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
+
+func replace(diagram domain.Diagram, req ReplaceDiagramRequest) error {
+	switch d := diagram.(type) {
+	case *domain.ERDiagram:
+		if req.Nodes != nil || req.Edges != nil {
+			return ErrInvalidDiagramType
+		}
+		d.Replace(req.Title, req.Description, req.Tables)
+	case *domain.FlowChart:
+		if req.Tables != nil {
+			return ErrInvalidDiagramType
+		}
+		d.Replace(req.Title, req.Description, req.Nodes, req.Edges)
+		return d.Validate()
+	default:
+		return ErrInvalidDiagramType
+	}
+	return nil
+}
+
+func (s *diagramService) ApplyMigration(ctx context.Context, id string, req MigrationRequest) (*domain.ERDiagram, error) {
+	diagram, err := s.find(ctx, id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	erd, ok := diagram.(*domain.ERDiagram)
+	if !ok {
+		return nil, ErrInvalidDiagramType
+	}
+
+	tables, err := parser.Apply(req.Dialect, erd.Tables, req.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	erd.UpdateTables(tables)
+
+	if err := s.save(ctx, erd, "applied migration"); err != nil {
+		return nil, err
+	}
+	return erd, nil
+}
+
+func (s *diagramService) UpdateLayout(ctx context.Context, id string, version int64, layouts map[string]*domain.TableLayout) (*domain.ERDiagram, error) {
+	diagram, err := s.find(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	erd, ok := diagram.(*domain.ERDiagram)
+	if !ok {
+		return nil, ErrInvalidDiagramType
+	}
+
+	if err := erd.UpdateLayout(layouts); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.repo.UpdateLayout(ctx, id, version, layouts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 버전이 하나만 올랐다면 그 사이 다른 저장이 없었으므로 읽은 값이 저장된 값과 같다
+	if saved != erd.Version()+1 {
+		if diagram, err = s.repo.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+		if erd, ok = diagram.(*domain.ERDiagram); !ok {
+			return nil, ErrInvalidDiagramType
+		}
+	}
+	erd.SetVersion(saved)
+
+	// 배치 저장도 버전을 올리므로 리비전 번호가 빠지지 않도록 기록한다
+	if err := s.record(ctx, erd, "updated layout"); err != nil {
+		return nil, err
+	}
+	return erd, nil
+}
+
+func (s *diagramService) LoadLive(ctx context.Context, id string) (*domain.ERDiagram, error) {
+	diagram, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	erd, ok := diagram.(*domain.ERDiagram)
+	if !ok {
+		return nil, ErrInvalidDiagramType
+	}
+	return erd, nil
+}
+
+func (s *diagramService) SaveLive(ctx context.Context, erd *domain.ERDiagram) error {
+	if _, err := s.load(ctx, erd.ID(), domain.RoleEditor); err != nil {
+		return err
+	}
+	return s.save(ctx, erd, "edited live")
+}
+
+func (s *diagramService) RolesLive(ctx context.Context, id string, userIDs []string) (map[string]domain.Role, error) {
 	diagram, err := s.repo.FindByID(ctx, id)
 	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.workspaceOf(ctx, diagram)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]domain.Role, len(userIDs))
+	for _, userID := range userIDs {
+		roles[userID] = roleIn(diagram, workspace, userID)
+	}
+	return roles, nil
+}
+
+// Delete 는 소유자만 할 수 있다
+func (s *diagramService) Delete(ctx context.Context, id string) error {
+	if _, err := s.load(ctx, id, domain.RoleOwner); err != nil {
 		return err
 	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.revisions.DeleteByDiagram(ctx, id)
+}
+
+func (s *diagramService) GetRevisions(ctx context.Context, id string) ([]*domain.Revision, error) {
+	if _, err := s.load(ctx, id, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.revisions.FindByDiagram(ctx, id)
+}
+
+func (s *diagramService) GetRevision(ctx context.Context, id string, number int) (*domain.Revision, error) {
+	if _, err := s.load(ctx, id, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.revisions.FindByNumber(ctx, id, number)
+}
+
+// RestoreRevision 은 리비전의 내용으로 다이어그램을 덮어쓰고, 복원 자체도 새 리비전으로 남긴다.
+// 내용만 되돌리므로 ACL 과 워크스페이스는 지금 상태를 유지한다
+func (s *diagramService) RestoreRevision(ctx context.Context, id string, number int) (domain.Diagram, error) {
+	diagram, err := s.load(ctx, id, domain.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := s.revisions.FindByNumber(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if rev.Diagram.Type() != diagram.Type() {
+		return nil, ErrInvalidDiagramType
+	}
+
+	var req ReplaceDiagramRequest
+	switch snapshot := rev.Diagram.(type) {
+	case *domain.ERDiagram:
+		req = ReplaceDiagramRequest{Title: snapshot.Title(), Description: snapshot.Description(), Tables: snapshot.Tables}
+	case *domain.FlowChart:
+		req = ReplaceDiagramRequest{Title: snapshot.Title(), Description: snapshot.Description(), Nodes: snapshot.Nodes, Edges: snapshot.Edges}
+	}
+	if err := replace(diagram, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.save(ctx, diagram, fmt.Sprintf("restored revision %d", number)); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
+
+func (s *diagramService) Diff(ctx context.Context, from, to DiagramRef) (*diff.Changeset, error) {
+	before, err := s.resolve(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.resolve(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(before.Tables, after.Tables), nil
+}
+
+// Migration 은 from 을 to 로 바꾸는 SQL 과 되돌리는 SQL 을 만든다
+func (s *diagramService) Migration(ctx context.Context, from, to DiagramRef, dialect exporter.Dialect) (*exporter.Migration, error) {
+	before, err := s.resolve(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.resolve(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	return exporter.MigrationSQL(before.Tables, after.Tables, dialect)
+}
+
+// resolve 는 현재 다이어그램을 볼 수 있는 사용자에게만 그 리비전도 보여준다
+func (s *diagramService) resolve(ctx context.Context, ref DiagramRef) (*domain.ERDiagram, error) {
+	diagram, err := s.load(ctx, ref.ID, domain.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Revision != 0 {
+		rev, err := s.revisions.FindByNumber(ctx, ref.ID, ref.Revision)
+		if err != nil {
+			return nil, err
+		}
+		diagram = rev.Diagram
+	}
 
 	erd, ok := diagram.(*domain.ERDiagram)
 	if !ok {
-		return errors.New("invalid diagram type")
+		return nil, ErrInvalidDiagramType
 	}
+	return erd, nil
+}
 
-	erd.Update(req.Title, req.Description, req.Tables)
+// find 는 편집자 이상만 수정할 다이어그램을 읽는다. version 이 AnyVersion 이 아니면 저장된 버전과 같아야 한다
+func (s *diagramService) find(ctx context.Context, id string, version int64) (domain.Diagram, error) {
+	diagram, err := s.load(ctx, id, domain.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if version != persistance.AnyVersion && version != diagram.Version() {
+		return nil, persistance.ErrVersionConflict
+	}
+	return diagram, nil
+}
 
-	return s.repo.Update(ctx, erd)
+// save 는 다이어그램을 저장하고 그 상태를 리비전으로 남긴다
+func (s *diagramService) save(ctx context.Context, diagram domain.Diagram, summary string) error {
+	if err := s.repo.Update(ctx, diagram); err != nil {
+		return err
+	}
+	return s.record(ctx, diagram, summary)
 }
 
-func (s *diagramService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+// record 는 요청한 사용자를 작성자로 리비전을 남긴다. 인증 정보가 없으면 소유자로 기록한다
+func (s *diagramService) record(ctx context.Context, diagram domain.Diagram, summary string) error {
+	author := diagram.Owner()
+	if identity, ok := auth.FromContext(ctx); ok {
+		author = identity.UserID
+	}
+	return s.revisions.Save(ctx, domain.NewRevision(diagram, author, summary))
 }
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"diagram-server/internal/auth"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type testRepositories struct {
+	diagrams   persistance.DiagramRepository
+	revisions  persistance.RevisionRepository
+	users      persistance.UserRepository
+	workspaces persistance.WorkspaceRepository
+}
+
+func newTestService() (DiagramService, testRepositories) {
+	repos := testRepositories{
+		diagrams:   persistance.NewMemoryDiagramRepository(),
+		revisions:  persistance.NewMemoryRevisionRepository(),
+		users:      persistance.NewMemoryUserRepository(),
+		workspaces: persistance.NewMemoryWorkspaceRepository(),
+	}
+	return NewDiagramService(repos.diagrams, repos.revisions, repos.users, repos.workspaces), repos
+}
+
+func as(userID string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{UserID: userID})
+}
+
+func TestDiagramService_CreateAll(t *testing.T) {
+	svc, repos := newTestService()
+	ctx := as("alice")
+
+	_, err := svc.CreateAll(ctx, []CreateDiagramRequest{
+		{Title: "first"},
+		{Title: "second", Workspace: "missing"},
+	})
+	if !errors.Is(err, persistance.ErrWorkspaceNotFound) {
+		t.Fatalf("CreateAll() error = %v, want %v", err, persistance.ErrWorkspaceNotFound)
+	}
+
+	saved, _ := repos.diagrams.FindByType(ctx, domain.TypeERD, persistance.Viewer{UserID: "alice"})
+	if len(saved) != 0 {
+		t.Errorf("saved = %d diagrams, want none after a failed import", len(saved))
+	}
+
+	created, err := svc.CreateAll(ctx, []CreateDiagramRequest{{Title: "first"}, {Title: "second"}})
+	if err != nil {
+		t.Fatalf("CreateAll() error = %v", err)
+	}
+	if len(created) != 2 || created[0].ID() == "" || created[1].ID() == "" {
+		t.Errorf("CreateAll() = %v, want two saved diagrams", created)
+	}
+}
+
+func TestDiagramService_EmptyDescription(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := as("alice")
+	desc, empty := "설명", ""
+
+	tests := []struct {
+		name  string
+		dtype domain.DiagramType
+		write func(d domain.Diagram) (domain.Diagram, error)
+	}{
+		{name: "PATCH 의 빈 설명은 설명을 지운다", dtype: domain.TypeERD, write: func(d domain.Diagram) (domain.Diagram, error) {
+			return svc.Update(ctx, d.ID(), UpdateDiagramRequest{Description: &empty, Version: d.Version()})
+		}},
+		{name: "PUT 의 빈 설명도 설명을 지운다", dtype: domain.TypeERD, write: func(d domain.Diagram) (domain.Diagram, error) {
+			return svc.Replace(ctx, d.ID(), ReplaceDiagramRequest{Type: domain.TypeERD, Title: "Orders", Description: &empty, Version: d.Version()})
+		}},
+		{name: "플로차트 PUT 의 빈 설명도 설명을 지운다", dtype: domain.TypeFlowChart, write: func(d domain.Diagram) (domain.Diagram, error) {
+			return svc.Replace(ctx, d.ID(), ReplaceDiagramRequest{Type: domain.TypeFlowChart, Title: "Flow", Description: &empty, Version: d.Version()})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := svc.Create(ctx, CreateDiagramRequest{Type: tt.dtype, Title: "Orders", Description: &desc})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tt.write(d); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			stored, err := svc.GetByID(ctx, d.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stored.(interface{ Description() *string }).Description(); got != nil {
+				t.Errorf("Description() = %q, want nil", *got)
+			}
+		})
+	}
+}
+
+func TestDiagramService_UpdateLayoutRecordsRevision(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := as("alice")
+
+	d, err := svc.Create(ctx, CreateDiagramRequest{Title: "Orders", Tables: []domain.Table{{Name: "orders"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	erd, err := svc.UpdateLayout(ctx, d.ID(), persistance.AnyVersion, map[string]*domain.TableLayout{"orders": {X: 10, Y: 20}})
+	if err != nil {
+		t.Fatalf("UpdateLayout() error = %v", err)
+	}
+
+	revisions, _ := svc.GetRevisions(ctx, d.ID())
+	if len(revisions) != 2 {
+		t.Fatalf("revisions = %d, want 2", len(revisions))
+	}
+	last := revisions[1].Diagram.(*domain.ERDiagram)
+	if revisions[1].Summary != "updated layout" || last.Version() != erd.Version() || last.Tables[0].Layout == nil {
+		t.Errorf("last revision = %q version %d, want the saved layout at version %d", revisions[1].Summary, last.Version(), erd.Version())
+	}
+}
+
+func TestDiagramService_UpdateVersionConflict(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := as("alice")
+
+	d, err := svc.Create(ctx, CreateDiagramRequest{Title: "Orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := d.Version()
+
+	first, second := "first", "second"
+	if _, err := svc.Update(ctx, d.ID(), UpdateDiagramRequest{Title: &first, Version: loaded}); err != nil {
+		t.Fatalf("first Update() error = %v", err)
+	}
+	if _, err := svc.Update(ctx, d.ID(), UpdateDiagramRequest{Title: &second, Version: loaded}); !errors.Is(err, persistance.ErrVersionConflict) {
+		t.Fatalf("second Update() error = %v, want %v", err, persistance.ErrVersionConflict)
+	}
+	layouts := map[string]*domain.TableLayout{"orders": {X: 1, Y: 1}}
+	if _, err := svc.UpdateLayout(ctx, d.ID(), loaded, layouts); !errors.Is(err, persistance.ErrVersionConflict) {
+		t.Fatalf("UpdateLayout() error = %v, want %v", err, persistance.ErrVersionConflict)
+	}
+
+	got, err := svc.GetByID(ctx, d.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.(*domain.ERDiagram).Title() != first {
+		t.Errorf("Title = %q, want %q kept from the first update", got.(*domain.ERDiagram).Title(), first)
+	}
+}
+
+func TestDiagramRepository_ConcurrentUpdate(t *testing.T) {
+	svc, repos := newTestService()
+	ctx := as("alice")
+
+	d, err := svc.Create(ctx, CreateDiagramRequest{Title: "Orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loaded, err := repos.diagrams.FindByID(ctx, d.ID())
+			if err != nil {
+				errs <- err
+				return
+			}
+			loaded.SetVersion(d.Version())
+			errs <- repos.diagrams.Update(ctx, loaded)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, persistance.ErrVersionConflict):
+			t.Errorf("Update() error = %v, want nil or %v", err, persistance.ErrVersionConflict)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("succeeded = %d, want exactly one update from the same version", succeeded)
+	}
+}
+
+func TestDiagramService_ACL(t *testing.T) {
+	svc, repos := newTestService()
+	ctx := context.Background()
+
+	workspace, err := domain.NewWorkspace("team", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workspace.AddMember("member", domain.RoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	workspaceID, err := repos.workspaces.Save(ctx, workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := svc.Create(as("owner"), CreateDiagramRequest{Title: "Orders", Workspace: workspaceID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Grant 는 가입한 사용자에게만 할 수 있다
+	users := map[string]string{"owner": "owner", "member": "member", "stranger": "stranger"}
+	for name, role := range map[string]domain.Role{"editor": domain.RoleEditor, "viewer": domain.RoleViewer} {
+		user, err := domain.NewUser(name+"@example.com", "password1", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if users[name], err = repos.users.Save(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.Grant(as("owner"), d.ID(), GrantRequest{UserID: users[name], Role: role}); err != nil {
+			t.Fatalf("Grant(%s) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		user       string
+		wantRead   error
+		wantUpdate error
+		wantOwner  error
+		wantRole   domain.Role
+	}{
+		{name: "소유자는 모두 할 수 있다", user: "owner", wantRole: domain.RoleOwner},
+		{name: "편집자는 읽고 고칠 수 있다", user: "editor", wantOwner: ErrForbidden, wantRole: domain.RoleEditor},
+		{name: "워크스페이스 편집자도 편집자다", user: "member", wantOwner: ErrForbidden, wantRole: domain.RoleEditor},
+		{name: "뷰어는 읽기만 할 수 있다", user: "viewer", wantUpdate: ErrForbidden, wantOwner: ErrForbidden, wantRole: domain.RoleViewer},
+		{name: "권한이 없으면 다이어그램이 없는 것처럼 보인다", user: "stranger", wantRead: persistance.ErrNotFound, wantUpdate: persistance.ErrNotFound, wantOwner: persistance.ErrNotFound},
+	}
+
+	// 실시간 편집 방은 모든 세션의 역할을 한 번에 읽는다
+	ids := make([]string, 0, len(users))
+	for _, id := range users {
+		ids = append(ids, id)
+	}
+	roles, err := svc.RolesLive(ctx, d.ID(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roles[users[tt.user]]; got != tt.wantRole {
+				t.Errorf("RolesLive() role = %q, want %q", got, tt.wantRole)
+			}
+
+			ctx := as(users[tt.user])
+
+			if _, err := svc.GetByID(ctx, d.ID()); !errors.Is(err, tt.wantRead) {
+				t.Errorf("GetByID() error = %v, want %v", err, tt.wantRead)
+			}
+			title := "by " + tt.user
+			if _, err := svc.Update(ctx, d.ID(), UpdateDiagramRequest{Title: &title, Version: persistance.AnyVersion}); !errors.Is(err, tt.wantUpdate) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantUpdate)
+			}
+			if _, err := svc.Grant(ctx, d.ID(), GrantRequest{UserID: users["viewer"], Role: domain.RoleViewer}); !errors.Is(err, tt.wantOwner) {
+				t.Errorf("Grant() error = %v, want %v", err, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestDiagramService_GrantThenRestore(t *testing.T) {
+	svc, repos := newTestService()
+	ctx := as("owner")
+
+	user, err := domain.NewUser("editor@example.com", "password1", "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor, err := repos.users.Save(context.Background(), user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := svc.Create(ctx, CreateDiagramRequest{Title: "Orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	granted, err := svc.Grant(ctx, d.ID(), GrantRequest{UserID: editor, Role: domain.RoleEditor})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 권한 변경도 리비전으로 남아 버전과 리비전 번호가 어긋나지 않는다
+	revisions, _ := svc.GetRevisions(ctx, d.ID())
+	if len(revisions) != 2 || revisions[1].Diagram.Version() != granted.Version() {
+		t.Fatalf("revisions = %d, want the grant recorded at version %d", len(revisions), granted.Version())
+	}
+
+	restored, err := svc.RestoreRevision(ctx, d.ID(), revisions[0].Number)
+	if err != nil {
+		t.Fatalf("RestoreRevision() error = %v", err)
+	}
+	if restored.RoleOf(editor) != domain.RoleEditor {
+		t.Errorf("RoleOf(editor) = %q after restore, want the granted role kept", restored.RoleOf(editor))
+	}
+}
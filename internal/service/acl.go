@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"diagram-server/internal/auth"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrForbidden = errors.New("permission denied")
+
+// GrantRequest 는 UserID 나 Email 중 하나로 대상을 지정한다
+type GrantRequest struct {
+	UserID string
+	Email  string
+	Role   domain.Role
+}
+
+// authorize 는 요청한 사용자가 required 이상의 역할인지 확인한다. 워크스페이스의 역할이 더 크면 그것을 쓴다.
+// 아무 권한도 없으면 다이어그램이 있다는 사실도 감추기 위해 ErrNotFound 를 반환한다.
+func (s *diagramService) authorize(ctx context.Context, diagram domain.Diagram, required domain.Role) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+
+	role, err := s.roleOf(ctx, diagram, identity.UserID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return persistance.ErrNotFound
+	}
+	if !role.Allows(required) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// roleOf 는 다이어그램에 직접 받은 역할과 워크스페이스 역할 중 큰 쪽이다. 워크스페이스가 없어졌으면 무시한다
+func (s *diagramService) roleOf(ctx context.Context, diagram domain.Diagram, userID string) (domain.Role, error) {
+	workspace, err := s.workspaceOf(ctx, diagram)
+	if err != nil {
+		return "", err
+	}
+	return roleIn(diagram, workspace, userID), nil
+}
+
+// workspaceOf 는 다이어그램이 속한 워크스페이스다. 속하지 않았거나 워크스페이스가 없어졌으면 nil 이다
+func (s *diagramService) workspaceOf(ctx context.Context, diagram domain.Diagram) (*domain.Workspace, error) {
+	if diagram.Workspace() == "" {
+		return nil, nil
+	}
+
+	workspace, err := s.workspaces.FindByID(ctx, diagram.Workspace())
+	if errors.Is(err, persistance.ErrWorkspaceNotFound) {
+		return nil, nil
+	}
+	return workspace, err
+}
+
+func roleIn(diagram domain.Diagram, workspace *domain.Workspace, userID string) domain.Role {
+	role := diagram.RoleOf(userID)
+	if workspace == nil {
+		return role
+	}
+	return role.Higher(workspace.RoleOf(userID))
+}
+
+// load 는 다이어그램을 읽고 required 이상의 역할인지 확인한다
+func (s *diagramService) load(ctx context.Context, id string, required domain.Role) (domain.Diagram, error) {
+	diagram, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, diagram, required); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
+
+func (s *diagramService) RoleOf(ctx context.Context, d domain.Diagram) (domain.Role, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return "", auth.ErrUnauthenticated
+	}
+	return s.roleOf(ctx, d, identity.UserID)
+}
+
+// GetACL 은 다이어그램을 볼 수 있는 사용자라면 누구나 조회할 수 있다
+func (s *diagramService) GetACL(ctx context.Context, id string) (domain.Diagram, error) {
+	return s.load(ctx, id, domain.RoleViewer)
+}
+
+// Grant 는 소유자만 할 수 있다. 권한은 내용이 아니므로 리비전을 남기지 않는다
+func (s *diagramService) Grant(ctx context.Context, id string, req GrantRequest) (domain.Diagram, error) {
+	diagram, err := s.load(ctx, id, domain.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := resolveUser(ctx, s.users, req.UserID, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := diagram.GrantAccess(userID, req.Role); err != nil {
+		return nil, err
+	}
+
+	if err := s.save(ctx, diagram, fmt.Sprintf("granted %s to %s", req.Role, userID)); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
+
+// Revoke 는 소유자만 할 수 있다. user 는 사용자 ID 나 이메일이다
+func (s *diagramService) Revoke(ctx context.Context, id string, user string) (domain.Diagram, error) {
+	diagram, err := s.load(ctx, id, domain.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	// 탈퇴한 사용자의 권한도 지울 수 있도록 ID 는 사용자를 확인하지 않는다
+	userID := user
+	if strings.Contains(user, "@") {
+		userID, err = resolveUser(ctx, s.users, "", user)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := diagram.RevokeAccess(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.save(ctx, diagram, "revoked "+userID); err != nil {
+		return nil, err
+	}
+	return diagram, nil
+}
+
+// resolveUser 는 등록된 사용자의 ID 를 찾는다
+func resolveUser(ctx context.Context, users persistance.UserRepository, userID, email string) (string, error) {
+	switch {
+	case userID != "" && email != "":
+		return "", domain.ErrInvalidGrant
+	case userID != "":
+		user, err := users.FindByID(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		return user.ID(), nil
+	case email != "":
+		user, err := users.FindByEmail(ctx, email)
+		if err != nil {
+			return "", err
+		}
+		return user.ID(), nil
+	default:
+		return "", domain.ErrInvalidGrant
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+type UserService interface {
+	Register(ctx context.Context, req RegisterRequest) (*domain.User, error)
+	Login(ctx context.Context, email, password string) (*domain.User, error)
+}
+
+type userService struct {
+	repo persistance.UserRepository
+	// 없는 이메일로 로그인해도 비교에 같은 시간이 걸리게 하는 더미 해시
+	dummyHash []byte
+}
+
+func NewUserService(repo persistance.UserRepository) UserService {
+	dummy, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return &userService{repo: repo, dummyHash: dummy}
+}
+
+type RegisterRequest struct {
+	Email    string
+	Password string
+	Name     string
+}
+
+func (s *userService) Register(ctx context.Context, req RegisterRequest) (*domain.User, error) {
+	user, err := domain.NewUser(req.Email, req.Password, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user.SetPassword(string(hash))
+
+	id, err := s.repo.Save(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	user.SetID(id)
+	return user, nil
+}
+
+// Login 은 이메일이 없을 때와 비밀번호가 틀릴 때 같은 오류를 반환한다
+func (s *userService) Login(ctx context.Context, email, password string) (*domain.User, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if errors.Is(err, persistance.ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password()), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"diagram-server/internal/persistance"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_Register(t *testing.T) {
+	svc := NewUserService(persistance.NewMemoryUserRepository())
+	ctx := context.Background()
+
+	user, err := svc.Register(ctx, RegisterRequest{Email: "Alice@Example.com", Password: "password1", Name: "Alice"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if user.ID() == "" || user.Password() == "password1" {
+		t.Errorf("Register() = %+v, want a saved user with a hashed password", user)
+	}
+
+	// 대소문자만 다른 이메일도 같은 이메일이다
+	_, err = svc.Register(ctx, RegisterRequest{Email: "alice@example.com", Password: "password2", Name: "Other"})
+	if !errors.Is(err, persistance.ErrEmailTaken) {
+		t.Errorf("Register() error = %v, want %v", err, persistance.ErrEmailTaken)
+	}
+}
+
+func TestUserService_Login(t *testing.T) {
+	svc := NewUserService(persistance.NewMemoryUserRepository())
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, RegisterRequest{Email: "alice@example.com", Password: "password1", Name: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "맞는 비밀번호로 로그인한다", email: "alice@example.com", password: "password1"},
+		{name: "틀린 비밀번호", email: "alice@example.com", password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "없는 사용자는 틀린 비밀번호와 같은 오류", email: "bob@example.com", password: "password1", wantErr: ErrInvalidCredentials},
+		{name: "더미 해시와 맞는 비밀번호도 없는 사용자면 실패", email: "bob@example.com", password: "dummy-password", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := svc.Login(ctx, tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && user.Email() != tt.email {
+				t.Errorf("Login() user = %v, want %v", user.Email(), tt.email)
+			}
+		})
+	}
+}
+
+func TestUserService_DummyHash(t *testing.T) {
+	svc := NewUserService(persistance.NewMemoryUserRepository()).(*userService)
+
+	// 없는 사용자도 실제 비밀번호와 같은 비용으로 비교해야 응답 시간으로 가입 여부를 알 수 없다
+	cost, err := bcrypt.Cost(svc.dummyHash)
+	if err != nil || cost != bcrypt.DefaultCost {
+		t.Errorf("dummy hash cost = %d (%v), want %d", cost, err, bcrypt.DefaultCost)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"diagram-server/internal/auth"
+	"diagram-server/internal/domain"
+	"diagram-server/internal/persistance"
+	"errors"
+	"strings"
+)
+
+// maxChangeAttempts 는 구성원을 동시에 바꿀 때 다시 읽어 적용하는 최대 횟수다
+const maxChangeAttempts = 5
+
+type WorkspaceService interface {
+	Create(ctx context.Context, name string) (*domain.Workspace, error)
+	GetByID(ctx context.Context, id string) (*domain.Workspace, error)
+	// GetMine 은 요청한 사용자가 구성원인 워크스페이스를 반환한다
+	GetMine(ctx context.Context) ([]*domain.Workspace, error)
+	AddMember(ctx context.Context, id string, req MemberRequest) (*domain.Workspace, error)
+	RemoveMember(ctx context.Context, id string, user string) (*domain.Workspace, error)
+	GetDiagrams(ctx context.Context, id string) ([]domain.Diagram, error)
+}
+
+type workspaceService struct {
+	repo     persistance.WorkspaceRepository
+	diagrams persistance.DiagramRepository
+	users    persistance.UserRepository
+}
+
+func NewWorkspaceService(repo persistance.WorkspaceRepository, diagrams persistance.DiagramRepository, users persistance.UserRepository) WorkspaceService {
+	return &workspaceService{repo: repo, diagrams: diagrams, users: users}
+}
+
+// MemberRequest 는 UserID 나 Email 중 하나로 초대할 사용자를 지정한다
+type MemberRequest struct {
+	UserID string
+	Email  string
+	Role   domain.Role
+}
+
+func (s *workspaceService) Create(ctx context.Context, name string) (*domain.Workspace, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	workspace, err := domain.NewWorkspace(name, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.Save(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace.SetID(id)
+	return workspace, nil
+}
+
+func (s *workspaceService) GetByID(ctx context.Context, id string) (*domain.Workspace, error) {
+	return s.load(ctx, id, domain.RoleViewer)
+}
+
+func (s *workspaceService) GetMine(ctx context.Context) ([]*domain.Workspace, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	return s.repo.FindByMember(ctx, identity.UserID)
+}
+
+// AddMember 는 owner 만 할 수 있다. 이미 구성원이면 역할을 바꾼다
+func (s *workspaceService) AddMember(ctx context.Context, id string, req MemberRequest) (*domain.Workspace, error) {
+	if _, err := s.load(ctx, id, domain.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	userID, err := resolveUser(ctx, s.users, req.UserID, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	return s.change(ctx, id, func(workspace *domain.Workspace) error {
+		return workspace.AddMember(userID, req.Role)
+	})
+}
+
+// RemoveMember 는 owner 만 할 수 있다. user 는 사용자 ID 나 이메일이다
+func (s *workspaceService) RemoveMember(ctx context.Context, id string, user string) (*domain.Workspace, error) {
+	if _, err := s.load(ctx, id, domain.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	userID := user
+	if strings.Contains(user, "@") {
+		var err error
+		userID, err = resolveUser(ctx, s.users, "", user)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.change(ctx, id, func(workspace *domain.Workspace) error {
+		return workspace.RemoveMember(userID)
+	})
+}
+
+// change 는 owner 가 워크스페이스를 읽어 fn 으로 고친 뒤 저장한다.
+// 그 사이 다른 요청이 먼저 저장했으면 다시 읽고 fn 을 적용해 그 변경을 덮어쓰지 않는다.
+func (s *workspaceService) change(ctx context.Context, id string, fn func(workspace *domain.Workspace) error) (*domain.Workspace, error) {
+	for attempt := 1; ; attempt++ {
+		workspace, err := s.load(ctx, id, domain.RoleOwner)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(workspace); err != nil {
+			return nil, err
+		}
+
+		err = s.repo.Update(ctx, workspace)
+		if errors.Is(err, persistance.ErrWorkspaceConflict) && attempt < maxChangeAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return workspace, nil
+	}
+}
+
+// GetDiagrams 는 구성원이면 역할과 관계없이 워크스페이스의 모든 다이어그램을 볼 수 있다
+func (s *workspaceService) GetDiagrams(ctx context.Context, id string) ([]domain.Diagram, error) {
+	if _, err := s.load(ctx, id, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.diagrams.FindByWorkspace(ctx, id)
+}
+
+func (s *workspaceService) load(ctx context.Context, id string, required domain.Role) (*domain.Workspace, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	workspace, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeMember(workspace, identity.UserID, required); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// authorizeMember 는 구성원이 아니면 워크스페이스를 감추고, 역할이 모자라면 ErrForbidden 을 반환한다
+func authorizeMember(workspace *domain.Workspace, userID string, required domain.Role) error {
+	role := workspace.RoleOf(userID)
+	if role == "" {
+		return persistance.ErrWorkspaceNotFound
+	}
+	if !role.Allows(required) {
+		return ErrForbidden
+	}
+	return nil
+}
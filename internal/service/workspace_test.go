@@ -0,0 +1,65 @@
+package service
+
+import (
+	"diagram-server/internal/domain"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestWorkspaceService_ConcurrentMembers(t *testing.T) {
+	_, repos := newTestService()
+	svc := NewWorkspaceService(repos.workspaces, repos.diagrams, repos.users)
+	ctx := as("owner")
+
+	workspace, err := svc.Create(ctx, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"leaving", "user0", "user1", "user2"} {
+		user, _ := domain.NewUser(name+"@example.com", "password1", name)
+		if _, err := repos.users.Save(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := svc.AddMember(ctx, workspace.ID(), MemberRequest{Email: "leaving@example.com", Role: domain.RoleViewer}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 동시에 추가하고 빼도 서로의 변경을 덮어쓰지 않는다. 쓰는 쪽이 maxChangeAttempts 보다 적으면 모두 성공한다
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.AddMember(ctx, workspace.ID(), MemberRequest{Email: fmt.Sprintf("user%d@example.com", i), Role: domain.RoleEditor})
+			errs <- err
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := svc.RemoveMember(ctx, workspace.ID(), "leaving@example.com")
+		errs <- err
+	}()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("member change error = %v", err)
+		}
+	}
+
+	stored, err := repos.workspaces.FindByID(ctx, workspace.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Members()) != 4 {
+		t.Errorf("members = %+v, want the owner and 3 added users", stored.Members())
+	}
+	if leaving, _ := repos.users.FindByEmail(ctx, "leaving@example.com"); stored.RoleOf(leaving.ID()) != "" {
+		t.Error("removed member is still in the workspace")
+	}
+}